@@ -32,11 +32,17 @@ import (
 
 import (
 	"github.com/arana-db/arana/cmd/cmds"
+	"github.com/arana-db/arana/pkg/admin"
+	_ "github.com/arana-db/arana/pkg/admin/router"
 	"github.com/arana-db/arana/pkg/boot"
+	"github.com/arana-db/arana/pkg/config"
 	"github.com/arana-db/arana/pkg/constants"
 	"github.com/arana-db/arana/pkg/executor"
+	"github.com/arana-db/arana/pkg/gateway"
 	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/registry"
+	"github.com/arana-db/arana/pkg/runtime/namespace"
 	"github.com/arana-db/arana/pkg/server"
 	"github.com/arana-db/arana/pkg/util/log"
 )
@@ -52,7 +58,10 @@ _____________________________________________
 
 `
 
-const _keyBootstrap = "config"
+const (
+	_keyBootstrap = "config"
+	_keyAdminAddr = "admin-addr"
+)
 
 func init() {
 	cmd := &cobra.Command{
@@ -63,6 +72,8 @@ func init() {
 	}
 	cmd.PersistentFlags().
 		StringP(_keyBootstrap, "c", os.Getenv(constants.EnvBootstrapPath), "bootstrap configuration file path")
+	cmd.PersistentFlags().
+		String(_keyAdminAddr, "", "start the admin api with the proxy on the address, eg: ':8080'")
 
 	cmds.Handle(func(root *cobra.Command) {
 		root.AddCommand(cmd)
@@ -70,6 +81,10 @@ func init() {
 }
 
 func Run(bootstrapConfigPath string) {
+	runServer(bootstrapConfigPath, "")
+}
+
+func runServer(bootstrapConfigPath string, adminAddr string) {
 	// print slogan
 	fmt.Printf("\033[92m%s\033[0m\n", slogan) // 92m: light green
 
@@ -84,7 +99,7 @@ func Run(bootstrapConfigPath string) {
 
 	listenersConf := discovery.ListListeners(context.Background())
 	for _, listenerConf := range listenersConf {
-		listener, err := mysql.NewListener(listenerConf)
+		listener, err := newListener(listenerConf)
 		if err != nil {
 			log.Fatalf("create listener failed: %v", err)
 			return
@@ -93,6 +108,11 @@ func Run(bootstrapConfigPath string) {
 		propeller.AddListener(listener)
 	}
 
+	server.SetDefault(propeller)
+	if opts := discovery.GetOptions(); opts != nil && opts.Shutdown != nil {
+		propeller.SetShutdownTimeout(opts.Shutdown.Timeout)
+	}
+
 	// init service registry
 	registryConf := discovery.GetServiceRegistry(context.Background())
 	if registryConf != nil && registryConf.Enable {
@@ -106,8 +126,23 @@ func Run(bootstrapConfigPath string) {
 			log.Errorf("do service register failed: %v", err)
 			return
 		}
+
+		// deregister first, so that no more new clients will be routed to here
+		propeller.BeforeDrain(func(ctx context.Context) error {
+			return serviceRegistry.Unregister(ctx, "service")
+		})
 	}
 
+	// close the backend pools after all sessions are finished
+	propeller.AfterDrain(func(ctx context.Context) error {
+		for _, name := range namespace.List() {
+			if err := namespace.Unregister(name); err != nil {
+				log.Errorf("failed to close namespace %s: %v", name, err)
+			}
+		}
+		return nil
+	})
+
 	if err := discovery.InitTrace(context.Background()); err != nil {
 		log.Warnf("init trace provider failed: %v", err)
 	}
@@ -116,19 +151,34 @@ func Run(bootstrapConfigPath string) {
 		log.Warnf("init supervisor failed: %v", err)
 	}
 
+	if len(adminAddr) > 0 {
+		tenantOp, err := config.NewTenantOperator(config.GetStoreOperate())
+		if err != nil {
+			log.Fatalf("start admin api server failed: %v", err)
+			return
+		}
+		go func() {
+			if err := admin.New(tenantOp).Listen(adminAddr); err != nil {
+				log.Errorf("admin api server stopped: %v", err)
+			}
+		}()
+	}
+
 	propeller.Start()
 
-	ctx, cancel := context.WithCancel(context.Background())
 	c := make(chan os.Signal, 2)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-c
-		cancel()
+		// drain the server, it can also be triggered by the admin api
+		go func() {
+			_, _ = propeller.Shutdown(0)
+		}()
 		<-c
 		os.Exit(1) // second signal. Exit directly.
 	}()
 
-	<-ctx.Done()
+	<-propeller.Done()
 }
 
 func run(cmd *cobra.Command, args []string) {
@@ -150,5 +200,24 @@ func run(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	Run(bootstrapConfigPath)
+	adminAddr, _ := cmd.PersistentFlags().GetString(_keyAdminAddr)
+
+	runServer(bootstrapConfigPath, adminAddr)
+}
+
+// newListener creates the listener by protocol type, mysql will be used if the protocol type is absent.
+func newListener(conf *config.Listener) (proto.Listener, error) {
+	protocol := config.MySQL
+	if len(conf.ProtocolType) > 0 {
+		if err := protocol.UnmarshalText([]byte(conf.ProtocolType)); err != nil {
+			return nil, err
+		}
+	}
+
+	switch protocol {
+	case config.Http:
+		return gateway.NewListener(conf)
+	default:
+		return mysql.NewListener(conf)
+	}
 }
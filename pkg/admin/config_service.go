@@ -246,6 +246,8 @@ func (cs *myConfigService) ListTables(ctx context.Context, tenant, cluster strin
 			Name:           tbl,
 			Sequence:       next.Sequence,
 			AllowFullScan:  next.AllowFullScan,
+			Broadcast:      next.Broadcast,
+			GlobalIndexes:  next.GlobalIndexes,
 			DbRules:        next.DbRules,
 			TblRules:       next.TblRules,
 			Topology:       next.Topology,
@@ -618,6 +620,8 @@ func (cs *myConfigService) UpsertTable(ctx context.Context, tenant, cluster, tab
 		if db == cluster && tb == table {
 			tableCfg.Sequence = body.Sequence
 			tableCfg.AllowFullScan = body.AllowFullScan
+			tableCfg.Broadcast = body.Broadcast
+			tableCfg.GlobalIndexes = body.GlobalIndexes
 			tableCfg.DbRules = body.DbRules
 			tableCfg.TblRules = body.TblRules
 			tableCfg.Topology = body.Topology
@@ -632,6 +636,8 @@ func (cs *myConfigService) UpsertTable(ctx context.Context, tenant, cluster, tab
 			Name:           cluster + "." + table,
 			Sequence:       body.Sequence,
 			AllowFullScan:  body.AllowFullScan,
+			Broadcast:      body.Broadcast,
+			GlobalIndexes:  body.GlobalIndexes,
 			DbRules:        body.DbRules,
 			TblRules:       body.TblRules,
 			Topology:       body.Topology,
@@ -0,0 +1,94 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package router
+
+import (
+	"net/http"
+	"time"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/admin"
+	"github.com/arana-db/arana/pkg/admin/exception"
+	"github.com/arana-db/arana/pkg/server"
+)
+
+func init() {
+	admin.Register(func(router admin.Router) {
+		router.POST("/server/drain", DrainServer)
+	})
+}
+
+// SessionStateDTO is a session which is terminated while in a transaction during draining.
+type SessionStateDTO struct {
+	ConnectionID  uint32 `json:"connection_id"`
+	Tenant        string `json:"tenant"`
+	Schema        string `json:"schema"`
+	RemoteAddr    string `json:"remote_addr"`
+	InTransaction bool   `json:"in_transaction"`
+}
+
+// DrainServer shuts down the proxy server in current process gracefully, it is only available
+// when the admin api is started with the proxy, eg: 'arana start --admin-addr :8080'. It blocks until the
+// server is drained, the sessions which are terminated while in a transaction will be responded.
+func DrainServer(c *gin.Context) error {
+	srv := server.Default()
+	if srv == nil {
+		return exception.New(exception.CodeNotFound, "no proxy server is running in this process")
+	}
+
+	var timeout time.Duration
+	if s := c.Query("timeout"); len(s) > 0 {
+		var err error
+		if timeout, err = time.ParseDuration(s); err != nil || timeout <= 0 {
+			return exception.New(exception.CodeInvalidParams, "invalid timeout '%s'", s)
+		}
+	}
+
+	// keep the process alive until the result is responded
+	release := srv.Hold()
+	defer release()
+
+	inTx, err := srv.Shutdown(timeout)
+	if err != nil {
+		if errors.Is(err, server.ErrShuttingDown) {
+			return exception.New(exception.CodeInvalidParams, "server is shutting down already")
+		}
+		return exception.Wrap(exception.CodeUnknownError, err)
+	}
+
+	sessions := make([]*SessionStateDTO, 0, len(inTx))
+	for _, it := range inTx {
+		sessions = append(sessions, &SessionStateDTO{
+			ConnectionID:  it.ConnectionID,
+			Tenant:        it.Tenant,
+			Schema:        it.Schema,
+			RemoteAddr:    it.RemoteAddr,
+			InTransaction: it.InTransaction,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"drained": true, "terminated": sessions})
+	c.Writer.Flush()
+	return nil
+}
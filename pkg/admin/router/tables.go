@@ -19,15 +19,21 @@ package router
 
 import (
 	"net/http"
+	"strconv"
 )
 
 import (
 	"github.com/gin-gonic/gin"
+
+	"golang.org/x/exp/slices"
 )
 
 import (
 	"github.com/arana-db/arana/pkg/admin"
 	"github.com/arana-db/arana/pkg/admin/exception"
+	"github.com/arana-db/arana/pkg/runtime"
+	"github.com/arana-db/arana/pkg/schema/drift"
+	"github.com/arana-db/arana/pkg/security"
 )
 
 func init() {
@@ -37,6 +43,7 @@ func init() {
 		router.GET("/tenants/:tenant/clusters/:cluster/tables/:table", GetTable)
 		router.PUT("/tenants/:tenant/clusters/:cluster/tables/:table", UpsertTable)
 		router.DELETE("/tenants/:tenant/clusters/:cluster/tables/:table", RemoveTable)
+		router.GET("/tenants/:tenant/clusters/:cluster/tables/:table/drift", CheckTableDrift)
 	})
 }
 
@@ -126,3 +133,38 @@ func RemoveTable(c *gin.Context) error {
 	c.Status(http.StatusNoContent)
 	return nil
 }
+
+// CheckTableDrift compares the schema of all physical tables of a sharding table, it is only available
+// when the admin api is started with the proxy, eg: 'arana start --admin-addr :8080'.
+func CheckTableDrift(c *gin.Context) error {
+	tenant, cluster, table := c.Param("tenant"), c.Param("cluster"), c.Param("table")
+
+	var repair bool
+	if s := c.Query("repair"); len(s) > 0 {
+		var err error
+		if repair, err = strconv.ParseBool(s); err != nil {
+			return exception.New(exception.CodeInvalidParams, "invalid repair '%s'", s)
+		}
+	}
+
+	if !slices.Contains(security.DefaultTenantManager().GetClusters(tenant), cluster) {
+		return exception.New(exception.CodeNotFound, "no such cluster `%s` in this process", cluster)
+	}
+	rt, err := runtime.Load(cluster)
+	if err != nil {
+		return exception.New(exception.CodeNotFound, "no such cluster `%s` in this process", cluster)
+	}
+
+	vt, ok := rt.Namespace().Rule().VTable(table)
+	if !ok {
+		return exception.New(exception.CodeNotFound, "no such sharding table `%s`", table)
+	}
+
+	report, err := drift.Check(c, rt, table, vt.Topology().Enumerate(), repair)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, report)
+	return nil
+}
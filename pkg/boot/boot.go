@@ -31,9 +31,11 @@ import (
 
 import (
 	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/runtime"
 	"github.com/arana-db/arana/pkg/runtime/namespace"
+	"github.com/arana-db/arana/pkg/runtime/optimize/ddl"
 	_ "github.com/arana-db/arana/pkg/schema"
 	"github.com/arana-db/arana/pkg/security"
 	"github.com/arana-db/arana/pkg/util/log"
@@ -136,6 +138,22 @@ func (bt *Booter) bootTenant(ctx context.Context, tenant string) {
 		}
 	}
 
+	// recover the interrupted renaming of sharding tables after the namespaces are ready
+	for _, cluster := range security.DefaultTenantManager().GetClusters(tenant) {
+		rt, err := runtime.Load(cluster)
+		if err != nil {
+			continue
+		}
+		if err = ddl.RecoverRenames(ctx, tenant, cluster, rt, rt.Namespace().Rule()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// resume the unfinished ddl jobs after the namespaces are ready
+	if err := proto.LoadDDLJobManager().Resume(ctx, tenant); err != nil {
+		errs = append(errs, err)
+	}
+
 	cost := time.Since(begin).Milliseconds()
 	if err := multierr.Combine(errs...); err != nil {
 		log.Errorf("[%s] boot failed after %dms: %v", tenant, err, cost)
@@ -243,6 +261,19 @@ func buildNamespace(ctx context.Context, tenant string, provider Discovery, clus
 		}
 		ru.SetVTable(table, vt)
 	}
+
+	var bindings [][]string
+	if bindings, err = provider.ListBindingTables(ctx, tenant, clusterName); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ru.SetBindingTables(bindings...)
+
+	var singles map[string]string
+	if singles, err = provider.ListSingleTables(ctx, tenant, clusterName); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ru.SetSingleTables(singles)
+
 	initCmds = append(initCmds, namespace.UpdateRule(&ru))
 
 	return namespace.New(clusterName, initCmds...)
@@ -0,0 +1,159 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package boot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime"
+	rrule "github.com/arana-db/arana/pkg/runtime/rule"
+)
+
+const (
+	_defaultDirectoryKeyColumn   = "shard_key"
+	_defaultDirectoryShardColumn = "shard_index"
+	_directoryLookupTimeout      = 3 * time.Second
+)
+
+// toDirectorySharder creates the directory sharding computer, the mapping in config is looked up first, then the
+// mapping table. The unmapped keys will fall back to the algorithm of rule expression, eg: modShard(4).
+// The mapping table is looked up lazily, the shards of topology are the range of fallback and the mapped shards,
+// and all the shards of topology pattern if the mapping table is used since its shards are unknown.
+func toDirectorySharder(schema string, input *config.Rule) (rule.ShardComputer, error) {
+	dir := input.Directory
+	if dir == nil {
+		return nil, errors.Errorf("no directory found for the directory sharding of column '%s'", input.Column)
+	}
+
+	var (
+		lookups []rrule.DirectoryLookup
+		indexes []int
+	)
+	if len(dir.Mapping) > 0 {
+		lookups = append(lookups, rrule.MapDirectoryLookup(dir.Mapping))
+		for _, idx := range dir.Mapping {
+			indexes = append(indexes, idx)
+		}
+	}
+	if len(dir.Table) > 0 {
+		lookup, err := tableDirectoryLookup(schema, dir)
+		if err != nil {
+			return nil, err
+		}
+		lookups = append(lookups, lookup)
+	}
+
+	var fallback rule.ShardComputer
+	if mat := getRuleExprRegexp().FindStringSubmatch(input.Expr); len(mat) == 3 {
+		var (
+			mod, _ = strconv.Atoi(mat[2])
+			err    error
+		)
+		if fallback, err = rrule.ShardFactory(rrule.ShardType(mat[1]), mod); err != nil {
+			return nil, errors.Wrapf(err, "invalid fallback algorithm '%s' of directory sharding", input.Expr)
+		}
+		// the fallback algorithms compute the shards in [0,mod)
+		for i := 0; i < mod; i++ {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return rrule.NewDirectoryShard(func(key string) (int, bool, error) {
+		for _, lookup := range lookups {
+			if idx, ok, err := lookup(key); err != nil || ok {
+				return idx, ok, err
+			}
+		}
+		return 0, false, nil
+	}, fallback, dir.CacheSize, len(dir.Table) > 0, indexes...)
+}
+
+// tableDirectoryLookup looks the key up in the mapping table through the runtime of schema, the runtime is loaded
+// when the first key is looked up, since it is not ready while the rule is being built.
+func tableDirectoryLookup(schema string, dir *config.Directory) (rrule.DirectoryLookup, error) {
+	group, table, err := parseDatabaseAndTable(dir.Table)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid directory table")
+	}
+
+	keyColumn, shardColumn := dir.KeyColumn, dir.ShardColumn
+	if len(keyColumn) == 0 {
+		keyColumn = _defaultDirectoryKeyColumn
+	}
+	if len(shardColumn) == 0 {
+		shardColumn = _defaultDirectoryShardColumn
+	}
+
+	query := fmt.Sprintf("SELECT `%s` FROM `%s` WHERE `%s` = ? LIMIT 1", shardColumn, table, keyColumn)
+
+	return func(key string) (int, bool, error) {
+		rt, err := runtime.Load(schema)
+		if err != nil {
+			return 0, false, errors.WithStack(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), _directoryLookupTimeout)
+		defer cancel()
+
+		res, err := rt.Query(ctx, group, query, proto.NewValueString(key))
+		if err != nil {
+			return 0, false, errors.WithStack(err)
+		}
+		ds, err := res.Dataset()
+		if err != nil {
+			return 0, false, errors.WithStack(err)
+		}
+		defer func() {
+			_ = ds.Close()
+		}()
+
+		row, err := ds.Next()
+		if errors.Is(err, io.EOF) {
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, errors.WithStack(err)
+		}
+
+		values := make([]proto.Value, 1)
+		if err = row.Scan(values); err != nil {
+			return 0, false, errors.WithStack(err)
+		}
+		if values[0] == nil {
+			return 0, false, nil
+		}
+		idx, err := values[0].Int64()
+		if err != nil {
+			return 0, false, errors.WithStack(err)
+		}
+		return int(idx), true, nil
+	}, nil
+}
@@ -305,6 +305,52 @@ func (fp *discovery) ListTables(ctx context.Context, tenant, cluster string) ([]
 	return tables, nil
 }
 
+func (fp *discovery) ListBindingTables(ctx context.Context, tenant, cluster string) ([][]string, error) {
+	op, ok := fp.centers[tenant]
+	if !ok {
+		return nil, ErrorNoTenant
+	}
+
+	cfg, err := op.LoadAll(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ShardingRule == nil {
+		return nil, nil
+	}
+
+	return filterBindingTables(cluster, cfg.ShardingRule.BindingTables)
+}
+
+func (fp *discovery) ListSingleTables(ctx context.Context, tenant, cluster string) (map[string]string, error) {
+	op, ok := fp.centers[tenant]
+	if !ok {
+		return nil, ErrorNoTenant
+	}
+
+	cfg, err := op.LoadAll(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ShardingRule == nil {
+		return nil, nil
+	}
+
+	singles, err := filterSingleTables(cluster, cfg.ShardingRule.SingleTables)
+	if err != nil {
+		return nil, err
+	}
+	for table, group := range singles {
+		if _, ok := fp.loadGroup(tenant, cluster, group); !ok {
+			return nil, errors.Errorf("no such group '%s' for single table '%s.%s'", group, cluster, table)
+		}
+	}
+
+	return singles, nil
+}
+
 func (fp *discovery) GetNode(ctx context.Context, tenant, cluster, group, node string) (*config.Node, error) {
 	op, ok := fp.centers[tenant]
 	if !ok {
@@ -468,7 +514,7 @@ func parseTopology(input string) (format string, begin, end int, err error) {
 	return
 }
 
-func toSharder(input *config.Rule) (rule.ShardComputer, error) {
+func toSharder(schema string, input *config.Rule) (rule.ShardComputer, error) {
 	var (
 		computer rule.ShardComputer
 		mod      int
@@ -488,8 +534,22 @@ func toSharder(input *config.Rule) (rule.ShardComputer, error) {
 		computer = rrule.NewHashBKDRShard(mod)
 	case rrule.HashCrc32Shard:
 		computer = rrule.NewHashCrc32Shard(mod)
+	case rrule.DayShard:
+		computer = rrule.NewDayShard(mod)
+	case rrule.WeekShard:
+		computer = rrule.NewWeekShard(mod)
+	case rrule.MonthShard:
+		computer = rrule.NewMonthShard(mod)
+	case rrule.YearShard:
+		computer = rrule.NewYearShard(mod)
+	case rrule.ConsistentHashShard:
+		computer = rrule.NewConsistentHashShard(mod)
+	case rrule.RangeShard:
+		computer, err = rrule.NewRangeShard(input.Expr)
+	case rrule.DirectoryShard:
+		computer, err = toDirectorySharder(schema, input)
 	case rrule.ScriptExpr:
-		computer, err = rrule.NewJavascriptShardComputer(input.Expr)
+		computer, err = rrule.NewJavascriptShardComputer(input.Expr, rrule.WithScriptColumns(splitColumns(input.Column)...))
 	default:
 		panic(fmt.Errorf("error config, unsupport shard type: %s", input.Type))
 	}
@@ -19,7 +19,11 @@ package boot
 
 import (
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 import (
@@ -29,6 +33,8 @@ import (
 import (
 	"github.com/arana-db/arana/pkg/config"
 	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/misc"
+	rrule "github.com/arana-db/arana/pkg/runtime/rule"
 )
 
 func makeVTable(tableName string, table *config.Table) (*rule.VTable, error) {
@@ -54,16 +60,26 @@ func makeVTable(tableName string, table *config.Table) (*rule.VTable, error) {
 			}
 		}
 	}
+	// the broadcast table is replicated with the same name in every database of topology
+	if table.Broadcast {
+		return makeBroadcastVTable(tableName, table, dbFormat, dbBegin, dbEnd)
+	}
+
 	topology.SetRender(getRender(dbFormat), getRender(tbFormat))
 
+	// the schema is used to look up the directory of sharding
+	schema, _, _ := parseDatabaseAndTable(table.Name)
+
 	var (
 		keys                 map[string]struct{}
 		dbSharder, tbSharder map[string]rule.ShardComputer
 		dbSteps, tbSteps     map[string]int
+		dbColumns, tbColumns = make(map[string][]string), make(map[string][]string)
+		partial              bool // whether the database and table are sharded by different keys
 	)
 	for _, it := range table.DbRules {
 		var shd rule.ShardComputer
-		if shd, err = toSharder(it); err != nil {
+		if shd, err = toSharder(schema, it); err != nil {
 			return nil, err
 		}
 		if dbSharder == nil {
@@ -75,14 +91,20 @@ func makeVTable(tableName string, table *config.Table) (*rule.VTable, error) {
 		if dbSteps == nil {
 			dbSteps = make(map[string]int)
 		}
-		dbSharder[it.Column] = shd
-		keys[it.Column] = struct{}{}
-		dbSteps[it.Column] = it.Step
+		columns := splitColumns(it.Column)
+		for _, column := range columns {
+			dbSharder[column] = shd
+			keys[column] = struct{}{}
+			dbSteps[column] = it.Step
+			if len(columns) > 1 {
+				dbColumns[column] = columns
+			}
+		}
 	}
 
 	for _, it := range table.TblRules {
 		var shd rule.ShardComputer
-		if shd, err = toSharder(it); err != nil {
+		if shd, err = toSharder(schema, it); err != nil {
 			return nil, err
 		}
 		if tbSharder == nil {
@@ -94,9 +116,15 @@ func makeVTable(tableName string, table *config.Table) (*rule.VTable, error) {
 		if tbSteps == nil {
 			tbSteps = make(map[string]int)
 		}
-		tbSharder[it.Column] = shd
-		keys[it.Column] = struct{}{}
-		tbSteps[it.Column] = it.Step
+		columns := splitColumns(it.Column)
+		for _, column := range columns {
+			tbSharder[column] = shd
+			keys[column] = struct{}{}
+			tbSteps[column] = it.Step
+			if len(columns) > 1 {
+				tbColumns[column] = columns
+			}
+		}
 	}
 
 	for k := range keys {
@@ -107,7 +135,8 @@ func makeVTable(tableName string, table *config.Table) (*rule.VTable, error) {
 		if shd, ok = dbSharder[k]; ok {
 			dbMetadata = &rule.ShardMetadata{
 				Computer: shd,
-				Stepper:  rule.DefaultNumberStepper,
+				Stepper:  toStepper(shd),
+				Columns:  dbColumns[k],
 			}
 			if s, ok := dbSteps[k]; ok && s > 0 {
 				dbMetadata.Steps = s
@@ -118,7 +147,8 @@ func makeVTable(tableName string, table *config.Table) (*rule.VTable, error) {
 		if shd, ok = tbSharder[k]; ok {
 			tbMetadata = &rule.ShardMetadata{
 				Computer: shd,
-				Stepper:  rule.DefaultNumberStepper,
+				Stepper:  toStepper(shd),
+				Columns:  tbColumns[k],
 			}
 			if s, ok := tbSteps[k]; ok && s > 0 {
 				tbMetadata.Steps = s
@@ -126,34 +156,98 @@ func makeVTable(tableName string, table *config.Table) (*rule.VTable, error) {
 				tbMetadata.Steps = 1 + tbEnd - tbBegin
 			}
 		}
-		vt.SetShardMetadata(k, dbMetadata, tbMetadata)
 
-		tpRes := make(map[int][]int)
-		step := tbMetadata.Steps
-		if dbMetadata.Steps > step {
+		// the database and table are not computed from the same single column, topology cannot be enumerated by values
+		if dbMetadata == nil || tbMetadata == nil || dbMetadata.IsComposite() || tbMetadata.IsComposite() {
+			vt.SetShardMetadata(k, dbMetadata, tbMetadata)
+			partial = true
+			continue
+		}
+
+		// the values of topology will be enumerated with the finer stepper
+		seedMetadata := tbMetadata
+		if dbMetadata.Stepper.U < seedMetadata.Stepper.U {
+			seedMetadata = dbMetadata
+		}
+		step := seedMetadata.Steps
+		if dbMetadata.Stepper != tbMetadata.Stepper {
+			// different steppers, eg: shard db by year and shard table by month
+			step = dbMetadata.Steps * tbMetadata.Steps
+			seedMetadata.Steps = step
+		} else if dbMetadata.Steps > step {
 			step = dbMetadata.Steps
 		}
-		rng, _ := tbMetadata.Stepper.Ascend(0, step)
+
+		vt.SetShardMetadata(k, dbMetadata, tbMetadata)
+
+		var offset interface{} = 0
+		if seedMetadata.Stepper.U.IsTime() {
+			offset = timeSeed(dbMetadata, dbBegin, tbMetadata, tbBegin)
+		}
+
+		rng, err := seedMetadata.Stepper.Ascend(offset, step)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		values := toSeeds(dbMetadata.Computer, tbMetadata.Computer)
+		// the stepping values are best-effort if seeds exist, eg: the value may be out of the range-map
+		bestEffort := len(values) > 0
 		for rng.HasNext() {
-			var (
-				seed  = rng.Next()
-				dbIdx = -1
-				tbIdx = -1
-			)
-			if dbMetadata != nil {
-				if dbIdx, err = dbMetadata.Computer.Compute(seed); err != nil {
-					return nil, errors.WithStack(err)
+			values = append(values, rng.Next())
+		}
+
+		tpRes := make(map[int]map[int]struct{})
+		if isCrossTopology(dbMetadata.Computer, tbMetadata.Computer) {
+			// the pairs of database and table cannot be enumerated, every database contains all the tables
+			var dbIndexes, tbIndexes []int
+			if dbIndexes, err = shardIndexes(dbMetadata.Computer, values, bestEffort, patternIndexes(dbBegin, dbEnd)); err != nil {
+				return nil, err
+			}
+			if tbIndexes, err = shardIndexes(tbMetadata.Computer, values, bestEffort, patternIndexes(tbBegin, tbEnd)); err != nil {
+				return nil, err
+			}
+			for _, dbIdx := range dbIndexes {
+				tpRes[dbIdx] = make(map[int]struct{}, len(tbIndexes))
+				for _, tbIdx := range tbIndexes {
+					tpRes[dbIdx][tbIdx] = struct{}{}
+				}
+			}
+		} else {
+			place := func(value interface{}) error {
+				dbIdx, err := dbMetadata.Computer.Compute(value)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				tbIdx, err := tbMetadata.Computer.Compute(value)
+				if err != nil {
+					return errors.WithStack(err)
 				}
+				if _, ok := tpRes[dbIdx]; !ok {
+					tpRes[dbIdx] = make(map[int]struct{})
+				}
+				tpRes[dbIdx][tbIdx] = struct{}{}
+				return nil
 			}
-			if tbMetadata != nil {
-				if tbIdx, err = tbMetadata.Computer.Compute(seed); err != nil {
-					return nil, errors.WithStack(err)
+			for _, value := range values {
+				if err = place(value); err != nil && !bestEffort {
+					return nil, err
 				}
 			}
-			tpRes[dbIdx] = append(tpRes[dbIdx], tbIdx)
 		}
 
 		for dbIndex, tbIndexes := range tpRes {
+			tables := make([]int, 0, len(tbIndexes))
+			for tbIndex := range tbIndexes {
+				tables = append(tables, tbIndex)
+			}
+			topology.SetTopology(dbIndex, tables...)
+		}
+	}
+
+	// every database contains all the tables, eg: shard db by tenant_id and shard table by order_id
+	if partial {
+		tbIndexes := patternIndexes(tbBegin, tbEnd)
+		for _, dbIndex := range patternIndexes(dbBegin, dbEnd) {
 			topology.SetTopology(dbIndex, tbIndexes...)
 		}
 	}
@@ -161,11 +255,13 @@ func makeVTable(tableName string, table *config.Table) (*rule.VTable, error) {
 	if table.AllowFullScan {
 		vt.SetAllowFullScan(true)
 	}
-	if table.Sequence != nil {
-		vt.SetAutoIncrement(&rule.AutoIncrement{
-			Type:   table.Sequence.Type,
-			Option: table.Sequence.Option,
-		})
+	// the rows will be moved between shards in a transaction if the sharding key is updated
+	if allow, _ := strconv.ParseBool(table.Attributes["allow_update_shard_key"]); allow {
+		vt.SetAllowUpdateShardKey(true)
+	}
+	setAutoIncrement(&vt, table)
+	if err = setGlobalIndexes(&vt, tableName, table); err != nil {
+		return nil, err
 	}
 
 	// TODO: process attributes
@@ -177,6 +273,213 @@ func makeVTable(tableName string, table *config.Table) (*rule.VTable, error) {
 	return &vt, nil
 }
 
+func makeBroadcastVTable(tableName string, table *config.Table, dbFormat string, dbBegin, dbEnd int) (*rule.VTable, error) {
+	if len(table.DbRules) > 0 || len(table.TblRules) > 0 {
+		return nil, errors.Errorf("broadcast table '%s' cannot have sharding rules", tableName)
+	}
+	if len(table.GlobalIndexes) > 0 {
+		return nil, errors.Errorf("broadcast table '%s' cannot have global indexes", tableName)
+	}
+	if len(dbFormat) < 1 {
+		return nil, errors.Errorf("no database pattern found for broadcast table '%s'", tableName)
+	}
+
+	var (
+		vt       rule.VTable
+		topology rule.Topology
+	)
+	topology.SetRender(getRender(dbFormat), func(_ int) string {
+		return tableName
+	})
+	for _, dbIndex := range patternIndexes(dbBegin, dbEnd) {
+		topology.SetTopology(dbIndex, 0)
+	}
+
+	// writes will be sent to all the databases, so it is not a real full-scan
+	vt.SetBroadcast(true)
+	vt.SetAllowFullScan(true)
+	setAutoIncrement(&vt, table)
+	vt.SetTopology(&topology)
+	vt.SetName(tableName)
+
+	return &vt, nil
+}
+
+func setAutoIncrement(vt *rule.VTable, table *config.Table) {
+	if table.Sequence != nil {
+		vt.SetAutoIncrement(&rule.AutoIncrement{
+			Type:   table.Sequence.Type,
+			Option: table.Sequence.Option,
+		})
+	}
+}
+
+// setGlobalIndexes sets the global secondary indexes, the index table should be in the same schema.
+func setGlobalIndexes(vt *rule.VTable, tableName string, table *config.Table) error {
+	if len(table.GlobalIndexes) < 1 {
+		return nil
+	}
+
+	schema, _, _ := parseDatabaseAndTable(table.Name)
+
+	indexes := make([]*rule.GlobalIndex, 0, len(table.GlobalIndexes))
+	for _, it := range table.GlobalIndexes {
+		if it == nil {
+			continue
+		}
+		if len(it.Name) < 1 || len(it.Column) < 1 {
+			return errors.Errorf("no name or column of global index for table '%s'", tableName)
+		}
+		if vt.HasColumn(it.Column) {
+			return errors.Errorf("cannot create global index '%s' on sharding key '%s'", it.Name, it.Column)
+		}
+		db, tbl, err := parseDatabaseAndTable(it.Table)
+		if err != nil {
+			return errors.Wrapf(err, "invalid table of global index '%s'", it.Name)
+		}
+		if db != schema || tbl == tableName {
+			return errors.Errorf("the table of global index '%s' should be another table in schema '%s'", it.Name, schema)
+		}
+		indexes = append(indexes, &rule.GlobalIndex{
+			Name:   it.Name,
+			Column: it.Column,
+			Table:  tbl,
+		})
+	}
+	vt.SetGlobalIndexes(indexes...)
+
+	return nil
+}
+
+// splitColumns splits the column of rule, multiple columns separated by comma means a composite sharding key.
+func splitColumns(column string) []string {
+	columns := strings.Split(column, ",")
+	for i := range columns {
+		columns[i] = strings.TrimSpace(columns[i])
+	}
+	return columns
+}
+
+// patternIndexes returns the indexes of the topology pattern, a pattern without range has only one index.
+func patternIndexes(begin, end int) []int {
+	if begin < 0 || end < begin {
+		return []int{0}
+	}
+	ret := make([]int, 0, end-begin+1)
+	for i := begin; i <= end; i++ {
+		ret = append(ret, i)
+	}
+	return ret
+}
+
+// toSeeds returns the sorted union of seeds provided by the shard computers.
+func toSeeds(computers ...rule.ShardComputer) []interface{} {
+	var seeds []interface{}
+	for _, it := range computers {
+		if seeder, ok := it.(rrule.ShardSeeder); ok {
+			seeds = append(seeds, seeder.Seeds()...)
+		}
+	}
+	sort.Slice(seeds, func(i, j int) bool {
+		return misc.Compare(seeds[i], seeds[j]) < 0
+	})
+
+	ret := seeds[:0]
+	for i := range seeds {
+		if i > 0 && misc.Compare(seeds[i], seeds[i-1]) == 0 {
+			continue
+		}
+		ret = append(ret, seeds[i])
+	}
+	return ret
+}
+
+// isCrossTopology returns true if the topology should be the cross product of the indexes of database and table,
+// eg: shard db by range-map and shard table by modShard, the values in a range can be computed into any table.
+func isCrossTopology(dbComputer, tbComputer rule.ShardComputer) bool {
+	_, dbIndexer := dbComputer.(rrule.ShardIndexer)
+	_, tbIndexer := tbComputer.(rrule.ShardIndexer)
+	if dbIndexer || tbIndexer {
+		return true
+	}
+	// the seeds cover the pairs only if both of the computers are piecewise, eg: range-map
+	_, dbSeeder := dbComputer.(rrule.ShardSeeder)
+	_, tbSeeder := tbComputer.(rrule.ShardSeeder)
+	return dbSeeder != tbSeeder
+}
+
+// shardIndexes returns the sorted shard indexes of the computer, they are computed from the values
+// if the computer is not a ShardIndexer. The failed values will be skipped if bestEffort is true.
+// The indexes of pattern will be included if the ShardIndexer doesn't know all its indexes, eg: the directory
+// sharding backed by a mapping table.
+func shardIndexes(shd rule.ShardComputer, values []interface{}, bestEffort bool, patterns []int) ([]int, error) {
+	visited := make(map[int]struct{})
+
+	if indexer, ok := shd.(rrule.ShardIndexer); ok {
+		if partial, ok := indexer.(rrule.PartialShardIndexer); !ok || !partial.Partial() {
+			return indexer.Indexes(), nil
+		}
+		for _, idx := range indexer.Indexes() {
+			visited[idx] = struct{}{}
+		}
+		for _, idx := range patterns {
+			visited[idx] = struct{}{}
+		}
+		return sortedIndexes(visited), nil
+	}
+
+	for _, value := range values {
+		idx, err := shd.Compute(value)
+		if err != nil {
+			if bestEffort {
+				continue
+			}
+			return nil, errors.WithStack(err)
+		}
+		visited[idx] = struct{}{}
+	}
+
+	return sortedIndexes(visited), nil
+}
+
+// sortedIndexes returns the indexes in ascending order.
+func sortedIndexes(visited map[int]struct{}) []int {
+	ret := make([]int, 0, len(visited))
+	for idx := range visited {
+		ret = append(ret, idx)
+	}
+	sort.Ints(ret)
+	return ret
+}
+
+// toStepper returns the stepper of the shard computer, time sharding will use the time stepper.
+func toStepper(shd rule.ShardComputer) rule.Stepper {
+	if ts, ok := shd.(rrule.TimeShardComputer); ok {
+		return rule.Stepper{N: 1, U: ts.Unit()}
+	}
+	return rule.DefaultNumberStepper
+}
+
+// timeSeed returns the first time value which is used to enumerate the topology.
+// The year 2020 is chosen because it is a leap year which has 53 ISO weeks, so all the
+// indexes of days and weeks can be covered.
+func timeSeed(dbMetadata *rule.ShardMetadata, dbBegin int, tbMetadata *rule.ShardMetadata, tbBegin int) time.Time {
+	year := 2020
+	for _, it := range []struct {
+		md    *rule.ShardMetadata
+		begin int
+	}{{dbMetadata, dbBegin}, {tbMetadata, tbBegin}} {
+		if it.md == nil || it.begin <= 0 {
+			continue
+		}
+		// yearly sharding without modulo uses the year as the index, so begin from the first year
+		if ts, ok := it.md.Computer.(rrule.TimeShardComputer); ok && ts.Unit() == rule.Uyear && ts.Modulo() <= 0 {
+			year = it.begin
+		}
+	}
+	return time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+}
+
 var (
 	_fullTableNameRegexp     *regexp.Regexp
 	_fullTableNameRegexpOnce sync.Once
@@ -197,3 +500,50 @@ func parseDatabaseAndTable(name string) (db, tbl string, err error) {
 	}
 	return
 }
+
+// filterBindingTables returns the binding table groups of the cluster, only the table names will be kept.
+func filterBindingTables(cluster string, bindings []*config.BindingTable) ([][]string, error) {
+	var groups [][]string
+	for _, binding := range bindings {
+		if binding == nil {
+			continue
+		}
+		var group []string
+		for _, it := range binding.Tables {
+			db, tbl, err := parseDatabaseAndTable(it)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if db != cluster {
+				continue
+			}
+			group = append(group, tbl)
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// filterSingleTables returns the locations of single tables of the cluster, table name -> group.
+func filterSingleTables(cluster string, singles []*config.SingleTable) (map[string]string, error) {
+	ret := make(map[string]string)
+	for _, single := range singles {
+		if single == nil {
+			continue
+		}
+		db, tbl, err := parseDatabaseAndTable(single.Name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if db != cluster {
+			continue
+		}
+		if len(single.Group) < 1 {
+			return nil, errors.Errorf("no group for single table '%s'", single.Name)
+		}
+		ret[tbl] = single.Group
+	}
+	return ret, nil
+}
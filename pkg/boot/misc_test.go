@@ -25,6 +25,15 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/cmp"
+	"github.com/arana-db/arana/pkg/runtime/logical"
+	rrule "github.com/arana-db/arana/pkg/runtime/rule"
+)
+
 func TestParseDatabaseAndTable(t *testing.T) {
 	type tt struct {
 		name  string
@@ -43,3 +52,369 @@ func TestParseDatabaseAndTable(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeVTable_TimeShard(t *testing.T) {
+	vt, err := makeVTable("orders", &config.Table{
+		Name: "orders",
+		DbRules: []*config.Rule{
+			{Column: "created_at", Type: string(rrule.YearShard), Expr: "yearShard()"},
+		},
+		TblRules: []*config.Rule{
+			{Column: "created_at", Type: string(rrule.MonthShard), Expr: "monthShard(12)"},
+		},
+		Topology: &config.Topology{
+			DbPattern:  "orders_${2021..2022}",
+			TblPattern: "orders_${00..11}",
+		},
+	})
+	assert.NoError(t, err)
+
+	dbLen, tblLen := vt.Topology().Len()
+	assert.Equal(t, 2, dbLen)
+	assert.Equal(t, 24, tblLen)
+
+	db, tb, ok := vt.Topology().Render(2022, 2)
+	assert.True(t, ok)
+	assert.Equal(t, "orders_2022", db)
+	assert.Equal(t, "orders_02", tb)
+
+	// created_at BETWEEN '2022-01-01' AND '2022-03-31'
+	l := rrule.NewKeyed("created_at", cmp.Cgte, proto.NewValueString("2022-01-01")).ToLogical().
+		And(rrule.NewKeyed("created_at", cmp.Clte, proto.NewValueString("2022-03-31")).ToLogical())
+	ev, err := rrule.Eval(l, vt)
+	assert.NoError(t, err)
+	shards, err := ev.Eval(vt)
+	assert.NoError(t, err)
+
+	var actual []string
+	shards.Each(func(db, tb uint32) bool {
+		d, t, _ := vt.Topology().Render(int(db), int(tb))
+		actual = append(actual, d+"."+t)
+		return true
+	})
+	assert.Equal(t, []string{"orders_2022.orders_00", "orders_2022.orders_01", "orders_2022.orders_02"}, actual)
+}
+
+func TestMakeVTable_CompositeShardKeys(t *testing.T) {
+	vt, err := makeVTable("orders", &config.Table{
+		Name: "orders",
+		DbRules: []*config.Rule{
+			{Column: "tenant_id", Type: string(rrule.ModShard), Expr: "modShard(2)"},
+		},
+		TblRules: []*config.Rule{
+			{Column: "tenant_id, order_id", Type: string(rrule.ScriptExpr), Expr: "($value[0] + $value[1]) % 4"},
+		},
+		Topology: &config.Topology{
+			DbPattern:  "orders_${0000..0001}",
+			TblPattern: "orders_${0000..0003}",
+		},
+	})
+	assert.NoError(t, err)
+
+	dbLen, tblLen := vt.Topology().Len()
+	assert.Equal(t, 2, dbLen)
+	assert.Equal(t, 8, tblLen)
+
+	eval := func(l logical.Logical) []string {
+		ev, err := rrule.Eval(l, vt)
+		assert.NoError(t, err)
+		shards, err := ev.Eval(vt)
+		assert.NoError(t, err)
+		if shards == nil {
+			return nil
+		}
+		var actual []string
+		shards.Each(func(db, tb uint32) bool {
+			d, t, _ := vt.Topology().Render(int(db), int(tb))
+			actual = append(actual, d+"."+t)
+			return true
+		})
+		return actual
+	}
+
+	var (
+		tenant = rrule.NewKeyed("tenant_id", cmp.Ceq, proto.NewValueInt64(1)).ToLogical()
+		order  = rrule.NewKeyed("order_id", cmp.Ceq, proto.NewValueInt64(6)).ToLogical()
+	)
+
+	// only the database can be computed
+	assert.Equal(t, []string{
+		"orders_0001.orders_0000",
+		"orders_0001.orders_0001",
+		"orders_0001.orders_0002",
+		"orders_0001.orders_0003",
+	}, eval(tenant))
+	// neither database nor table can be computed
+	assert.Nil(t, eval(order))
+	// all the key columns are bound
+	assert.Equal(t, []string{"orders_0001.orders_0003"}, eval(tenant.And(order)))
+	assert.Equal(t, []string{"orders_0001.orders_0003"}, eval(order.And(tenant)))
+}
+
+func TestMakeVTable_RangeShard(t *testing.T) {
+	vt, err := makeVTable("users", &config.Table{
+		Name: "users",
+		DbRules: []*config.Rule{
+			{Column: "id", Type: string(rrule.RangeShard), Expr: "[0,1000000)->0,[1000000,5000000)->0,[5000000,)->1"},
+		},
+		TblRules: []*config.Rule{
+			{Column: "id", Type: string(rrule.RangeShard), Expr: "[0,1000000)->0,[1000000,5000000)->1,[5000000,)->2"},
+		},
+		Topology: &config.Topology{
+			DbPattern:  "users_${0000..0001}",
+			TblPattern: "users_${0000..0002}",
+		},
+	})
+	assert.NoError(t, err)
+
+	eval := func(l logical.Logical) []string {
+		ev, err := rrule.Eval(l, vt)
+		assert.NoError(t, err)
+		shards, err := ev.Eval(vt)
+		assert.NoError(t, err)
+		var actual []string
+		shards.Each(func(db, tb uint32) bool {
+			d, t, _ := vt.Topology().Render(int(db), int(tb))
+			actual = append(actual, d+"."+t)
+			return true
+		})
+		return actual
+	}
+
+	// id BETWEEN 500000 AND 2000000
+	between := rrule.NewKeyed("id", cmp.Cgte, proto.NewValueInt64(500000)).ToLogical().
+		And(rrule.NewKeyed("id", cmp.Clte, proto.NewValueInt64(2000000)).ToLogical())
+	assert.Equal(t, []string{"users_0000.users_0000", "users_0000.users_0001"}, eval(between))
+
+	// id >= 6000000
+	assert.Equal(t, []string{"users_0001.users_0002"}, eval(rrule.NewKeyed("id", cmp.Cgte, proto.NewValueInt64(6000000)).ToLogical()))
+
+	// id = 1200000
+	assert.Equal(t, []string{"users_0000.users_0001"}, eval(rrule.NewKeyed("id", cmp.Ceq, proto.NewValueInt64(1200000)).ToLogical()))
+}
+
+func TestMakeVTable_ConsistentHashShard(t *testing.T) {
+	vt, err := makeVTable("users", &config.Table{
+		Name: "users",
+		DbRules: []*config.Rule{
+			{Column: "id", Type: string(rrule.ModShard), Expr: "modShard(1)"},
+		},
+		TblRules: []*config.Rule{
+			{Column: "id", Type: string(rrule.ConsistentHashShard), Expr: "consistentHashShard(16)"},
+		},
+		Topology: &config.Topology{
+			DbPattern:  "users_${0000..0000}",
+			TblPattern: "users_${0000..0015}",
+		},
+	})
+	assert.NoError(t, err)
+
+	dbLen, tblLen := vt.Topology().Len()
+	assert.Equal(t, 1, dbLen)
+	assert.Equal(t, 16, tblLen)
+}
+
+func TestMakeVTable_MixedRangeShard(t *testing.T) {
+	vt, err := makeVTable("users", &config.Table{
+		Name: "users",
+		DbRules: []*config.Rule{
+			{Column: "id", Type: string(rrule.RangeShard), Expr: "[0,1e6)->0,[1e6,)->1"},
+		},
+		TblRules: []*config.Rule{
+			{Column: "id", Type: string(rrule.ModShard), Expr: "modShard(8)"},
+		},
+		Topology: &config.Topology{
+			DbPattern:  "db_${0..1}",
+			TblPattern: "t_${0000..0007}",
+		},
+	})
+	assert.NoError(t, err)
+
+	dbLen, tblLen := vt.Topology().Len()
+	assert.Equal(t, 2, dbLen)
+	assert.Equal(t, 16, tblLen)
+
+	// id = 1000003
+	ev, err := rrule.Eval(rrule.NewKeyed("id", cmp.Ceq, proto.NewValueInt64(1000003)).ToLogical(), vt)
+	assert.NoError(t, err)
+	shards, err := ev.Eval(vt)
+	assert.NoError(t, err)
+	db, tb, _ := shards.Min()
+	d, tbl, _ := vt.Topology().Render(int(db), int(tb))
+	assert.Equal(t, "db_1.t_0003", d+"."+tbl)
+}
+
+func TestMakeVTable_DirectoryShard(t *testing.T) {
+	vt, err := makeVTable("orders", &config.Table{
+		Name: "orders",
+		DbRules: []*config.Rule{
+			{
+				Column: "tenant_id",
+				Type:   string(rrule.DirectoryShard),
+				Expr:   "modShard(4)",
+				Directory: &config.Directory{
+					Mapping: map[string]int{"1001": 4},
+				},
+			},
+		},
+		TblRules: []*config.Rule{
+			{Column: "tenant_id", Type: string(rrule.ModShard), Expr: "modShard(2)"},
+		},
+		Topology: &config.Topology{
+			DbPattern:  "orders_${0000..0004}",
+			TblPattern: "orders_${0000..0001}",
+		},
+	})
+	assert.NoError(t, err)
+
+	dbs := vt.Topology().EnumerateDatabases()
+	assert.Equal(t, []string{"orders_0000", "orders_0001", "orders_0002", "orders_0003", "orders_0004"}, dbs)
+
+	for _, it := range []struct {
+		tenant int64
+		expect string
+	}{
+		{1001, "orders_0004.orders_0001"}, // pinned to the dedicated group
+		{7, "orders_0003.orders_0001"},    // fallback: modShard(4)
+	} {
+		ev, err := rrule.Eval(rrule.NewKeyed("tenant_id", cmp.Ceq, proto.NewValueInt64(it.tenant)).ToLogical(), vt)
+		assert.NoError(t, err)
+		shards, err := ev.Eval(vt)
+		assert.NoError(t, err)
+		db, tb, _ := shards.Min()
+		d, tbl, _ := vt.Topology().Render(int(db), int(tb))
+		assert.Equal(t, 1, shards.Len())
+		assert.Equal(t, it.expect, d+"."+tbl)
+	}
+
+	_, err = makeVTable("orders", &config.Table{
+		Name: "orders",
+		DbRules: []*config.Rule{
+			{Column: "tenant_id", Type: string(rrule.DirectoryShard), Expr: "fooShard(4)", Directory: &config.Directory{}},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestMakeVTable_TableDirectoryShard(t *testing.T) {
+	// the mapping table won't be looked up while building the topology
+	for _, it := range []struct {
+		expr   string
+		expect []string
+	}{
+		// the shards of mapping table are unknown, so the pattern is used with the range of fallback
+		{"modShard(2)", []string{"orders_0000", "orders_0001", "orders_0002"}},
+		{"", []string{"orders_0000", "orders_0001", "orders_0002"}}, // no fallback, use the pattern
+	} {
+		vt, err := makeVTable("orders", &config.Table{
+			Name: "employees.orders",
+			DbRules: []*config.Rule{
+				{
+					Column: "tenant_id",
+					Type:   string(rrule.DirectoryShard),
+					Expr:   it.expr,
+					Directory: &config.Directory{
+						Table: "employees_0000.tenant_directory",
+					},
+				},
+			},
+			TblRules: []*config.Rule{
+				{Column: "tenant_id", Type: string(rrule.ModShard), Expr: "modShard(2)"},
+			},
+			Topology: &config.Topology{
+				DbPattern:  "orders_${0000..0002}",
+				TblPattern: "orders_${0000..0001}",
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, it.expect, vt.Topology().EnumerateDatabases())
+	}
+}
+
+func TestMakeVTable_Broadcast(t *testing.T) {
+	vt, err := makeVTable("country", &config.Table{
+		Name:      "country",
+		Broadcast: true,
+		Topology: &config.Topology{
+			DbPattern: "employees_${0000..0002}",
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, vt.IsBroadcast())
+	assert.True(t, vt.AllowFullScan())
+
+	shards := vt.Topology().Enumerate()
+	assert.Equal(t, 3, shards.Len())
+	for _, db := range []string{"employees_0000", "employees_0001", "employees_0002"} {
+		assert.Equal(t, []string{"country"}, shards[db])
+	}
+
+	_, err = makeVTable("country", &config.Table{
+		Name:      "country",
+		Broadcast: true,
+		DbRules: []*config.Rule{
+			{Column: "id", Type: string(rrule.ModShard), Expr: "modShard(3)"},
+		},
+		Topology: &config.Topology{
+			DbPattern: "employees_${0000..0002}",
+		},
+	})
+	assert.Error(t, err)
+
+	_, err = makeVTable("country", &config.Table{
+		Name:      "country",
+		Broadcast: true,
+	})
+	assert.Error(t, err)
+}
+
+func TestMakeVTable_GlobalIndex(t *testing.T) {
+	makeOrders := func(indexes ...*config.GlobalIndex) (*rule.VTable, error) {
+		return makeVTable("orders", &config.Table{
+			Name: "employees.orders",
+			DbRules: []*config.Rule{
+				{Column: "user_id", Type: string(rrule.ModShard), Expr: "modShard(4)"},
+			},
+			TblRules: []*config.Rule{
+				{Column: "user_id", Type: string(rrule.ModShard), Expr: "modShard(4)"},
+			},
+			Topology: &config.Topology{
+				DbPattern:  "employees_${0000..0003}",
+				TblPattern: "orders_${0000..0003}",
+			},
+			GlobalIndexes: indexes,
+		})
+	}
+
+	vt, err := makeOrders(&config.GlobalIndex{Name: "idx_order_no", Column: "order_no", Table: "employees.orders_order_no"})
+	assert.NoError(t, err)
+	idx, ok := vt.GlobalIndex("idx_order_no")
+	assert.True(t, ok)
+	assert.Equal(t, rule.GlobalIndex{Name: "idx_order_no", Column: "order_no", Table: "orders_order_no"}, *idx)
+
+	for _, it := range []*config.GlobalIndex{
+		{Name: "idx_order_no", Table: "employees.orders_order_no"},
+		{Name: "idx_user_id", Column: "user_id", Table: "employees.orders_user_id"},
+		{Name: "idx_order_no", Column: "order_no", Table: "orders_order_no"},
+		{Name: "idx_order_no", Column: "order_no", Table: "other.orders_order_no"},
+		{Name: "idx_order_no", Column: "order_no", Table: "employees.orders"},
+	} {
+		_, err = makeOrders(it)
+		assert.Error(t, err)
+	}
+}
+
+func TestFilterSingleTables(t *testing.T) {
+	singles, err := filterSingleTables("employees", []*config.SingleTable{
+		{Name: "employees.config", Group: "employees_0001"},
+		{Name: "other.config", Group: "other_0000"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"config": "employees_0001"}, singles)
+
+	_, err = filterSingleTables("employees", []*config.SingleTable{{Name: "employees.config"}})
+	assert.Error(t, err)
+
+	_, err = filterSingleTables("employees", []*config.SingleTable{{Name: "config", Group: "employees_0001"}})
+	assert.Error(t, err)
+}
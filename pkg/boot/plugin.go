@@ -21,6 +21,7 @@ import (
 	_ "github.com/arana-db/arana/pkg/config/etcd"
 	_ "github.com/arana-db/arana/pkg/config/file"
 	_ "github.com/arana-db/arana/pkg/config/nacos"
+	_ "github.com/arana-db/arana/pkg/ddljob"
 	_ "github.com/arana-db/arana/pkg/sequence"
 	_ "github.com/arana-db/arana/pkg/sequence/group"
 	_ "github.com/arana-db/arana/pkg/sequence/snowflake"
@@ -70,6 +70,12 @@ type ConfigProvider interface {
 	// GetTable returns the table info.
 	GetTable(ctx context.Context, tenant, cluster, table string) (*rule.VTable, error)
 
+	// ListBindingTables lists the binding table groups, each group contains the table names.
+	ListBindingTables(ctx context.Context, tenant, cluster string) ([][]string, error)
+
+	// ListSingleTables lists the locations of single tables, table name -> group.
+	ListSingleTables(ctx context.Context, tenant, cluster string) (map[string]string, error)
+
 	// Import import config into config_center
 	Import(ctx context.Context, info *config.Tenant) error
 }
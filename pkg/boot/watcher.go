@@ -165,6 +165,16 @@ L:
 					log.Errorf("[%s] handle event TABLE-CHG failed: %v", d.tenant, err)
 				}
 			}
+			if item.BindingTables != nil {
+				if err := d.onBindingTablesChange(ctx, item.BindingTables); err != nil {
+					log.Errorf("[%s] handle event BINDING-TABLES-CHG failed: %v", d.tenant, err)
+				}
+			}
+			if item.SingleTables != nil {
+				if err := d.onSingleTablesChange(ctx, item.SingleTables); err != nil {
+					log.Errorf("[%s] handle event SINGLE-TABLES-CHG failed: %v", d.tenant, err)
+				}
+			}
 		case item := <-chShadowRules:
 			// TODO: need implementation
 			_ = item
@@ -378,6 +388,19 @@ func (d *watcher) onClusterAdd(ctx context.Context, cluster *config.DataSourceCl
 		}
 		ru.SetVTable(table, vt)
 	}
+
+	bindings, err := d.discovery.ListBindingTables(ctx, d.tenant, cluster.Name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ru.SetBindingTables(bindings...)
+
+	singles, err := d.discovery.ListSingleTables(ctx, d.tenant, cluster.Name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ru.SetSingleTables(singles)
+
 	cmds = append(cmds, namespace.UpdateRule(&ru))
 	ns, err := namespace.New(cluster.Name, cmds...)
 	if err != nil {
@@ -588,3 +611,40 @@ func (d *watcher) onTableChange(ctx context.Context, table *config.Table) error
 
 	return nil
 }
+
+func (d *watcher) onBindingTablesChange(ctx context.Context, bindings []*config.BindingTable) error {
+	for _, cluster := range security.DefaultTenantManager().GetClusters(d.tenant) {
+		ns := namespace.Load(cluster)
+		if ns == nil {
+			continue
+		}
+		groups, err := filterBindingTables(cluster, bindings)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ns.Rule().SetBindingTables(groups...)
+		log.Infof("[%s] BINDING-TABLES-CHG: update binding tables of '%s' successfully", d.tenant, cluster)
+	}
+	return nil
+}
+
+func (d *watcher) onSingleTablesChange(ctx context.Context, singles []*config.SingleTable) error {
+	for _, cluster := range security.DefaultTenantManager().GetClusters(d.tenant) {
+		ns := namespace.Load(cluster)
+		if ns == nil {
+			continue
+		}
+		locations, err := filterSingleTables(cluster, singles)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for table, group := range locations {
+			if !slices.Contains(ns.DBGroups(), group) {
+				return errors.Errorf("no such group '%s' for single table '%s.%s'", group, cluster, table)
+			}
+		}
+		ns.Rule().SetSingleTables(locations)
+		log.Infof("[%s] SINGLE-TABLES-CHG: update single tables of '%s' successfully", d.tenant, cluster)
+	}
+	return nil
+}
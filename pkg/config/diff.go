@@ -257,10 +257,24 @@ func (s *ShardingRule) Diff(old *ShardingRule) *ShardingRuleEvent {
 		}
 	}
 
+	var bindingTables []*BindingTable
+	if !reflect.DeepEqual(s.BindingTables, old.BindingTables) {
+		bindingTables = make([]*BindingTable, 0, len(s.BindingTables))
+		bindingTables = append(bindingTables, s.BindingTables...)
+	}
+
+	var singleTables []*SingleTable
+	if !reflect.DeepEqual(s.SingleTables, old.SingleTables) {
+		singleTables = make([]*SingleTable, 0, len(s.SingleTables))
+		singleTables = append(singleTables, s.SingleTables...)
+	}
+
 	return &ShardingRuleEvent{
-		AddTables:    addTables,
-		UpdateTables: updateTables,
-		DeleteTables: deleteTables,
+		AddTables:     addTables,
+		UpdateTables:  updateTables,
+		DeleteTables:  deleteTables,
+		BindingTables: bindingTables,
+		SingleTables:  singleTables,
 	}
 }
 
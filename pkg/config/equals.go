@@ -127,9 +127,17 @@ func (t *Table) Equals(o *Table) bool {
 		return false
 	}
 
+	if t.Broadcast != o.Broadcast {
+		return false
+	}
+
 	if !reflect.DeepEqual(t.Attributes, o.Attributes) {
 		return false
 	}
 
+	if !reflect.DeepEqual(t.GlobalIndexes, o.GlobalIndexes) {
+		return false
+	}
+
 	return true
 }
@@ -84,6 +84,10 @@ type (
 		AddTables    []*Table
 		UpdateTables []*Table
 		DeleteTables []*Table
+		// BindingTables is the latest binding tables, it is nil if the binding tables are not changed.
+		BindingTables []*BindingTable
+		// SingleTables is the latest single tables, it is nil if the single tables are not changed.
+		SingleTables []*SingleTable
 	}
 
 	// ShadowRuleEvent shadow rule event
@@ -41,6 +41,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+import (
+	utilnet "github.com/arana-db/arana/pkg/util/net"
+)
+
 type (
 	DataRevision interface {
 		Revision() string
@@ -56,16 +60,27 @@ type (
 
 	// SocketAddress specify either a logical or physical address and port, which are
 	// used to tell server where to bind/listen, connect to upstream and find
-	// management servers
+	// management servers.
+	// The address with prefix 'unix:' is a unix domain socket, eg: 'unix:/var/run/arana.sock',
+	// the port will be ignored and the mode(eg: '0660') is the file permission of socket.
 	SocketAddress struct {
 		Address string `default:"0.0.0.0" yaml:"address" json:"address"`
 		Port    int    `default:"13306" yaml:"port" json:"port"`
+		Mode    string `yaml:"mode,omitempty" json:"mode,omitempty"`
 	}
 
 	Listener struct {
 		ProtocolType  string         `yaml:"protocol_type" json:"protocol_type"`
 		SocketAddress *SocketAddress `yaml:"socket_address" json:"socket_address"`
 		ServerVersion string         `yaml:"server_version" json:"server_version"`
+		ProxyProtocol *ProxyProtocol `yaml:"proxy_protocol" json:"proxy_protocol"`
+	}
+
+	// ProxyProtocol specify the HAProxy PROXY protocol(v1/v2) options, the header is required
+	// for connections from the trusted CIDRs, and it will never be parsed for others.
+	ProxyProtocol struct {
+		Enable       bool     `yaml:"enable" json:"enable"`
+		TrustedCIDRs []string `yaml:"trusted_cidrs" json:"trusted_cidrs"`
 	}
 
 	Registry struct {
@@ -82,6 +97,13 @@ type (
 		Registry   *Registry   `yaml:"registry" json:"registry"`
 		Trace      *Trace      `yaml:"trace" json:"trace"`
 		Supervisor *User       `validate:"required,dive" yaml:"supervisor" json:"supervisor"`
+		Shutdown   *Shutdown   `yaml:"shutdown" json:"shutdown"`
+	}
+
+	// Shutdown specify the graceful shutdown options.
+	Shutdown struct {
+		// Timeout is the max duration to wait for the pending transactions, eg: 30s.
+		Timeout time.Duration `yaml:"timeout" json:"timeout"`
 	}
 
 	// Configuration represents an Arana configuration.
@@ -134,7 +156,22 @@ type (
 	}
 
 	ShardingRule struct {
-		Tables []*Table `yaml:"tables" json:"tables"`
+		Tables        []*Table        `yaml:"tables" json:"tables"`
+		BindingTables []*BindingTable `yaml:"binding_tables,omitempty" json:"binding_tables,omitempty"`
+		SingleTables  []*SingleTable  `yaml:"single_tables,omitempty" json:"single_tables,omitempty"`
+	}
+
+	// SingleTable is a table which is not sharded but placed on the specific group,
+	// the tables which are neither sharded nor placed are stored in the first group.
+	SingleTable struct {
+		Name  string `yaml:"name" json:"name"` // format: 'schema.table'
+		Group string `yaml:"group" json:"group"`
+	}
+
+	// BindingTable is a group of sharding tables which are sharded by the same key with the same topology,
+	// eg: order and order_item sharded by order_id, so that they can be joined inside each physical shard.
+	BindingTable struct {
+		Tables []string `yaml:"tables" json:"tables"` // format: 'schema.table'
 	}
 
 	ShadowRule struct {
@@ -176,11 +213,22 @@ type (
 		Name           string            `validate:"required" yaml:"name" json:"name"`
 		Sequence       *Sequence         `yaml:"sequence" json:"sequence"`
 		AllowFullScan  bool              `yaml:"allow_full_scan" json:"allow_full_scan,omitempty"`
+		Broadcast      bool              `yaml:"broadcast" json:"broadcast,omitempty"`
 		DbRules        []*Rule           `yaml:"db_rules" json:"db_rules"`
 		TblRules       []*Rule           `yaml:"tbl_rules" json:"tbl_rules"`
 		Topology       *Topology         `yaml:"topology" json:"topology"`
 		ShadowTopology *Topology         `yaml:"shadow_topology" json:"shadow_topology"`
 		Attributes     map[string]string `yaml:"attributes" json:"attributes"`
+		GlobalIndexes  []*GlobalIndex    `yaml:"global_indexes,omitempty" json:"global_indexes,omitempty"`
+	}
+
+	// GlobalIndex is a global secondary index of the sharding table, the index table is another sharding table
+	// which is sharded by the indexed column, each row of it contains the indexed column and the sharding keys
+	// of the base row, so the rows can be located without the sharding key.
+	GlobalIndex struct {
+		Name   string `yaml:"name" json:"name"`
+		Column string `yaml:"column" json:"column"`
+		Table  string `yaml:"table" json:"table"` // the index table, format: 'schema.table'
 	}
 
 	Sequence struct {
@@ -189,10 +237,21 @@ type (
 	}
 
 	Rule struct {
-		Column string `validate:"required" yaml:"column" json:"column"`
-		Type   string `validate:"required" yaml:"type" json:"type"`
-		Expr   string `validate:"required" yaml:"expr" json:"expr"`
-		Step   int    `yaml:"step" json:"step"`
+		Column    string     `validate:"required" yaml:"column" json:"column"`
+		Type      string     `validate:"required" yaml:"type" json:"type"`
+		Expr      string     `validate:"required" yaml:"expr" json:"expr"`
+		Step      int        `yaml:"step" json:"step"`
+		Directory *Directory `yaml:"directory,omitempty" json:"directory,omitempty"`
+	}
+
+	// Directory is the lookup directory of the directory sharding, the shard of key will be resolved from the
+	// mapping or the mapping table, the unmapped keys will fall back to the algorithm of rule expression.
+	Directory struct {
+		Mapping     map[string]int `yaml:"mapping,omitempty" json:"mapping,omitempty"`           // key -> shard index
+		Table       string         `yaml:"table,omitempty" json:"table,omitempty"`               // the mapping table, format: 'group.table'
+		KeyColumn   string         `yaml:"key_column,omitempty" json:"key_column,omitempty"`     // default: shard_key
+		ShardColumn string         `yaml:"shard_column,omitempty" json:"shard_column,omitempty"` // default: shard_index
+		CacheSize   int            `yaml:"cache_size,omitempty" json:"cache_size,omitempty"`     // size of LRU cache
 	}
 
 	Topology struct {
@@ -422,6 +481,17 @@ func NewEmptyTenant() *Tenant {
 }
 
 func (l *Listener) String() string {
-	socketAddr := fmt.Sprintf("%s:%d", l.SocketAddress.Address, l.SocketAddress.Port)
-	return fmt.Sprintf("Listener protocol_type:%s, socket_address:%s, server_version:%s", l.ProtocolType, socketAddr, l.ServerVersion)
+	return fmt.Sprintf("Listener protocol_type:%s, socket_address:%s, server_version:%s", l.ProtocolType, l.SocketAddress, l.ServerVersion)
+}
+
+// IsUnix returns true if the socket address is a unix domain socket.
+func (sa *SocketAddress) IsUnix() bool {
+	return utilnet.IsUnixAddress(sa.Address)
+}
+
+func (sa *SocketAddress) String() string {
+	if sa.IsUnix() {
+		return sa.Address
+	}
+	return fmt.Sprintf("%s:%d", sa.Address, sa.Port)
 }
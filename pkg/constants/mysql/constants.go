@@ -231,6 +231,12 @@ const (
 	// ComSetOption is COM_SET_OPTION
 	ComSetOption = 0x1b
 
+	// MySQLOptionMultiStatementsOn is MYSQL_OPTION_MULTI_STATEMENTS_ON, the operation of COM_SET_OPTION.
+	MySQLOptionMultiStatementsOn = 0
+
+	// MySQLOptionMultiStatementsOff is MYSQL_OPTION_MULTI_STATEMENTS_OFF, the operation of COM_SET_OPTION.
+	MySQLOptionMultiStatementsOff = 1
+
 	// ComStmtFetch is COM_STMT_FETCH
 	ComStmtFetch = 0x1c
 
@@ -427,6 +433,7 @@ const (
 	ERFeatureDisabled               = 1289
 	EROptionPreventsStatement       = 1290
 	ERDuplicatedValueInType         = 1291
+	ERSPBadSelect                   = 1312
 	ERRowIsReferenced2              = 1451
 	ErNoReferencedRow2              = 1452
 
@@ -560,6 +567,9 @@ const (
 	// SSServerShutdown is ER_SERVER_SHUTDOWN
 	SSServerShutdown = "08S01"
 
+	// SSWrongValueCountOnRow is ER_WRONG_VALUE_COUNT_ON_ROW
+	SSWrongValueCountOnRow = "21S01"
+
 	// SSDataTooLong is ER_DATA_TOO_LONG
 	SSDataTooLong = "22001"
 
@@ -587,6 +597,12 @@ const (
 
 	// SSNoDatabaseSelected is ER_NO_DB
 	SSNoDatabaseSelected = "3D000"
+
+	// SSSyntaxErrorOrAccessViolation is ER_PARSE_ERROR
+	SSSyntaxErrorOrAccessViolation = "42000"
+
+	// SSFeatureNotSupported is ER_SP_BADSELECT
+	SSFeatureNotSupported = "0A000"
 )
 
 // Status flags. They are returned by the server in a few cases.
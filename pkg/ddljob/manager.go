@@ -0,0 +1,513 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddljob
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/bwmarrin/snowflake"
+
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime"
+	"github.com/arana-db/arana/pkg/util/identity"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+const (
+	DefaultConcurrency   = 2 // max executing physical tables of each group
+	DefaultMaxAttempts   = 3
+	DefaultRetryInterval = 3 * time.Second
+
+	// maxFinishedJobs is the max finished jobs of each tenant to be kept.
+	maxFinishedJobs = 64
+)
+
+func init() {
+	proto.RegisterDDLJobManager(NewManager(connectRuntime, NewConfigStore()))
+}
+
+// Connector returns the connection of logical schema, which is used to execute the DDL on physical tables.
+type Connector func(schema string) (proto.VConn, error)
+
+func connectRuntime(schema string) (proto.VConn, error) {
+	return runtime.Load(schema)
+}
+
+type Option func(m *manager)
+
+// WithConcurrency sets the max executing physical tables of each group.
+func WithConcurrency(n int) Option {
+	return func(m *manager) {
+		m.concurrency = n
+	}
+}
+
+// WithMaxAttempts sets the max attempts of the DDL of each physical table.
+func WithMaxAttempts(n int) Option {
+	return func(m *manager) {
+		m.maxAttempts = n
+	}
+}
+
+// WithRetryInterval sets the interval between the attempts.
+func WithRetryInterval(d time.Duration) Option {
+	return func(m *manager) {
+		m.retryInterval = d
+	}
+}
+
+// WithOwner sets the owner of the submitted jobs, the node identity of current proxy will be used by default.
+func WithOwner(owner string) Option {
+	return func(m *manager) {
+		m.owner = owner
+	}
+}
+
+// jobEntry is a job with the lock which guards its states and serializes its persistence.
+type jobEntry struct {
+	mu  sync.Mutex
+	job *proto.DDLJob
+}
+
+type tenantJobs struct {
+	jobs    []*jobEntry // ordered by id
+	resumed bool
+}
+
+type manager struct {
+	connect       Connector
+	store         Store
+	owner         string
+	ids           *snowflake.Node
+	concurrency   int
+	maxAttempts   int
+	retryInterval time.Duration
+
+	// mu guards the tenants and the job lists, the states of each job are guarded by the lock of its entry
+	mu      sync.Mutex
+	tenants map[string]*tenantJobs
+}
+
+// NewManager creates a DDLJobManager, the DDL of each group will be executed with bounded concurrency,
+// and the states will be persisted after each change so that the jobs can be resumed after restart.
+// Each proxy owns the jobs submitted by itself, so the jobs will never be executed by multiple proxies.
+func NewManager(connect Connector, store Store, opts ...Option) proto.DDLJobManager {
+	m := &manager{
+		connect:       connect,
+		store:         store,
+		concurrency:   DefaultConcurrency,
+		maxAttempts:   DefaultMaxAttempts,
+		retryInterval: DefaultRetryInterval,
+		tenants:       make(map[string]*tenantJobs),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if len(m.owner) < 1 {
+		owner, err := identity.GetNodeIdentity()
+		if err != nil {
+			log.Warnf("failed to get node identity for ddl jobs: %v", err)
+			owner = "default"
+		}
+		m.owner = owner
+	}
+
+	// the ids are time-ordered, and they are generated by the node derived from the owner
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(m.owner))
+	m.ids, _ = snowflake.NewNode(int64(h.Sum32() % 1024))
+
+	return m
+}
+
+func (m *manager) Submit(ctx context.Context, job *proto.DDLJob) (int64, error) {
+	if len(job.Tasks) < 1 {
+		return 0, errors.New("no physical table found for ddl job")
+	}
+
+	job.ID = m.ids.Generate().Int64()
+	job.Owner = m.owner
+	job.State = proto.DDLStatePending
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	for _, task := range job.Tasks {
+		task.State = proto.DDLStatePending
+	}
+
+	// the job must be persisted before it's indexed
+	if err := m.store.Save(ctx, job.Tenant, job); err != nil {
+		return 0, errors.Wrap(err, "failed to persist ddl job")
+	}
+
+	m.mu.Lock()
+	tj, err := m.tenant(ctx, job.Tenant)
+	if err != nil {
+		m.mu.Unlock()
+		return 0, err
+	}
+	e := &jobEntry{job: job}
+	tj.jobs = append(tj.jobs, e)
+	removed := tj.trim()
+	if err = m.store.SaveIndex(ctx, job.Tenant, m.owner, tj.ids()); err != nil {
+		log.Errorf("failed to persist ddl jobs of tenant '%s': %v", job.Tenant, err)
+	}
+	m.mu.Unlock()
+
+	for _, id := range removed {
+		if err := m.store.Remove(ctx, job.Tenant, m.owner, id); err != nil {
+			log.Errorf("failed to remove ddl job %d of tenant '%s': %v", id, job.Tenant, err)
+		}
+	}
+
+	log.Infof("submit ddl job %d of %s.%s: tables=%d", job.ID, job.Schema, job.Table, len(job.Tasks))
+
+	go m.run(e)
+
+	return job.ID, nil
+}
+
+func (m *manager) List(ctx context.Context, tenant string) ([]*proto.DDLJob, error) {
+	entries, err := m.entries(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*proto.DDLJob, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entries[i].mu.Lock()
+		ret = append(ret, snapshot(entries[i].job))
+		entries[i].mu.Unlock()
+	}
+	return ret, nil
+}
+
+func (m *manager) Cancel(ctx context.Context, tenant string, id int64) error {
+	entries, err := m.entries(ctx, tenant)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.job.ID != id {
+			continue
+		}
+		return m.update(e, func() error {
+			if e.job.State.IsFinished() {
+				return errors.Errorf("ddl job %d is %s already", id, e.job.State)
+			}
+			// the pending tables will be skipped, and the job will be finished after the running ones
+			for _, task := range e.job.Tasks {
+				if task.State == proto.DDLStatePending {
+					task.State = proto.DDLStateCancelled
+				}
+			}
+			return nil
+		})
+	}
+
+	return errors.Wrapf(proto.ErrorDDLJobNotFound, "cannot cancel ddl job %d", id)
+}
+
+func (m *manager) Resume(ctx context.Context, tenant string) error {
+	m.mu.Lock()
+	tj, err := m.tenant(ctx, tenant)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	if tj.resumed {
+		m.mu.Unlock()
+		return nil
+	}
+	tj.resumed = true
+	entries := append([]*jobEntry(nil), tj.jobs...)
+	m.mu.Unlock()
+
+	for _, e := range entries {
+		var resumed bool
+		_ = m.update(e, func() error {
+			// the jobs of other proxies will never be loaded, check it again for safety
+			if e.job.Owner != m.owner || e.job.State.IsFinished() {
+				return nil
+			}
+			// the tables which were running before restart will be executed again
+			for _, task := range e.job.Tasks {
+				if task.State == proto.DDLStateRunning {
+					task.State = proto.DDLStatePending
+				}
+			}
+			resumed = true
+			return nil
+		})
+		if !resumed {
+			continue
+		}
+		log.Infof("resume ddl job %d of %s.%s", e.job.ID, e.job.Schema, e.job.Table)
+		go m.run(e)
+	}
+
+	return nil
+}
+
+// tenant returns the jobs of tenant, the persisted jobs will be loaded at the first time.
+// It must be called with the lock held.
+func (m *manager) tenant(ctx context.Context, tenant string) (*tenantJobs, error) {
+	if tj, ok := m.tenants[tenant]; ok {
+		return tj, nil
+	}
+
+	jobs, err := m.store.Load(ctx, tenant, m.owner)
+	if err != nil {
+		return nil, err
+	}
+	tj := &tenantJobs{
+		jobs: make([]*jobEntry, 0, len(jobs)),
+	}
+	for _, job := range jobs {
+		tj.jobs = append(tj.jobs, &jobEntry{job: job})
+	}
+	m.tenants[tenant] = tj
+	return tj, nil
+}
+
+// entries returns a copy of the job list of tenant.
+func (m *manager) entries(ctx context.Context, tenant string) ([]*jobEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tj, err := m.tenant(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*jobEntry(nil), tj.jobs...), nil
+}
+
+// update changes the job with the lock of entry held, and then persists the job only if it's changed successfully.
+func (m *manager) update(e *jobEntry, fn func() error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := fn(); err != nil {
+		return err
+	}
+	e.job.UpdatedAt = time.Now()
+	if err := m.store.Save(context.Background(), e.job.Tenant, e.job); err != nil {
+		log.Errorf("failed to persist ddl job %d of tenant '%s': %v", e.job.ID, e.job.Tenant, err)
+	}
+	return nil
+}
+
+// modify is the shortcut of update which never fails.
+func (m *manager) modify(e *jobEntry, fn func()) {
+	_ = m.update(e, func() error {
+		fn()
+		return nil
+	})
+}
+
+func (m *manager) run(e *jobEntry) {
+	job := e.job
+	conn, err := m.connect(job.Schema)
+	if err != nil {
+		m.modify(e, func() {
+			for _, task := range job.Tasks {
+				if !task.State.IsFinished() {
+					task.State = proto.DDLStateFailed
+					task.Error = err.Error()
+				}
+			}
+			job.State = proto.DDLStateFailed
+		})
+		log.Errorf("ddl job %d of %s.%s failed: %v", job.ID, job.Schema, job.Table, err)
+		return
+	}
+
+	var groups []string
+	tasks := make(map[string][]*proto.DDLTask)
+	m.modify(e, func() {
+		job.State = proto.DDLStateRunning
+		for _, task := range job.Tasks {
+			if task.State.IsFinished() {
+				continue
+			}
+			if _, ok := tasks[task.DB]; !ok {
+				groups = append(groups, task.DB)
+			}
+			tasks[task.DB] = append(tasks[task.DB], task)
+		}
+	})
+
+	// execute concurrently for each group, and at most m.concurrency tables of each group at the same time
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		wg.Add(1)
+		go func(tasks []*proto.DDLTask) {
+			defer wg.Done()
+
+			var (
+				running sync.WaitGroup
+				sem     = make(chan struct{}, m.concurrency)
+			)
+			for _, task := range tasks {
+				sem <- struct{}{}
+				running.Add(1)
+				go func(task *proto.DDLTask) {
+					defer func() {
+						<-sem
+						running.Done()
+					}()
+					m.exec(conn, e, task)
+				}(task)
+			}
+			running.Wait()
+		}(tasks[group])
+	}
+	wg.Wait()
+
+	var state proto.DDLState
+	m.modify(e, func() {
+		state = proto.DDLStateDone
+		for _, task := range job.Tasks {
+			switch task.State {
+			case proto.DDLStateFailed:
+				state = proto.DDLStateFailed
+			case proto.DDLStateCancelled:
+				if state == proto.DDLStateDone {
+					state = proto.DDLStateCancelled
+				}
+			}
+		}
+		job.State = state
+	})
+
+	log.Infof("ddl job %d of %s.%s finished: state=%s", job.ID, job.Schema, job.Table, state)
+}
+
+// exec executes the DDL of one physical table, it will be retried until the max attempts.
+func (m *manager) exec(conn proto.VConn, e *jobEntry, task *proto.DDLTask) {
+	job := e.job
+	for {
+		var (
+			cancelled bool
+			attempts  int
+		)
+		m.modify(e, func() {
+			if cancelled = task.State == proto.DDLStateCancelled; cancelled {
+				return
+			}
+			task.State = proto.DDLStateRunning
+			task.Attempts++
+			attempts = task.Attempts
+		})
+		if cancelled {
+			return
+		}
+
+		res, err := conn.Exec(context.Background(), task.DB, task.SQL)
+		if err == nil {
+			resultx.Drain(res)
+		}
+
+		var retry bool
+		m.modify(e, func() {
+			if err == nil {
+				task.State, task.Error = proto.DDLStateDone, ""
+				return
+			}
+			task.Error = err.Error()
+			if attempts >= m.maxAttempts {
+				task.State = proto.DDLStateFailed
+				return
+			}
+			// it may be cancelled before the next attempt
+			task.State, retry = proto.DDLStatePending, true
+		})
+
+		if err != nil {
+			log.Warnf("ddl job %d failed on %s.%s: attempts=%d, err=%v", job.ID, task.DB, task.Table, attempts, err)
+		}
+		if !retry {
+			return
+		}
+		time.Sleep(m.retryInterval)
+	}
+}
+
+// trim removes the oldest finished jobs, returns the ids of removed jobs.
+// It must be called with the lock of manager held.
+func (tj *tenantJobs) trim() []int64 {
+	finished := make([]bool, len(tj.jobs))
+	var cnt int
+	for i, e := range tj.jobs {
+		e.mu.Lock()
+		finished[i] = e.job.State.IsFinished()
+		e.mu.Unlock()
+		if finished[i] {
+			cnt++
+		}
+	}
+
+	if cnt <= maxFinishedJobs {
+		return nil
+	}
+
+	var (
+		removed []int64
+		kept    = tj.jobs[:0]
+	)
+	for i, e := range tj.jobs {
+		if cnt > maxFinishedJobs && finished[i] {
+			cnt--
+			removed = append(removed, e.job.ID)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	tj.jobs = kept
+	return removed
+}
+
+// ids returns the ids of all the jobs, it must be called with the lock of manager held.
+func (tj *tenantJobs) ids() []int64 {
+	ret := make([]int64, 0, len(tj.jobs))
+	for _, e := range tj.jobs {
+		ret = append(ret, e.job.ID)
+	}
+	return ret
+}
+
+func snapshot(job *proto.DDLJob) *proto.DDLJob {
+	ret := new(proto.DDLJob)
+	*ret = *job
+	ret.Tasks = make([]*proto.DDLTask, 0, len(job.Tasks))
+	for _, task := range job.Tasks {
+		t := *task
+		ret.Tasks = append(ret.Tasks, &t)
+	}
+	return ret
+}
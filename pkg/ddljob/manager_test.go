@@ -0,0 +1,305 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddljob
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/golang/mock/gomock"
+
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/testdata"
+)
+
+type memStore struct {
+	sync.Mutex
+	indexes map[string][]int64                 // tenant/owner -> ids
+	jobs    map[string]map[int64]*proto.DDLJob // tenant/owner -> id -> job
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		indexes: make(map[string][]int64),
+		jobs:    make(map[string]map[int64]*proto.DDLJob),
+	}
+}
+
+func (ms *memStore) Load(_ context.Context, tenant, owner string) ([]*proto.DDLJob, error) {
+	ms.Lock()
+	defer ms.Unlock()
+	var ret []*proto.DDLJob
+	for _, id := range ms.indexes[tenant+"/"+owner] {
+		if job, ok := ms.jobs[tenant+"/"+owner][id]; ok {
+			ret = append(ret, snapshot(job))
+		}
+	}
+	return ret, nil
+}
+
+func (ms *memStore) Save(_ context.Context, tenant string, job *proto.DDLJob) error {
+	ms.Lock()
+	defer ms.Unlock()
+	key := tenant + "/" + job.Owner
+	if _, ok := ms.jobs[key]; !ok {
+		ms.jobs[key] = make(map[int64]*proto.DDLJob)
+	}
+	ms.jobs[key][job.ID] = snapshot(job)
+	return nil
+}
+
+func (ms *memStore) Remove(_ context.Context, tenant, owner string, id int64) error {
+	ms.Lock()
+	defer ms.Unlock()
+	delete(ms.jobs[tenant+"/"+owner], id)
+	return nil
+}
+
+func (ms *memStore) SaveIndex(_ context.Context, tenant, owner string, ids []int64) error {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.indexes[tenant+"/"+owner] = append([]int64(nil), ids...)
+	return nil
+}
+
+// seed persists the job as if it's submitted by the owner.
+func (ms *memStore) seed(owner string, job *proto.DDLJob) {
+	job.Owner = owner
+	_ = ms.Save(context.Background(), job.Tenant, job)
+	ms.Lock()
+	ms.indexes[job.Tenant+"/"+owner] = append(ms.indexes[job.Tenant+"/"+owner], job.ID)
+	ms.Unlock()
+}
+
+func newJob(tables ...string) *proto.DDLJob {
+	job := &proto.DDLJob{
+		Tenant: "arana",
+		Schema: "employees",
+		Table:  "student",
+		Query:  "ALTER TABLE `student` ADD COLUMN `age` INT",
+	}
+	for i, table := range tables {
+		db := "employees_0000"
+		if i%2 == 1 {
+			db = "employees_0001"
+		}
+		job.Tasks = append(job.Tasks, &proto.DDLTask{
+			DB:    db,
+			Table: table,
+			SQL:   "ALTER TABLE `" + table + "` ADD COLUMN `age` INT",
+		})
+	}
+	return job
+}
+
+func waitFinished(t *testing.T, m proto.DDLJobManager, id int64) *proto.DDLJob {
+	var ret *proto.DDLJob
+	assert.Eventually(t, func() bool {
+		jobs, err := m.List(context.Background(), "arana")
+		assert.NoError(t, err)
+		for _, job := range jobs {
+			if job.ID == id && job.State.IsFinished() {
+				ret = job
+				return true
+			}
+		}
+		return false
+	}, 3*time.Second, 10*time.Millisecond)
+	return ret
+}
+
+func TestManager(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		mu      sync.Mutex
+		running = make(map[string]int)
+		peak    int
+	)
+	conn := testdata.NewMockVConn(ctrl)
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			mu.Lock()
+			running[db]++
+			if running[db] > peak {
+				peak = running[db]
+			}
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			running[db]--
+			mu.Unlock()
+
+			if strings.Contains(sql, "`student_0003`") {
+				return nil, errors.New("Lock wait timeout exceeded")
+			}
+			return resultx.New(), nil
+		}).AnyTimes()
+
+	store := newMemStore()
+	m := NewManager(func(string) (proto.VConn, error) {
+		return conn, nil
+	}, store, WithOwner("proxy-a"), WithConcurrency(1), WithMaxAttempts(2), WithRetryInterval(time.Millisecond))
+
+	var last int64
+
+	t.Run("done", func(t *testing.T) {
+		id, err := m.Submit(context.Background(), newJob("student_0000", "student_0001", "student_0002"))
+		assert.NoError(t, err)
+		last = id
+
+		job := waitFinished(t, m, id)
+		assert.Equal(t, proto.DDLStateDone, job.State)
+		for _, task := range job.Tasks {
+			assert.Equal(t, proto.DDLStateDone, task.State)
+			assert.Equal(t, 1, task.Attempts)
+		}
+		// at most one table of each group at the same time
+		assert.Equal(t, 1, peak)
+
+		assert.Error(t, m.Cancel(context.Background(), "arana", id))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		id, err := m.Submit(context.Background(), newJob("student_0002", "student_0003"))
+		assert.NoError(t, err)
+		assert.Greater(t, id, last)
+		last = id
+
+		job := waitFinished(t, m, id)
+		assert.Equal(t, proto.DDLStateFailed, job.State)
+		assert.Equal(t, proto.DDLStateDone, job.Tasks[0].State)
+		assert.Equal(t, proto.DDLStateFailed, job.Tasks[1].State)
+		assert.Equal(t, 2, job.Tasks[1].Attempts)
+		assert.Contains(t, job.Tasks[1].Error, "Lock wait timeout")
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		// the proxy restarted while the job was running
+		job := newJob("student_0000", "student_0001", "student_0002")
+		job.ID = last + 1
+		job.State = proto.DDLStateRunning
+		job.Tasks[0].State = proto.DDLStateDone
+		job.Tasks[1].State = proto.DDLStateRunning
+		store.seed("proxy-a", job)
+
+		// the job of another proxy should never be resumed
+		other := newJob("student_0000")
+		other.ID = last + 2
+		other.State = proto.DDLStateRunning
+		store.seed("proxy-b", other)
+
+		restarted := NewManager(func(string) (proto.VConn, error) {
+			return conn, nil
+		}, store, WithOwner("proxy-a"))
+		assert.NoError(t, restarted.Resume(context.Background(), "arana"))
+
+		job = waitFinished(t, restarted, last+1)
+		assert.Equal(t, proto.DDLStateDone, job.State)
+		assert.Equal(t, 0, job.Tasks[0].Attempts)
+		assert.Equal(t, 1, job.Tasks[1].Attempts)
+		assert.Equal(t, 1, job.Tasks[2].Attempts)
+
+		jobs, err := restarted.List(context.Background(), "arana")
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 3)
+		assert.Equal(t, last+1, jobs[0].ID)
+
+		store.Lock()
+		assert.Equal(t, proto.DDLStateRunning, store.jobs["arana/proxy-b"][last+2].State)
+		store.Unlock()
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		slow := testdata.NewMockVConn(ctrl)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		slow.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+				close(started)
+				<-release
+				return resultx.New(), nil
+			}).Times(1)
+
+		m := NewManager(func(string) (proto.VConn, error) {
+			return slow, nil
+		}, newMemStore(), WithConcurrency(1))
+
+		job := newJob("student_0000", "student_0002", "student_0004")
+		for _, task := range job.Tasks {
+			task.DB = "employees_0000"
+		}
+		id, err := m.Submit(context.Background(), job)
+		assert.NoError(t, err)
+
+		<-started
+		assert.NoError(t, m.Cancel(context.Background(), "arana", id))
+		close(release)
+
+		job = waitFinished(t, m, id)
+		assert.Equal(t, proto.DDLStateCancelled, job.State)
+		assert.Equal(t, proto.DDLStateDone, job.Tasks[0].State)
+		assert.Equal(t, proto.DDLStateCancelled, job.Tasks[1].State)
+		assert.Equal(t, proto.DDLStateCancelled, job.Tasks[2].State)
+
+		assert.ErrorIs(t, m.Cancel(context.Background(), "arana", 42), proto.ErrorDDLJobNotFound)
+	})
+	t.Run("multiple proxies", func(t *testing.T) {
+		var (
+			shared = newMemStore()
+			a      = NewManager(func(string) (proto.VConn, error) { return conn, nil }, shared, WithOwner("proxy-a"))
+			b      = NewManager(func(string) (proto.VConn, error) { return conn, nil }, shared, WithOwner("proxy-b"))
+		)
+
+		ida, err := a.Submit(context.Background(), newJob("student_0000"))
+		assert.NoError(t, err)
+		idb, err := b.Submit(context.Background(), newJob("student_0002"))
+		assert.NoError(t, err)
+		waitFinished(t, a, ida)
+		waitFinished(t, b, idb)
+
+		// each proxy persists and lists its own jobs only
+		for owner, id := range map[string]int64{"proxy-a": ida, "proxy-b": idb} {
+			jobs, err := shared.Load(context.Background(), "arana", owner)
+			assert.NoError(t, err)
+			assert.Len(t, jobs, 1)
+			assert.Equal(t, id, jobs[0].ID)
+			assert.Equal(t, owner, jobs[0].Owner)
+		}
+
+		jobs, err := a.List(context.Background(), "arana")
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 1)
+		assert.ErrorIs(t, a.Cancel(context.Background(), "arana", idb), proto.ErrorDDLJobNotFound)
+	})
+}
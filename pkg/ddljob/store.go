@@ -0,0 +1,133 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddljob
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+// Store represents the persistence of DDL jobs, the jobs are persisted separately for each owner,
+// so that the proxies never overwrite the jobs of each other.
+type Store interface {
+	// Load loads all the jobs of the tenant which are owned by the owner.
+	Load(ctx context.Context, tenant, owner string) ([]*proto.DDLJob, error)
+	// Save saves one job of the tenant.
+	Save(ctx context.Context, tenant string, job *proto.DDLJob) error
+	// Remove removes one job of the tenant.
+	Remove(ctx context.Context, tenant, owner string, id int64) error
+	// SaveIndex saves the ids of all the jobs of the tenant which are owned by the owner.
+	SaveIndex(ctx context.Context, tenant, owner string, ids []int64) error
+}
+
+// NewConfigStore creates a Store which persists the jobs into the config store,
+// the jobs will be kept in memory only if the config store is not initialized.
+func NewConfigStore() Store {
+	return configStore{}
+}
+
+type configStore struct{}
+
+func (configStore) Load(_ context.Context, tenant, owner string) ([]*proto.DDLJob, error) {
+	op := config.GetStoreOperate()
+	if op == nil {
+		return nil, nil
+	}
+
+	b, err := op.Get(indexPath(tenant, owner))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(b) < 1 {
+		return nil, nil
+	}
+
+	var ids []int64
+	if err = json.Unmarshal(b, &ids); err != nil {
+		return nil, errors.Wrapf(err, "failed to load ddl jobs of tenant '%s'", tenant)
+	}
+
+	jobs := make([]*proto.DDLJob, 0, len(ids))
+	for _, id := range ids {
+		if b, err = op.Get(jobPath(tenant, owner, id)); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(b) < 1 {
+			continue
+		}
+		job := new(proto.DDLJob)
+		if err = json.Unmarshal(b, job); err != nil {
+			return nil, errors.Wrapf(err, "failed to load ddl job %d of tenant '%s'", id, tenant)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (configStore) Save(_ context.Context, tenant string, job *proto.DDLJob) error {
+	op := config.GetStoreOperate()
+	if op == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(job)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return op.Save(jobPath(tenant, job.Owner, job.ID), b)
+}
+
+func (configStore) Remove(_ context.Context, tenant, owner string, id int64) error {
+	op := config.GetStoreOperate()
+	if op == nil {
+		return nil
+	}
+	return op.Save(jobPath(tenant, owner, id), nil)
+}
+
+func (configStore) SaveIndex(_ context.Context, tenant, owner string, ids []int64) error {
+	op := config.GetStoreOperate()
+	if op == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return op.Save(indexPath(tenant, owner), b)
+}
+
+func indexPath(tenant, owner string) config.PathKey {
+	return config.PathKey(filepath.Join(string(config.NewPathInfo(tenant).DefaultTenantBaseConfigPath), "ddlJobs", owner))
+}
+
+func jobPath(tenant, owner string, id int64) config.PathKey {
+	return config.PathKey(filepath.Join(string(indexPath(tenant, owner)), strconv.FormatInt(id, 10)))
+}
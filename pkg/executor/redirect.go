@@ -49,8 +49,12 @@ import (
 )
 
 var (
-	errMissingTx          = stdErrors.New("no transaction found")
-	errNoDatabaseSelected = mysqlErrors.NewSQLError(mConstants.ERNoDb, mConstants.SSNoDatabaseSelected, "No database selected")
+	errMissingTx               = stdErrors.New("no transaction found")
+	errNoDatabaseSelected      = mysqlErrors.NewSQLError(mConstants.ERNoDb, mConstants.SSNoDatabaseSelected, "No database selected")
+	errMultiStatementsDisabled = mysqlErrors.NewSQLError(mConstants.ERParseError, mConstants.SSSyntaxErrorOrAccessViolation,
+		"You have an error in your SQL syntax; multi-statements is disabled")
+	errMultiResultsDisabled = mysqlErrors.NewSQLError(mConstants.ERSPBadSelect, mConstants.SSFeatureNotSupported,
+		"PROCEDURE can't return a result set in the given context")
 )
 
 var (
@@ -78,6 +82,7 @@ func IsErrMissingTx(err error) bool {
 
 type RedirectExecutor struct {
 	localTransactionMap sync.Map // map[uint32]proto.Tx, (ConnectionID,Tx)
+	lastInsertIDs       sync.Map // map[uint32]uint64, (ConnectionID,LastInsertID)
 }
 
 func NewRedirectExecutor() *RedirectExecutor {
@@ -222,6 +227,7 @@ func (executor *RedirectExecutor) doExecutorComQuery(ctx *proto.Context, act ast
 		if !schemaless || stmt.From == nil {
 			// only SELECT without FROM is allowed in schemaless mode
 			// for example: select connection_id()
+			executor.bindLastInsertID(ctx)
 			if tx, ok := executor.getTx(ctx); ok {
 				res, warn, err = tx.Execute(ctx)
 			} else {
@@ -240,6 +246,9 @@ func (executor *RedirectExecutor) doExecutorComQuery(ctx *proto.Context, act ast
 			} else {
 				res, warn, err = rt.Execute(ctx)
 			}
+			if err == nil {
+				executor.rememberLastInsertID(ctx, res)
+			}
 		}
 	case *ast.ShowStmt:
 		allowSchemaless := func(stmt *ast.ShowStmt) bool {
@@ -258,10 +267,29 @@ func (executor *RedirectExecutor) doExecutorComQuery(ctx *proto.Context, act ast
 		} else {
 			err = errNoDatabaseSelected
 		}
-	case *ast.TruncateTableStmt, *ast.DropTableStmt, *ast.ExplainStmt, *ast.DropIndexStmt, *ast.CreateIndexStmt, *ast.AnalyzeTableStmt, *ast.OptimizeTableStmt:
+	case *ast.TruncateTableStmt, *ast.DropTableStmt, *ast.ExplainStmt, *ast.DropIndexStmt, *ast.CreateIndexStmt, *ast.AnalyzeTableStmt, *ast.OptimizeTableStmt,
+		*ast.CreateTableStmt, *ast.RenameTableStmt:
 		res, warn, err = executeStmt(ctx, schemaless, rt)
-	case *ast.DropTriggerStmt, *ast.SetStmt, *ast.KillStmt:
+	case *ast.DropTriggerStmt, *ast.SetStmt, *ast.KillStmt, *ast.AdminStmt:
 		res, warn, err = rt.Execute(ctx)
+	case *ast.CreateDatabaseStmt:
+		res, warn, err = executeDatabaseStmt(ctx, stmt.Name, rt)
+	case *ast.DropDatabaseStmt:
+		res, warn, err = executeDatabaseStmt(ctx, stmt.Name, rt)
+	case *ast.CallStmt:
+		switch {
+		case schemaless:
+			err = errNoDatabaseSelected
+		case !ctx.MultiResults:
+			// the results of a stored procedure always ends with an extra OK packet
+			err = errMultiResultsDisabled
+		default:
+			if tx, ok := executor.getTx(ctx); ok {
+				res, warn, err = tx.Execute(ctx)
+			} else {
+				res, warn, err = rt.Execute(ctx)
+			}
+		}
 	default:
 		if schemaless {
 			err = errNoDatabaseSelected
@@ -279,7 +307,7 @@ func (executor *RedirectExecutor) doExecutorComQuery(ctx *proto.Context, act ast
 	return res, warn, err
 }
 
-func (executor *RedirectExecutor) ExecutorComQuery(ctx *proto.Context, h func(result proto.Result, warns uint16, failure error) error) error {
+func (executor *RedirectExecutor) ExecutorComQuery(ctx *proto.Context, h proto.ComQueryCallback) error {
 	p := parser.New()
 	query := ctx.GetQuery()
 	log.Debugf("ComQuery: %s", query)
@@ -290,25 +318,30 @@ func (executor *RedirectExecutor) ExecutorComQuery(ctx *proto.Context, h func(re
 	case -1: // no ';' exists
 		stmt, err := p.ParseOneStmt(query, charset, collation)
 		if err != nil {
-			return err
+			return h(nil, 0, err, false)
 		}
 		result, warns, failure := executor.doExecutorComQuery(ctx, stmt)
-		return h(result, warns, failure)
+		return h(result, warns, failure, false)
 	case len(query) - 1: // suffix is ';'
 		ctx.Data = ctx.Data[:len(ctx.Data)-1]
 		stmt, err := p.ParseOneStmt(query[:len(query)-1], charset, collation)
 		if err != nil {
-			return err
+			return h(nil, 0, err, false)
 		}
 		result, warns, failure := executor.doExecutorComQuery(ctx, stmt)
-		return h(result, warns, failure)
+		return h(result, warns, failure, false)
 	}
 
 	// slow path
 	p = parser.New()
 	stmts, _, err := p.Parse(query, charset, collation)
 	if err != nil {
-		return h(nil, 0, err)
+		return h(nil, 0, err, false)
+	}
+
+	// multi-statements is disabled by client, just like MySQL, reject it as a syntax error.
+	if len(stmts) > 1 && !ctx.MultiStatements {
+		return h(nil, 0, errMultiStatementsDisabled, false)
 	}
 
 	for i := range stmts {
@@ -322,10 +355,13 @@ func (executor *RedirectExecutor) ExecutorComQuery(ctx *proto.Context, h func(re
 
 		result, warns, failure := executor.doExecutorComQuery(&ctx2, stmt)
 
-		if err := h(result, warns, failure); err != nil {
+		// NOTICE: the current result must be consumed before executing next statement,
+		// because they may share a same backend connection in transaction.
+		if err := h(result, warns, failure, i < len(stmts)-1); err != nil {
 			return err
 		}
 
+		// stop processing when a statement fails, the error packet is the last one.
 		if failure != nil {
 			break
 		}
@@ -341,6 +377,32 @@ func executeStmt(ctx *proto.Context, schemaless bool, rt runtime.Runtime) (proto
 	return rt.Execute(ctx)
 }
 
+// executeDatabaseStmt executes CREATE/DROP DATABASE in the runtime of the target logical schema,
+// so that it can be fanned out to all the groups of the schema.
+func executeDatabaseStmt(ctx *proto.Context, name string, rt runtime.Runtime) (proto.Result, uint16, error) {
+	if name == ctx.Schema {
+		return rt.Execute(ctx)
+	}
+
+	for _, cluster := range security.DefaultTenantManager().GetClusters(ctx.Tenant) {
+		if cluster != name {
+			continue
+		}
+		target, err := runtime.Load(cluster)
+		if err != nil {
+			return nil, 0, err
+		}
+		schema := ctx.Schema
+		ctx.Schema = cluster
+		defer func() {
+			ctx.Schema = schema
+		}()
+		return target.Execute(ctx)
+	}
+
+	return rt.Execute(ctx)
+}
+
 func (executor *RedirectExecutor) ExecutorComStmtExecute(ctx *proto.Context) (proto.Result, uint16, error) {
 	var (
 		executable proto.Executable
@@ -358,7 +420,9 @@ func (executor *RedirectExecutor) ExecutorComStmtExecute(ctx *proto.Context) (pr
 	}
 
 	switch ctx.Stmt.StmtNode.(type) {
-	case *ast.SelectStmt, *ast.InsertStmt, *ast.UpdateStmt, *ast.DeleteStmt, *ast.AlterTableStmt:
+	case *ast.SelectStmt:
+		executor.bindLastInsertID(ctx)
+	case *ast.InsertStmt, *ast.UpdateStmt, *ast.DeleteStmt, *ast.AlterTableStmt:
 	default:
 		ctx.Context = rcontext.WithDirect(ctx.Context)
 	}
@@ -366,10 +430,21 @@ func (executor *RedirectExecutor) ExecutorComStmtExecute(ctx *proto.Context) (pr
 	query := ctx.Stmt.StmtNode.Text()
 	log.Debugf("ComStmtExecute: %s", query)
 
-	return executable.Execute(ctx)
+	res, warn, err := executable.Execute(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, ok := ctx.Stmt.StmtNode.(*ast.InsertStmt); ok {
+		executor.rememberLastInsertID(ctx, res)
+	}
+
+	return res, warn, nil
 }
 
 func (executor *RedirectExecutor) ConnectionClose(ctx *proto.Context) {
+	executor.lastInsertIDs.Delete(ctx.ConnectionID)
+
 	tx, ok := executor.removeTx(ctx)
 	if !ok {
 		return
@@ -411,3 +486,20 @@ func (executor *RedirectExecutor) getTx(ctx *proto.Context) (proto.Tx, bool) {
 	}
 	return exist.(proto.Tx), true
 }
+
+// rememberLastInsertID saves the last insert id of connection, it won't be changed if no id generated, just like MySQL.
+func (executor *RedirectExecutor) rememberLastInsertID(ctx *proto.Context, res proto.Result) {
+	if _, ok := ctx.Stmt.StmtNode.(*ast.InsertStmt); !ok || res == nil {
+		return
+	}
+	if id, err := res.LastInsertId(); err == nil && id > 0 {
+		executor.lastInsertIDs.Store(ctx.ConnectionID, id)
+	}
+}
+
+// bindLastInsertID binds the last insert id of connection, it will be used by LAST_INSERT_ID().
+func (executor *RedirectExecutor) bindLastInsertID(ctx *proto.Context) {
+	if exist, ok := executor.lastInsertIDs.Load(ctx.ConnectionID); ok {
+		ctx.Context = rcontext.WithLastInsertID(ctx.Context, exist.(uint64))
+	}
+}
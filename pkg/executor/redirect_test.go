@@ -28,6 +28,7 @@ import (
 )
 
 import (
+	mConstants "github.com/arana-db/arana/pkg/constants/mysql"
 	"github.com/arana-db/arana/pkg/proto"
 )
 
@@ -60,3 +61,20 @@ func createContext() *proto.Context {
 	}
 	return result
 }
+
+func TestExecutorComQuery_MultiStatementsDisabled(t *testing.T) {
+	redirect := NewRedirectExecutor()
+	ctx := createContext()
+	ctx.Data = append([]byte{mConstants.ComQuery}, "select 1; select 2"...)
+
+	var called int
+	err := redirect.ExecutorComQuery(ctx, func(result proto.Result, warns uint16, failure error, hasMore bool) error {
+		called++
+		assert.Nil(t, result)
+		assert.False(t, hasMore)
+		assert.Equal(t, errMultiStatementsDisabled, failure)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, called)
+}
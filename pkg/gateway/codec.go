@@ -0,0 +1,160 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+
+	"github.com/shopspring/decimal"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+// param is a typed parameter of statement, the type will be inferred from the JSON value if it is absent.
+//
+// Supported types: null, int, uint, float, decimal, string, bool, time.
+type param struct {
+	Type  string          `json:"type,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+// toValue converts the param to proto.Value.
+func (p *param) toValue() (proto.Value, error) {
+	raw := bytes.TrimSpace(p.Value)
+	if len(raw) < 1 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	typ := strings.ToLower(p.Type)
+
+	if len(typ) < 1 { // infer from the JSON value
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, perrors.Wrapf(err, "invalid param value %s", raw)
+		}
+		switch val := v.(type) {
+		case bool:
+			return proto.NewValueBool(val), nil
+		case string:
+			return proto.NewValueString(val), nil
+		case json.Number:
+			if i, err := val.Int64(); err == nil {
+				return proto.NewValueInt64(i), nil
+			}
+			d, err := decimal.NewFromString(val.String())
+			if err != nil {
+				return nil, perrors.Wrapf(err, "invalid param value %s", raw)
+			}
+			return proto.NewValueDecimal(d), nil
+		default:
+			return nil, perrors.Errorf("unsupported param value %s", raw)
+		}
+	}
+
+	var (
+		s   string
+		err error
+	)
+	// accept both quoted and unquoted literal, eg: {"type":"int","value":"1"}
+	if raw[0] == '"' {
+		err = json.Unmarshal(raw, &s)
+	} else {
+		s = string(raw)
+	}
+	if err != nil {
+		return nil, perrors.Wrapf(err, "invalid param value %s", raw)
+	}
+
+	var v proto.Value
+	switch typ {
+	case "null":
+		return nil, nil
+	case "int":
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, 64); err == nil {
+			v = proto.NewValueInt64(i)
+		}
+	case "uint":
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, 64); err == nil {
+			v = proto.NewValueUint64(u)
+		}
+	case "float":
+		var f float64
+		if f, err = strconv.ParseFloat(s, 64); err == nil {
+			v = proto.NewValueFloat64(f)
+		}
+	case "decimal":
+		var d decimal.Decimal
+		if d, err = decimal.NewFromString(s); err == nil {
+			v = proto.NewValueDecimal(d)
+		}
+	case "string":
+		v = proto.NewValueString(s)
+	case "bool":
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v = proto.NewValueBool(b)
+		}
+	case "time":
+		var t time.Time
+		if t, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			t, err = proto.NewValueString(s).Time()
+		}
+		if err == nil {
+			v = proto.NewValueTime(t)
+		}
+	default:
+		return nil, perrors.Errorf("unsupported param type '%s'", p.Type)
+	}
+
+	if err != nil {
+		return nil, perrors.Wrapf(err, "invalid %s param value %s", typ, raw)
+	}
+	return v, nil
+}
+
+// toJSON converts the proto.Value to the value which can be marshaled as JSON.
+func toJSON(v proto.Value) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch v.Family() {
+	case proto.ValueFamilySign:
+		return v.Int64()
+	case proto.ValueFamilyUnsigned:
+		return v.Uint64()
+	case proto.ValueFamilyFloat:
+		return v.Float64()
+	case proto.ValueFamilyBool:
+		return v.Bool()
+	default:
+		return v.String(), nil
+	}
+}
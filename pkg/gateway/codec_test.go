@@ -0,0 +1,105 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gateway
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+func TestParam_ToValue(t *testing.T) {
+	type tt struct {
+		input  string
+		family proto.ValueFamily
+		expect string
+	}
+
+	for _, it := range []tt{
+		{`{"value":1}`, proto.ValueFamilySign, "1"},
+		{`{"value":1.5}`, proto.ValueFamilyDecimal, "1.5"},
+		{`{"value":"foo"}`, proto.ValueFamilyString, "foo"},
+		{`{"value":true}`, proto.ValueFamilyBool, "1"},
+		{`{"type":"int","value":"42"}`, proto.ValueFamilySign, "42"},
+		{`{"type":"uint","value":18446744073709551615}`, proto.ValueFamilyUnsigned, "18446744073709551615"},
+		{`{"type":"float","value":0.25}`, proto.ValueFamilyFloat, "0.25"},
+		{`{"type":"decimal","value":"3.1415926535897932384626"}`, proto.ValueFamilyDecimal, "3.1415926535897932384626"},
+		{`{"type":"string","value":123}`, proto.ValueFamilyString, "123"},
+		{`{"type":"bool","value":"false"}`, proto.ValueFamilyBool, "0"},
+		{`{"type":"time","value":"2022-01-02 03:04:05"}`, proto.ValueFamilyTime, ""},
+	} {
+		t.Run(it.input, func(t *testing.T) {
+			var p param
+			assert.NoError(t, json.Unmarshal([]byte(it.input), &p))
+			v, err := p.toValue()
+			assert.NoError(t, err)
+			assert.Equal(t, it.family, v.Family())
+			if len(it.expect) > 0 {
+				assert.Equal(t, it.expect, v.String())
+			}
+		})
+	}
+
+	for _, it := range []string{`{"value":null}`, `{"type":"null","value":1}`, `{}`} {
+		var p param
+		assert.NoError(t, json.Unmarshal([]byte(it), &p))
+		v, err := p.toValue()
+		assert.NoError(t, err)
+		assert.Nil(t, v, it)
+	}
+
+	for _, it := range []string{
+		`{"type":"int","value":"abc"}`,
+		`{"type":"bool","value":"maybe"}`,
+		`{"type":"unknown","value":1}`,
+		`{"value":[1,2]}`,
+	} {
+		var p param
+		assert.NoError(t, json.Unmarshal([]byte(it), &p))
+		_, err := p.toValue()
+		assert.Error(t, err, it)
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	now := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, it := range []struct {
+		input  proto.Value
+		expect interface{}
+	}{
+		{nil, nil},
+		{proto.NewValueInt64(-1), int64(-1)},
+		{proto.NewValueUint64(1), uint64(1)},
+		{proto.NewValueFloat64(1.5), 1.5},
+		{proto.NewValueBool(true), true},
+		{proto.NewValueString("foo"), "foo"},
+		{proto.MustNewValueDecimalString("1.10"), "1.1"},
+		{proto.NewValueTime(now), proto.NewValueTime(now).String()},
+	} {
+		v, err := toJSON(it.input)
+		assert.NoError(t, err)
+		assert.Equal(t, it.expect, v)
+	}
+}
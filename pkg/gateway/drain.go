@@ -0,0 +1,86 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gateway
+
+import (
+	"context"
+	"time"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+const _drainCheckInterval = 100 * time.Millisecond
+
+func (l *Listener) inTransaction(connectionID uint32) bool {
+	ctx := &proto.Context{
+		Context:      context.Background(),
+		ConnectionID: connectionID,
+	}
+	return l.executor.InLocalTransaction(ctx) || l.executor.InGlobalTransaction(ctx)
+}
+
+// Drain rejects new requests and sessions, only the sessions in a transaction can go on until they are finished.
+func (l *Listener) Drain(ctx context.Context) []*proto.SessionState {
+	l.draining.Store(true)
+
+	ticker := time.NewTicker(_drainCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		remains := l.sessions.closeIdle(func(s *session) bool {
+			return l.inTransaction(s.connectionID)
+		})
+		if remains == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return l.terminateSessions()
+		case <-ticker.C:
+		}
+	}
+
+	// wait for the running one-shot requests
+	if err := l.server.Shutdown(ctx); err != nil {
+		_ = l.server.Close()
+		return l.terminateSessions()
+	}
+	l.sessions.close()
+
+	log.Infof("http listener %s is drained", l.listener.Addr())
+	return nil
+}
+
+func (l *Listener) terminateSessions() []*proto.SessionState {
+	// cancel the running requests
+	_ = l.server.Close()
+
+	var states []*proto.SessionState
+	for _, s := range l.sessions.list() {
+		states = append(states, &proto.SessionState{
+			ConnectionID:  s.connectionID,
+			Tenant:        s.tenant,
+			Schema:        s.schema,
+			InTransaction: l.inTransaction(s.connectionID),
+		})
+	}
+	l.sessions.close()
+	return states
+}
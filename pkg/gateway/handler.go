@@ -0,0 +1,485 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/gin-gonic/gin"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	mysqlErrors "github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/hint"
+	"github.com/arana-db/arana/pkg/security"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+var (
+	errUnauthorized   = perrors.New("unauthorized")
+	errUnknownSession = perrors.New("unknown session")
+	errDraining       = perrors.New("server is shutting down")
+)
+
+type queryRequest struct {
+	Database string   `json:"database"`
+	SQL      string   `json:"sql" binding:"required"`
+	Params   []*param `json:"params"`
+	Format   string   `json:"format"`
+}
+
+type sessionRequest struct {
+	Database string `json:"database"`
+}
+
+type column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type errorResponse struct {
+	Code     uint16 `json:"code,omitempty"`
+	SQLState string `json:"sql_state,omitempty"`
+	Message  string `json:"message"`
+}
+
+type execResponse struct {
+	AffectedRows uint64 `json:"affected_rows"`
+	LastInsertID uint64 `json:"last_insert_id"`
+	Warnings     uint16 `json:"warnings"`
+}
+
+type queryResponse struct {
+	Columns  []column        `json:"columns"`
+	Rows     [][]interface{} `json:"rows"`
+	Warnings uint16          `json:"warnings"`
+}
+
+// authenticate checks the basic auth of request, returns the tenant and username.
+func authenticate(c *gin.Context, schema string) (string, string, error) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return "", "", errUnauthorized
+	}
+
+	tm := security.DefaultTenantManager()
+	check := func(tenant string) bool {
+		user, ok := tm.GetUser(tenant, username)
+		if !ok {
+			return false
+		}
+		return subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) == 1
+	}
+
+	if len(schema) > 0 {
+		tenant, ok := tm.GetTenantOfCluster(schema)
+		if !ok || !check(tenant) {
+			return "", "", errUnauthorized
+		}
+		return tenant, username, nil
+	}
+
+	// login without schema, reject conflict users like the mysql listener
+	var (
+		tenant string
+		cnt    int
+	)
+	for _, next := range tm.GetTenants() {
+		if check(next) {
+			tenant = next
+			cnt++
+		}
+	}
+	if cnt != 1 {
+		return "", "", errUnauthorized
+	}
+	return tenant, username, nil
+}
+
+func (l *Listener) handleOpenSession(c *gin.Context) {
+	if l.draining.Load() {
+		writeError(c, http.StatusServiceUnavailable, errDraining)
+		return
+	}
+
+	var req sessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	tenant, username, err := authenticate(c, req.Database)
+	if err != nil {
+		writeError(c, http.StatusUnauthorized, err)
+		return
+	}
+
+	s, err := l.sessions.open(l.nextConnectionID(), tenant, username, req.Database)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header(_headerSession, s.token)
+	c.JSON(http.StatusCreated, gin.H{"session": s.token})
+}
+
+func (l *Listener) handleCloseSession(c *gin.Context) {
+	token := c.Param("token")
+	s, ok := l.sessions.acquire(token)
+	if !ok {
+		writeError(c, http.StatusNotFound, errUnknownSession)
+		return
+	}
+	// only the owner can close the session
+	tenant, username, err := authenticate(c, s.schema)
+	l.sessions.release(s)
+	if err != nil || tenant != s.tenant || username != s.username {
+		writeError(c, http.StatusUnauthorized, errUnauthorized)
+		return
+	}
+
+	l.sessions.remove(token)
+	c.Status(http.StatusNoContent)
+}
+
+func (l *Listener) handleQuery(c *gin.Context) {
+	var req queryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := &proto.Context{
+		Context:       c.Request.Context(),
+		ServerVersion: l.conf.ServerVersion,
+		CharacterSet:  mysql.CharacterSetUtf8,
+	}
+	if addr, err := net.ResolveTCPAddr("tcp", c.Request.RemoteAddr); err == nil {
+		ctx.RemoteAddr = addr
+	}
+
+	if token := c.GetHeader(_headerSession); len(token) > 0 {
+		s, ok := l.sessions.acquire(token)
+		if !ok {
+			writeError(c, http.StatusNotFound, errUnknownSession)
+			return
+		}
+		defer l.sessions.release(s)
+
+		// only the pending transactions can go on while draining
+		if l.draining.Load() && !l.inTransaction(s.connectionID) {
+			writeError(c, http.StatusServiceUnavailable, errDraining)
+			return
+		}
+
+		tenant, username, err := authenticate(c, s.schema)
+		if err != nil || tenant != s.tenant || username != s.username {
+			writeError(c, http.StatusUnauthorized, errUnauthorized)
+			return
+		}
+
+		if len(req.Database) > 0 && req.Database != s.schema {
+			if !l.allowDatabase(s.tenant, req.Database) {
+				writeError(c, http.StatusBadRequest, mysqlErrors.NewSQLError(mysql.ERBadDb, "", "Unknown database '%s'", req.Database))
+				return
+			}
+			s.schema = req.Database
+		}
+
+		ctx.ConnectionID = s.connectionID
+		ctx.Tenant = s.tenant
+		ctx.Schema = s.schema
+		ctx.TransientVariables = s.transientVariables
+	} else {
+		if l.draining.Load() {
+			writeError(c, http.StatusServiceUnavailable, errDraining)
+			return
+		}
+
+		tenant, _, err := authenticate(c, req.Database)
+		if err != nil {
+			writeError(c, http.StatusUnauthorized, err)
+			return
+		}
+
+		// one-shot virtual connection, the pending transaction will be rolled back
+		ctx.ConnectionID = l.nextConnectionID()
+		ctx.Tenant = tenant
+		ctx.Schema = req.Database
+		ctx.TransientVariables = make(map[string]proto.Value)
+		defer l.closeConnection(ctx.ConnectionID)
+	}
+
+	ndjson := strings.EqualFold(req.Format, "ndjson") || strings.Contains(c.GetHeader("Accept"), _mimeNDJSON)
+
+	result, warns, err := l.execute(ctx, &req)
+	if err != nil {
+		writeSQLError(c, err)
+		return
+	}
+
+	if ndjson {
+		writeNDJSON(c, result, warns)
+	} else {
+		writeJSON(c, result, warns)
+	}
+}
+
+func (l *Listener) allowDatabase(tenant, db string) bool {
+	for _, it := range security.DefaultTenantManager().GetClusters(tenant) {
+		if it == db {
+			return true
+		}
+	}
+	return false
+}
+
+// execute executes the request, statements without params will be executed as COM_QUERY,
+// otherwise they will be executed as COM_STMT_EXECUTE.
+func (l *Listener) execute(ctx *proto.Context, req *queryRequest) (proto.Result, uint16, error) {
+	if len(req.Params) < 1 {
+		ctx.Data = append([]byte{mysql.ComQuery}, req.SQL...)
+
+		var (
+			result proto.Result
+			warns  uint16
+		)
+		// multiple statements and multiple results are not supported by the gateway
+		err := l.executor.ExecutorComQuery(ctx, func(r proto.Result, w uint16, failure error, _ bool) error {
+			result, warns = r, w
+			return failure
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		if result == nil {
+			return nil, 0, mysqlErrors.NewSQLError(mysql.EREmptyQuery, mysql.SSSyntaxErrorOrAccessViolation, "Query was empty")
+		}
+		return result, warns, nil
+	}
+
+	act, err := parser.New().ParseOneStmt(req.SQL, "", "")
+	if err != nil {
+		return nil, 0, mysqlErrors.NewSQLError(mysql.ERParseError, mysql.SSSyntaxErrorOrAccessViolation, err.Error())
+	}
+
+	stmt := &proto.Stmt{
+		PrepareStmt: req.SQL,
+		StmtNode:    act,
+		ParamsCount: uint16(len(req.Params)),
+		BindVars:    make(map[string]proto.Value, len(req.Params)),
+	}
+
+	for _, it := range act.Hints() {
+		var h *hint.Hint
+		if h, err = hint.Parse(it); err != nil {
+			return nil, 0, err
+		}
+		stmt.Hints = append(stmt.Hints, h)
+	}
+
+	for i, it := range req.Params {
+		var v proto.Value
+		if v, err = it.toValue(); err != nil {
+			return nil, 0, mysqlErrors.NewSQLError(mysql.ERWrongArguments, mysql.SSUnknownSQLState, "param #%d: %s", i+1, err.Error())
+		}
+		stmt.BindVars[fmt.Sprintf("v%d", i+1)] = v
+	}
+
+	ctx.Stmt = stmt
+	return l.executor.ExecutorComStmtExecute(ctx)
+}
+
+func writeError(c *gin.Context, status int, err error) {
+	c.AbortWithStatusJSON(status, errorResponse{Message: err.Error()})
+}
+
+func writeSQLError(c *gin.Context, err error) {
+	var sqlErr *mysqlErrors.SQLError
+	if perrors.As(err, &sqlErr) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse{
+			Code:     uint16(sqlErr.Num),
+			SQLState: sqlErr.State,
+			Message:  sqlErr.Message,
+		})
+		return
+	}
+	log.Errorf("failed to execute http query: %v", err)
+	writeError(c, http.StatusInternalServerError, err)
+}
+
+func columnsOf(fields []proto.Field) []column {
+	columns := make([]column, 0, len(fields))
+	for _, it := range fields {
+		columns = append(columns, column{Name: it.Name(), Type: it.DatabaseTypeName()})
+	}
+	return columns
+}
+
+func scanRow(row proto.Row, size int) ([]interface{}, error) {
+	values := make([]proto.Value, size)
+	if err := row.Scan(values); err != nil {
+		return nil, err
+	}
+	ret := make([]interface{}, size)
+	for i := range values {
+		var err error
+		if ret[i], err = toJSON(values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func writeJSON(c *gin.Context, result proto.Result, warns uint16) {
+	ds, err := result.Dataset()
+	if err != nil {
+		writeSQLError(c, err)
+		return
+	}
+
+	if ds == nil {
+		affected, _ := result.RowsAffected()
+		lastInsertID, _ := result.LastInsertId()
+		c.JSON(http.StatusOK, execResponse{
+			AffectedRows: affected,
+			LastInsertID: lastInsertID,
+			Warnings:     warns,
+		})
+		return
+	}
+
+	defer ds.Close()
+
+	fields, err := ds.Fields()
+	if err != nil {
+		writeSQLError(c, err)
+		return
+	}
+
+	res := queryResponse{
+		Columns:  columnsOf(fields),
+		Rows:     make([][]interface{}, 0),
+		Warnings: warns,
+	}
+
+	for {
+		row, err := ds.Next()
+		if perrors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writeSQLError(c, err)
+			return
+		}
+		values, err := scanRow(row, len(fields))
+		if err != nil {
+			writeSQLError(c, err)
+			return
+		}
+		res.Rows = append(res.Rows, values)
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// writeNDJSON streams the result, the first line is the header, then one line for each row.
+// Errors after the header is written will be reported as the last line.
+func writeNDJSON(c *gin.Context, result proto.Result, warns uint16) {
+	ds, err := result.Dataset()
+	if err != nil {
+		writeSQLError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", _mimeNDJSON)
+
+	if ds == nil {
+		affected, _ := result.RowsAffected()
+		lastInsertID, _ := result.LastInsertId()
+		c.Status(http.StatusOK)
+		_ = json.NewEncoder(c.Writer).Encode(execResponse{
+			AffectedRows: affected,
+			LastInsertID: lastInsertID,
+			Warnings:     warns,
+		})
+		return
+	}
+
+	defer ds.Close()
+
+	fields, err := ds.Fields()
+	if err != nil {
+		writeSQLError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	if err = enc.Encode(gin.H{"columns": columnsOf(fields), "warnings": warns}); err != nil {
+		return
+	}
+	c.Writer.Flush()
+
+	writeErrorLine := func(err error) {
+		var (
+			sqlErr *mysqlErrors.SQLError
+			res    = errorResponse{Message: err.Error()}
+		)
+		if perrors.As(err, &sqlErr) {
+			res = errorResponse{Code: uint16(sqlErr.Num), SQLState: sqlErr.State, Message: sqlErr.Message}
+		}
+		_ = enc.Encode(gin.H{"error": res})
+	}
+
+	for {
+		row, err := ds.Next()
+		if perrors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			writeErrorLine(err)
+			return
+		}
+		values, err := scanRow(row, len(fields))
+		if err != nil {
+			writeErrorLine(err)
+			return
+		}
+		if err = enc.Encode(values); err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+// Package gateway implements an HTTP listener which accepts SQL and typed parameters in JSON,
+// so that clients such as serverless functions can access arana without a MySQL driver.
+//
+// Routes:
+//   - POST   /v1/query: execute a statement, results will be returned as JSON, or NDJSON if 'Accept: application/x-ndjson'.
+//   - POST   /v1/sessions: create a session, the token should be carried by 'X-Arana-Session' header.
+//   - DELETE /v1/sessions/:token: close a session, the pending transaction will be rolled back.
+//
+// All requests must be authenticated by HTTP basic auth with the tenant users.
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"go.uber.org/atomic"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/util/log"
+	utilnet "github.com/arana-db/arana/pkg/util/net"
+)
+
+const (
+	_headerSession = "X-Arana-Session"
+	_mimeNDJSON    = "application/x-ndjson"
+)
+
+const _sessionIdleTimeout = 5 * time.Minute
+
+var _ proto.Listener = (*Listener)(nil)
+
+// Listener is the HTTP/JSON SQL gateway listener.
+type Listener struct {
+	conf     *config.Listener
+	listener net.Listener
+	server   *http.Server
+	executor proto.Executor
+	sessions *sessionManager
+
+	// connectionID is the virtual connection id, every request or session owns one.
+	connectionID atomic.Uint32
+
+	// draining is true if the listener is draining, only the sessions in a transaction will be served.
+	draining atomic.Bool
+}
+
+// NewListener creates an HTTP listener.
+func NewListener(conf *config.Listener) (proto.Listener, error) {
+	l, err := utilnet.Listen(conf.SocketAddress.Address, conf.SocketAddress.Port, conf.SocketAddress.Mode)
+	if err != nil {
+		log.Errorf("listen %s error, %s", conf.SocketAddress, err)
+		return nil, err
+	}
+
+	listener := &Listener{
+		conf:     conf,
+		listener: l,
+	}
+	listener.sessions = newSessionManager(_sessionIdleTimeout, listener.closeConnection)
+
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	v1 := engine.Group("/v1")
+	v1.POST("/query", listener.handleQuery)
+	v1.POST("/sessions", listener.handleOpenSession)
+	v1.DELETE("/sessions/:token", listener.handleCloseSession)
+
+	listener.server = &http.Server{Handler: engine}
+
+	return listener, nil
+}
+
+func (l *Listener) SetExecutor(executor proto.Executor) {
+	l.executor = executor
+}
+
+func (l *Listener) Listen() {
+	log.Infof("start http Listener %s", l.listener.Addr())
+	go l.sessions.run()
+	if err := l.server.Serve(l.listener); err != nil && err != http.ErrServerClosed {
+		log.Errorf("http listener %s stopped: %v", l.listener.Addr(), err)
+	}
+}
+
+func (l *Listener) Close() {
+	_ = l.server.Close()
+	l.sessions.close()
+}
+
+func (l *Listener) nextConnectionID() uint32 {
+	// server-side connection id should always > 0
+	id := l.connectionID.Inc()
+	for id == 0 {
+		id = l.connectionID.Inc()
+	}
+	return id
+}
+
+func (l *Listener) closeConnection(connectionID uint32) {
+	l.executor.ConnectionClose(&proto.Context{
+		Context:      context.Background(),
+		ConnectionID: connectionID,
+	})
+}
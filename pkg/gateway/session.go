@@ -0,0 +1,200 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gateway
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+// session represents a virtual connection, which keeps the transaction and variables between requests.
+type session struct {
+	sync.Mutex
+	token              string
+	connectionID       uint32
+	tenant             string
+	username           string
+	schema             string
+	transientVariables map[string]proto.Value
+	lastActive         time.Time
+}
+
+// sessionManager manages the sessions, the idle sessions will be closed automatically.
+type sessionManager struct {
+	sync.Mutex
+	idleTimeout time.Duration
+	sessions    map[string]*session
+	onClose     func(connectionID uint32)
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+func newSessionManager(idleTimeout time.Duration, onClose func(connectionID uint32)) *sessionManager {
+	return &sessionManager{
+		idleTimeout: idleTimeout,
+		sessions:    make(map[string]*session),
+		onClose:     onClose,
+		done:        make(chan struct{}),
+	}
+}
+
+func (sm *sessionManager) open(connectionID uint32, tenant, username, schema string) (*session, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+
+	s := &session{
+		token:              hex.EncodeToString(b[:]),
+		connectionID:       connectionID,
+		tenant:             tenant,
+		username:           username,
+		schema:             schema,
+		transientVariables: make(map[string]proto.Value),
+		lastActive:         time.Now(),
+	}
+
+	sm.Lock()
+	sm.sessions[s.token] = s
+	sm.Unlock()
+
+	return s, nil
+}
+
+// acquire locks the session of the token, the session must be released after use.
+func (sm *sessionManager) acquire(token string) (*session, bool) {
+	sm.Lock()
+	s, ok := sm.sessions[token]
+	sm.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	s.Lock()
+	// the session may be removed while waiting for the lock
+	sm.Lock()
+	_, ok = sm.sessions[token]
+	sm.Unlock()
+	if !ok {
+		s.Unlock()
+		return nil, false
+	}
+	return s, true
+}
+
+func (sm *sessionManager) release(s *session) {
+	s.lastActive = time.Now()
+	s.Unlock()
+}
+
+// remove removes and closes the session of the token.
+func (sm *sessionManager) remove(token string) (*session, bool) {
+	sm.Lock()
+	s, ok := sm.sessions[token]
+	delete(sm.sessions, token)
+	sm.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	// wait for the running request
+	s.Lock()
+	defer s.Unlock()
+	sm.onClose(s.connectionID)
+	return s, true
+}
+
+func (sm *sessionManager) run() {
+	interval := sm.idleTimeout / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sm.done:
+			return
+		case <-ticker.C:
+			sm.evict(time.Now())
+		}
+	}
+}
+
+// evict closes the sessions which have been idle longer than the idle timeout.
+func (sm *sessionManager) evict(now time.Time) {
+	sm.closeIdle(func(s *session) bool {
+		return now.Sub(s.lastActive) < sm.idleTimeout
+	})
+}
+
+// closeIdle closes the idle sessions unless keep returns true, returns the count of remaining sessions.
+func (sm *sessionManager) closeIdle(keep func(s *session) bool) int {
+	var expired []string
+	sm.Lock()
+	total := len(sm.sessions)
+	for token, s := range sm.sessions {
+		if !s.TryLock() { // in use
+			continue
+		}
+		if !keep(s) {
+			expired = append(expired, token)
+		}
+		s.Unlock()
+	}
+	sm.Unlock()
+
+	for _, token := range expired {
+		sm.remove(token)
+	}
+	return total - len(expired)
+}
+
+// list returns all sessions.
+func (sm *sessionManager) list() []*session {
+	sm.Lock()
+	defer sm.Unlock()
+	ret := make([]*session, 0, len(sm.sessions))
+	for _, s := range sm.sessions {
+		ret = append(ret, s)
+	}
+	return ret
+}
+
+func (sm *sessionManager) close() {
+	sm.closeOnce.Do(func() {
+		close(sm.done)
+	})
+
+	sm.Lock()
+	tokens := make([]string, 0, len(sm.sessions))
+	for token := range sm.sessions {
+		tokens = append(tokens, token)
+	}
+	sm.Unlock()
+
+	for _, token := range tokens {
+		sm.remove(token)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gateway
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionManager(t *testing.T) {
+	var closed []uint32
+	sm := newSessionManager(time.Minute, func(connectionID uint32) {
+		closed = append(closed, connectionID)
+	})
+
+	s1, err := sm.open(1, "arana", "root", "employees")
+	assert.NoError(t, err)
+	s2, err := sm.open(2, "arana", "root", "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, s1.token, s2.token)
+
+	s, ok := sm.acquire(s1.token)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), s.connectionID)
+	sm.release(s)
+
+	_, ok = sm.acquire("unknown")
+	assert.False(t, ok)
+
+	// s2 is idle, s1 is in use
+	s2.lastActive = time.Now().Add(-2 * time.Minute)
+	s, _ = sm.acquire(s1.token)
+	s.lastActive = time.Now().Add(-2 * time.Minute)
+	sm.evict(time.Now())
+	assert.Equal(t, []uint32{2}, closed)
+	sm.release(s)
+
+	_, ok = sm.acquire(s2.token)
+	assert.False(t, ok)
+
+	sm.close()
+	assert.Equal(t, []uint32{2, 1}, closed)
+	_, ok = sm.acquire(s1.token)
+	assert.False(t, ok)
+}
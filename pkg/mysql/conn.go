@@ -110,6 +110,9 @@ type Conn struct {
 	// closed is set to true when Close() is called on the connection.
 	closed *atomic.Bool
 
+	// state is the serving state of server-side connection.
+	state atomic.Int32
+
 	// Packet encoding variables.
 	sequence       uint8
 	bufferedReader *bufio.Reader
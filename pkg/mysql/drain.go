@@ -0,0 +1,149 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package mysql
+
+import (
+	"context"
+	"time"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+// The serving states of server-side connection.
+const (
+	connStateBusy    int32 = iota // handshaking or executing a command
+	connStateIdle                 // waiting for the next command
+	connStateClosing              // closed by draining
+)
+
+const (
+	_drainCheckInterval = 100 * time.Millisecond
+	// _drainForceWait is the max duration to wait for the terminated sessions to exit.
+	_drainForceWait = 3 * time.Second
+)
+
+// track registers the connection, returns false if the listener is draining.
+func (l *Listener) track(c *Conn) bool {
+	l.connsMu.Lock()
+	defer l.connsMu.Unlock()
+	if l.draining.Load() {
+		return false
+	}
+	if l.conns == nil {
+		l.conns = make(map[uint32]*Conn)
+	}
+	l.conns[c.ConnectionID] = c
+	l.handlers.Add(1)
+	return true
+}
+
+func (l *Listener) untrack(c *Conn) {
+	l.connsMu.Lock()
+	delete(l.conns, c.ConnectionID)
+	l.connsMu.Unlock()
+	l.handlers.Done()
+}
+
+func (l *Listener) snapshotConns() []*Conn {
+	l.connsMu.Lock()
+	defer l.connsMu.Unlock()
+	conns := make([]*Conn, 0, len(l.conns))
+	for _, c := range l.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+func (l *Listener) inTransaction(c *Conn) bool {
+	ctx := &proto.Context{
+		Context:      context.Background(),
+		ConnectionID: c.ConnectionID,
+	}
+	return l.executor.InLocalTransaction(ctx) || l.executor.InGlobalTransaction(ctx)
+}
+
+func (l *Listener) Drain(ctx context.Context) []*proto.SessionState {
+	l.connsMu.Lock()
+	l.draining.Store(true)
+	l.connsMu.Unlock()
+
+	// stop accepting new connections
+	_ = l.listener.Close()
+
+	ticker := time.NewTicker(_drainCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if l.closeIdleConns() == 0 {
+			log.Infof("mysql listener %s is drained", l.listener.Addr())
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return l.terminateConns()
+		case <-ticker.C:
+		}
+	}
+}
+
+// closeIdleConns closes the idle connections which are not in a transaction, returns the count of remaining connections.
+func (l *Listener) closeIdleConns() int {
+	var remains int
+	for _, c := range l.snapshotConns() {
+		// the transaction state won't change until the connection becomes busy
+		if l.inTransaction(c) || !c.state.CAS(connStateIdle, connStateClosing) {
+			remains++
+			continue
+		}
+		c.Close()
+	}
+	return remains
+}
+
+// terminateConns closes all remaining connections, the pending transactions will be rolled back.
+func (l *Listener) terminateConns() []*proto.SessionState {
+	var states []*proto.SessionState
+	for _, c := range l.snapshotConns() {
+		state := &proto.SessionState{
+			ConnectionID:  c.ConnectionID,
+			Tenant:        c.Tenant,
+			Schema:        c.Schema,
+			RemoteAddr:    c.RemoteAddr().String(),
+			InTransaction: l.inTransaction(c),
+		}
+		states = append(states, state)
+		c.state.Store(connStateClosing)
+		c.Close()
+	}
+
+	// wait for the sessions to roll back
+	done := make(chan struct{})
+	go func() {
+		l.handlers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(_drainForceWait):
+		log.Warnf("mysql listener %s: some sessions are still running after terminated", l.listener.Addr())
+	}
+
+	return states
+}
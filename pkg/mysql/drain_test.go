@@ -0,0 +1,94 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package mysql
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+type fakeTxExecutor struct {
+	proto.Executor
+	inTx map[uint32]bool
+}
+
+func (f *fakeTxExecutor) InLocalTransaction(ctx *proto.Context) bool {
+	return f.inTx[ctx.ConnectionID]
+}
+
+func (f *fakeTxExecutor) InGlobalTransaction(*proto.Context) bool {
+	return false
+}
+
+func TestListener_Drain(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	listener := &Listener{
+		listener: l,
+		executor: &fakeTxExecutor{inTx: map[uint32]bool{2: true}},
+	}
+
+	newTracked := func(id uint32, state int32) *Conn {
+		server, client := net.Pipe()
+		t.Cleanup(func() { _ = client.Close() })
+		c := newConn(server)
+		c.ConnectionID = id
+		c.Tenant = "arana"
+		c.state.Store(state)
+		assert.True(t, listener.track(c))
+		// simulate the exit of handler
+		go func() {
+			_, _ = server.Read(make([]byte, 1))
+			listener.untrack(c)
+		}()
+		return c
+	}
+
+	idle := newTracked(1, connStateIdle)
+	inTx := newTracked(2, connStateIdle)
+	busy := newTracked(3, connStateBusy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	states := listener.Drain(ctx)
+
+	assert.True(t, idle.IsClosed())
+	assert.True(t, inTx.IsClosed())
+	assert.True(t, busy.IsClosed())
+
+	assert.Len(t, states, 2)
+	for _, it := range states {
+		assert.Equal(t, it.ConnectionID == 2, it.InTransaction)
+	}
+
+	// reject new connections
+	assert.False(t, listener.track(newConn(new(mockConn))))
+	_, err = l.Accept()
+	assert.Error(t, err)
+}
@@ -71,7 +71,7 @@ func (l *Listener) handleInitDB(c *Conn, ctx *proto.Context) error {
 func (l *Listener) handleQuery(c *Conn, ctx *proto.Context) error {
 	c.recycleReadPacket()
 
-	handleOnce := func(result proto.Result, failure error, warn uint16, hasMore bool) error {
+	return l.executor.ExecutorComQuery(ctx, func(result proto.Result, warns uint16, failure error, hasMore bool) error {
 		c.startWriterBuffering()
 		defer func() {
 			if err := c.endWriterBuffering(); err != nil {
@@ -79,105 +79,113 @@ func (l *Listener) handleQuery(c *Conn, ctx *proto.Context) error {
 			}
 		}()
 
-		if failure != nil {
-			log.Errorf("executor com_query error %v: %+v", ctx.ConnectionID, failure)
-			if err := c.writeErrorPacketFromError(failure); err != nil {
-				log.Errorf("Error writing query error to client %v: %v", ctx.ConnectionID, err)
+		// write all results of current statement, a statement such as CALL may produce multiple results.
+		for {
+			next, err := l.writeQueryResult(c, ctx, result, failure, warns, hasMore)
+			if err != nil || next == nil {
 				return err
 			}
-			return nil
+			result, warns = next, 0
 		}
+	})
+}
 
-		if result == nil {
-			log.Errorf("executor com_query error %v: %+v", ctx.ConnectionID, "un dataset")
-			if err := c.writeErrorPacketFromError(errors.NewSQLError(mysql.ERBadNullError, mysql.SSUnknownSQLState, "un dataset")); err != nil {
-				log.Errorf("Error writing query error to client %v: %v", ctx.ConnectionID, failure)
-				return err
-			}
-			return nil
+// writeQueryResult writes a result of COM_QUERY, then returns the next result if exists.
+func (l *Listener) writeQueryResult(c *Conn, ctx *proto.Context, result proto.Result, failure error, warn uint16, hasMore bool) (proto.Result, error) {
+	if failure != nil {
+		log.Errorf("executor com_query error %v: %+v", ctx.ConnectionID, failure)
+		if err := c.writeErrorPacketFromError(failure); err != nil {
+			log.Errorf("Error writing query error to client %v: %v", ctx.ConnectionID, err)
+			return nil, err
 		}
+		return nil, nil
+	}
 
-		var ds proto.Dataset
-		if ds, failure = result.Dataset(); failure != nil {
-			log.Errorf("get dataset error %v: %v", ctx.ConnectionID, failure)
-			if err := c.writeErrorPacketFromError(failure); err != nil {
-				log.Errorf("Error writing query error to client %v: %v", ctx.ConnectionID, err)
-				return err
-			}
-			return nil
+	if result == nil {
+		log.Errorf("executor com_query error %v: %+v", ctx.ConnectionID, "un dataset")
+		if err := c.writeErrorPacketFromError(errors.NewSQLError(mysql.ERBadNullError, mysql.SSUnknownSQLState, "un dataset")); err != nil {
+			log.Errorf("Error writing query error to client %v: %v", ctx.ConnectionID, failure)
+			return nil, err
 		}
+		return nil, nil
+	}
 
-		if ds == nil {
-			// A successful callback with no fields means that this was a
-			// DML or other write-only operation.
-			//
-			// We should not send any more packets after this, but make sure
-			// to extract the affected rows and last insert id from the result
-			// struct here since clients expect it.
-			var (
-				affected, _ = result.RowsAffected()
-				insertId, _ = result.LastInsertId()
-			)
-
-			statusFlag := c.StatusFlags
-			if hasMore {
-				statusFlag |= mysql.ServerMoreResultsExists
-			}
+	// NOTICE: the more flag of a multi-result is available only after the current result is consumed.
+	multi, _ := result.(proto.MultiResult)
+	more := func() bool {
+		return hasMore || (multi != nil && multi.HasMore())
+	}
 
-			if err := c.writeOKPacket(affected, insertId, statusFlag, warn); err != nil {
-				log.Errorf("failed to write OK packet into client %v: %v", ctx.ConnectionID, err)
-				return err
-			}
-			return nil
+	var (
+		ds  proto.Dataset
+		err error
+	)
+	if ds, err = result.Dataset(); err != nil {
+		log.Errorf("get dataset error %v: %v", ctx.ConnectionID, err)
+		if err := c.writeErrorPacketFromError(err); err != nil {
+			log.Errorf("Error writing query error to client %v: %v", ctx.ConnectionID, err)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if ds == nil {
+		// A successful callback with no fields means that this was a
+		// DML or other write-only operation.
+		//
+		// We should not send any more packets after this, but make sure
+		// to extract the affected rows and last insert id from the result
+		// struct here since clients expect it.
+		var (
+			affected, _ = result.RowsAffected()
+			insertId, _ = result.LastInsertId()
+		)
+
+		statusFlag := c.StatusFlags
+		if more() {
+			statusFlag |= mysql.ServerMoreResultsExists
 		}
 
+		if err = c.writeOKPacket(affected, insertId, statusFlag, warn); err != nil {
+			log.Errorf("failed to write OK packet into client %v: %v", ctx.ConnectionID, err)
+			return nil, err
+		}
+	} else {
+		defer func() {
+			_ = ds.Close()
+		}()
+
 		fields, _ := ds.Fields()
 
-		if err := c.writeFields(fields); err != nil {
+		if err = c.writeFields(fields); err != nil {
 			log.Errorf("write fields error %v: %v", ctx.ConnectionID, err)
-			return err
+			return nil, err
 		}
-		if err := c.writeDataset(ds); err != nil {
+		if err = c.writeDataset(ds); err != nil {
 			log.Errorf("write dataset error %v: %v", ctx.ConnectionID, err)
-			return err
+			return nil, err
 		}
-		if err := c.writeEndResult(hasMore, 0, 0, warn); err != nil {
+		if err = c.writeEndResult(more(), 0, 0, warn); err != nil {
 			log.Errorf("Error writing result to %s: %v", c, err)
-			return err
+			return nil, err
 		}
-		return nil
-	}
-	type compositeResult struct {
-		r proto.Result
-		w uint16
-		e error
 	}
 
-	var prev *compositeResult
-	err := l.executor.ExecutorComQuery(ctx, func(result proto.Result, warns uint16, failure error) error {
-		if prev != nil {
-			if err := handleOnce(prev.r, prev.e, prev.w, true); err != nil {
-				return err
-			}
-		}
-		prev = &compositeResult{
-			r: result,
-			w: warns,
-			e: failure,
-		}
-		return nil
-	})
-	if err != nil {
-		return err
+	if multi == nil || !multi.HasMore() {
+		return nil, nil
 	}
 
-	if prev != nil {
-		if err := handleOnce(prev.r, prev.e, prev.w, false); err != nil {
-			return err
+	next, err := multi.NextResult()
+	if err != nil {
+		// the previous result has been sent with SERVER_MORE_RESULTS_EXISTS, so terminate it with an error packet.
+		log.Errorf("get next result error %v: %v", ctx.ConnectionID, err)
+		if err := c.writeErrorPacketFromError(err); err != nil {
+			log.Errorf("Error writing query error to client %v: %v", ctx.ConnectionID, err)
+			return nil, err
 		}
+		return nil, nil
 	}
-
-	return nil
+	return next, nil
 }
 
 func (l *Listener) handleFieldList(c *Conn, ctx *proto.Context) error {
@@ -365,9 +373,9 @@ func (l *Listener) handleSetOption(c *Conn, ctx *proto.Context) error {
 	c.recycleReadPacket()
 	if ok {
 		switch operation {
-		case 0:
+		case mysql.MySQLOptionMultiStatementsOn:
 			c.Capabilities |= mysql.CapabilityClientMultiStatements
-		case 1:
+		case mysql.MySQLOptionMultiStatementsOff:
 			c.Capabilities &^= mysql.CapabilityClientMultiStatements
 		default:
 			log.Errorf("Got unhandled packet (ComSetOption default) from client %v, returning error: %v", ctx.ConnectionID, ctx.Data)
@@ -375,11 +383,14 @@ func (l *Listener) handleSetOption(c *Conn, ctx *proto.Context) error {
 				log.Errorf("Error writing error packet to client: %v", err)
 				return err
 			}
+			return nil
 		}
-		if err := c.writeEndResult(false, 0, 0, 0); err != nil {
-			log.Errorf("Error writeEndResult error %v ", err)
+		// COM_SET_OPTION responds an EOF packet on success.
+		if err := c.writeEOFPacket(c.StatusFlags, 0); err != nil {
+			log.Errorf("Error writeEOFPacket error %v ", err)
 			return err
 		}
+		return nil
 	}
 	log.Errorf("Got unhandled packet (ComSetOption else) from client %v, returning error: %v", ctx.ConnectionID, ctx.Data)
 	if err := c.writeErrorPacket(mysql.ERUnknownComError, mysql.SSUnknownComError, "error handling packet: %v", ctx.Data); err != nil {
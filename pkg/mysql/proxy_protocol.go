@@ -0,0 +1,260 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package mysql
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+// See https://www.haproxy.org/download/2.6/doc/proxy-protocol.txt
+const (
+	_proxyV1Prefix    = "PROXY "
+	_proxyV1MaxLength = 107
+
+	_proxyV2HeaderLength = 16
+	_proxyV2CmdLocal     = 0x0
+	_proxyV2CmdProxy     = 0x1
+	_proxyV2FamilyInet   = 0x1
+	_proxyV2FamilyInet6  = 0x2
+	_proxyV2FamilyUnix   = 0x3
+
+	_proxyHeaderTimeout = 5 * time.Second
+)
+
+var _proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+var errInvalidProxyHeader = perrors.New("invalid PROXY protocol header")
+
+// proxyPolicy decides which connections should carry the PROXY protocol header.
+type proxyPolicy struct {
+	trusted []*net.IPNet
+}
+
+func newProxyPolicy(cidrs []string) (*proxyPolicy, error) {
+	p := &proxyPolicy{trusted: make([]*net.IPNet, 0, len(cidrs))}
+	for _, it := range cidrs {
+		if !strings.Contains(it, "/") { // single ip
+			if ip := net.ParseIP(it); ip != nil && ip.To4() != nil {
+				it += "/32"
+			} else {
+				it += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(it)
+		if err != nil {
+			return nil, perrors.Wrapf(err, "invalid trusted cidr '%s'", it)
+		}
+		p.trusted = append(p.trusted, ipNet)
+	}
+	return p, nil
+}
+
+// isTrusted returns true if the address is in the trusted CIDRs.
+func (p *proxyPolicy) isTrusted(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, it := range p.trusted {
+		if it.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyConn is the connection which reports the real client address from PROXY protocol header.
+type proxyConn struct {
+	net.Conn
+	r          *bufio.Reader
+	remoteAddr net.Addr
+}
+
+func (pc *proxyConn) Read(b []byte) (int, error) {
+	return pc.r.Read(b)
+}
+
+func (pc *proxyConn) RemoteAddr() net.Addr {
+	return pc.remoteAddr
+}
+
+// acceptProxy reads the PROXY protocol header from the connection, returns the wrapped connection.
+func acceptProxy(conn net.Conn) (net.Conn, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(_proxyHeaderTimeout)); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+
+	r := bufio.NewReader(conn)
+	addr, err := readProxyHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+
+	if addr == nil { // LOCAL or UNKNOWN, use the address of connection
+		addr = conn.RemoteAddr()
+	}
+
+	return &proxyConn{
+		Conn:       conn,
+		r:          r,
+		remoteAddr: addr,
+	}, nil
+}
+
+// readProxyHeader reads the PROXY protocol v1/v2 header, returns the source address,
+// nil will be returned if the source address is unknown.
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	// v1 header is at least 15 bytes, eg: 'PROXY UNKNOWN\r\n'
+	b, err := r.Peek(len(_proxyV2Signature))
+	if err != nil {
+		return nil, perrors.Wrap(err, "cannot read PROXY protocol header")
+	}
+
+	switch {
+	case bytes.Equal(b, _proxyV2Signature):
+		return readProxyHeaderV2(r)
+	case bytes.HasPrefix(b, []byte(_proxyV1Prefix)):
+		return readProxyHeaderV1(r)
+	default:
+		return nil, errInvalidProxyHeader
+	}
+}
+
+func readProxyHeaderV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, perrors.Wrap(err, "cannot read PROXY protocol v1 header")
+		}
+		line = append(line, c)
+		if c == '\n' {
+			break
+		}
+		if len(line) >= _proxyV1MaxLength {
+			return nil, errInvalidProxyHeader
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errInvalidProxyHeader
+	}
+
+	// PROXY TCP4 <src> <dst> <sport> <dport>
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) < 2 {
+		return nil, errInvalidProxyHeader
+	}
+
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, errInvalidProxyHeader
+	}
+
+	if len(fields) != 6 {
+		return nil, errInvalidProxyHeader
+	}
+
+	ip := net.ParseIP(fields[2])
+	if ip == nil || (fields[1] == "TCP4") != (ip.To4() != nil) {
+		return nil, errInvalidProxyHeader
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, errInvalidProxyHeader
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+func readProxyHeaderV2(r *bufio.Reader) (net.Addr, error) {
+	var header [_proxyV2HeaderLength]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, perrors.Wrap(err, "cannot read PROXY protocol v2 header")
+	}
+
+	if header[12]>>4 != 0x2 { // version
+		return nil, errInvalidProxyHeader
+	}
+
+	var (
+		cmd    = header[12] & 0x0f
+		family = header[13] >> 4
+		length = binary.BigEndian.Uint16(header[14:])
+	)
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, perrors.Wrap(err, "cannot read PROXY protocol v2 addresses")
+	}
+
+	switch cmd {
+	case _proxyV2CmdLocal: // health check from the proxy itself
+		return nil, nil
+	case _proxyV2CmdProxy:
+	default:
+		return nil, errInvalidProxyHeader
+	}
+
+	// TLVs after the addresses are ignored
+	switch family {
+	case _proxyV2FamilyInet:
+		if len(payload) < 12 {
+			return nil, errInvalidProxyHeader
+		}
+		return &net.TCPAddr{
+			IP:   net.IP(payload[:4]),
+			Port: int(binary.BigEndian.Uint16(payload[8:])),
+		}, nil
+	case _proxyV2FamilyInet6:
+		if len(payload) < 36 {
+			return nil, errInvalidProxyHeader
+		}
+		return &net.TCPAddr{
+			IP:   net.IP(payload[:16]),
+			Port: int(binary.BigEndian.Uint16(payload[32:])),
+		}, nil
+	case _proxyV2FamilyUnix:
+		if len(payload) < 216 {
+			return nil, errInvalidProxyHeader
+		}
+		return &net.UnixAddr{
+			Name: string(bytes.TrimRight(payload[:108], "\x00")),
+			Net:  "unix",
+		}, nil
+	default: // AF_UNSPEC
+		return nil, nil
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package mysql
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadProxyHeaderV1(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("PROXY TCP4 192.168.0.1 192.168.0.11 56324 13306\r\nhello"))
+	addr, err := readProxyHeader(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.0.1:56324", addr.String())
+	rest, _ := io.ReadAll(r)
+	assert.Equal(t, "hello", string(rest))
+
+	addr, err = readProxyHeader(bufio.NewReader(strings.NewReader("PROXY TCP6 2001:db8::1 2001:db8::2 4000 13306\r\n")))
+	assert.NoError(t, err)
+	assert.Equal(t, "[2001:db8::1]:4000", addr.String())
+
+	addr, err = readProxyHeader(bufio.NewReader(strings.NewReader("PROXY UNKNOWN\r\n")))
+	assert.NoError(t, err)
+	assert.Nil(t, addr)
+
+	for _, it := range []string{
+		"PROXY TCP4 192.168.0.1 192.168.0.11 56324\r\n",
+		"PROXY TCP4 2001:db8::1 192.168.0.11 56324 13306\r\n",
+		"PROXY TCP4 192.168.0.1 192.168.0.11 99999 13306\r\n",
+		"PROXY TCP4 192.168.0.1 192.168.0.11 56324 13306\n",
+		"PROXY UDP4 192.168.0.1 192.168.0.11 56324 13306\r\n",
+		"GET / HTTP/1.1\r\n\r\n",
+		"PROXY TCP4 " + strings.Repeat("1", 128) + "\r\n",
+	} {
+		_, err = readProxyHeader(bufio.NewReader(strings.NewReader(it)))
+		assert.Error(t, err, it)
+	}
+}
+
+func TestReadProxyHeaderV2(t *testing.T) {
+	build := func(cmd, family byte, addresses []byte) []byte {
+		var b bytes.Buffer
+		b.Write(_proxyV2Signature)
+		b.WriteByte(0x20 | cmd)
+		b.WriteByte(family<<4 | 0x1)
+		_ = binary.Write(&b, binary.BigEndian, uint16(len(addresses)))
+		b.Write(addresses)
+		return b.Bytes()
+	}
+
+	inet := make([]byte, 12)
+	copy(inet, net.ParseIP("10.0.0.1").To4())
+	copy(inet[4:], net.ParseIP("10.0.0.2").To4())
+	binary.BigEndian.PutUint16(inet[8:], 50000)
+	binary.BigEndian.PutUint16(inet[10:], 13306)
+
+	r := bufio.NewReader(bytes.NewReader(append(build(_proxyV2CmdProxy, _proxyV2FamilyInet, inet), "hello"...)))
+	addr, err := readProxyHeader(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:50000", addr.String())
+	rest, _ := io.ReadAll(r)
+	assert.Equal(t, "hello", string(rest))
+
+	inet6 := make([]byte, 36+8) // with TLVs
+	copy(inet6, net.ParseIP("2001:db8::1"))
+	copy(inet6[16:], net.ParseIP("2001:db8::2"))
+	binary.BigEndian.PutUint16(inet6[32:], 4000)
+	addr, err = readProxyHeader(bufio.NewReader(bytes.NewReader(build(_proxyV2CmdProxy, _proxyV2FamilyInet6, inet6))))
+	assert.NoError(t, err)
+	assert.Equal(t, "[2001:db8::1]:4000", addr.String())
+
+	addr, err = readProxyHeader(bufio.NewReader(bytes.NewReader(build(_proxyV2CmdLocal, 0, nil))))
+	assert.NoError(t, err)
+	assert.Nil(t, addr)
+
+	_, err = readProxyHeader(bufio.NewReader(bytes.NewReader(build(_proxyV2CmdProxy, _proxyV2FamilyInet, inet[:8]))))
+	assert.Error(t, err)
+}
+
+func TestProxyPolicy(t *testing.T) {
+	p, err := newProxyPolicy([]string{"10.0.0.0/8", "192.168.1.1", "::1"})
+	assert.NoError(t, err)
+	assert.True(t, p.isTrusted(&net.TCPAddr{IP: net.ParseIP("10.1.2.3")}))
+	assert.True(t, p.isTrusted(&net.TCPAddr{IP: net.ParseIP("192.168.1.1")}))
+	assert.False(t, p.isTrusted(&net.TCPAddr{IP: net.ParseIP("192.168.1.2")}))
+	assert.True(t, p.isTrusted(&net.TCPAddr{IP: net.ParseIP("::1")}))
+	assert.False(t, p.isTrusted(&net.UnixAddr{Name: "/tmp/arana.sock"}))
+
+	_, err = newProxyPolicy([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
@@ -39,8 +39,9 @@ const (
 )
 
 var (
-	_ proto.Result  = (*RawResult)(nil)
-	_ proto.Dataset = (*RawResult)(nil)
+	_ proto.Result      = (*RawResult)(nil)
+	_ proto.MultiResult = (*RawResult)(nil)
+	_ proto.Dataset     = (*RawResult)(nil)
 )
 
 type RawResult struct {
@@ -98,6 +99,13 @@ func (rr *RawResult) Warn() (uint16, error) {
 
 func (rr *RawResult) Close() error {
 	rr.closeOnce.Do(func() {
+		// NOTICE: the following results must be drained, or the connection cannot be reused.
+		if rr.more {
+			rr.more = false
+			if err := rr.newNextResult().Discard(); err != nil {
+				log.Errorf("failed to discard more mysql results: %v", err)
+			}
+		}
 		if rr.closeFunc == nil {
 			return
 		}
@@ -116,10 +124,30 @@ func (rr *RawResult) Fields() ([]proto.Field, error) {
 	return rr.fields, nil
 }
 
+func (rr *RawResult) HasMore() bool {
+	return rr.more
+}
+
+func (rr *RawResult) NextResult() (proto.Result, error) {
+	if !rr.more {
+		return nil, io.EOF
+	}
+
+	// transfer the closer to the next result, the connection will be released after all results are consumed.
+	next := rr.newNextResult()
+	next.closeFunc = rr.closeFunc
+
+	rr.more = false
+	rr.closeFunc = nil
+	_ = rr.Close()
+
+	return next, nil
+}
+
 func (rr *RawResult) Next() (row proto.Row, err error) {
 	defer func() {
 		if err != nil {
-			_ = rr.Close()
+			rr.autoClose()
 		}
 	}()
 
@@ -157,7 +185,7 @@ func (rr *RawResult) Dataset() (proto.Dataset, error) {
 func (rr *RawResult) preflight() (err error) {
 	defer func() {
 		if err != nil || rr.colNumber < 1 {
-			_ = rr.Close()
+			rr.autoClose()
 		}
 	}()
 
@@ -175,7 +203,7 @@ func (rr *RawResult) flight() (err error) {
 
 	defer func() {
 		if err != nil {
-			_ = rr.Close()
+			rr.autoClose()
 		}
 	}()
 
@@ -214,7 +242,7 @@ func (rr *RawResult) postFlight() (err error) {
 
 	defer func() {
 		if err != nil {
-			_ = rr.Close()
+			rr.autoClose()
 		}
 	}()
 
@@ -301,8 +329,8 @@ func (rr *RawResult) nextRowData() (data []byte, err error) {
 		err = io.EOF
 	case isErrorPacket(data):
 		rr.eof = true
-		data = nil
 		err = ParseErrorPacket(data)
+		data = nil
 	}
 
 	// TODO: Check we're not over the limit before we add more.
@@ -316,6 +344,22 @@ func (rr *RawResult) nextRowData() (data []byte, err error) {
 	return
 }
 
+// autoClose closes the result after it has been consumed, but the connection will be kept
+// if more results follow, which will be released by NextResult or Close.
+func (rr *RawResult) autoClose() {
+	if rr.more {
+		return
+	}
+	_ = rr.Close()
+}
+
+func (rr *RawResult) newNextResult() *RawResult {
+	return &RawResult{
+		c:    rr.c,
+		flag: rr.flag,
+	}
+}
+
 func (rr *RawResult) setTextProtocol() {
 	rr.flag |= _flagTextMode
 }
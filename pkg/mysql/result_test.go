@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package mysql
+
+import (
+	"io"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+func mockOKPackets(affected ...uint8) []byte {
+	var b []byte
+	for i, n := range affected {
+		var status byte
+		if i < len(affected)-1 {
+			status = mysql.ServerMoreResultsExists
+		}
+		// header: length=7, sequence=i
+		b = append(b, 7, 0, 0, byte(i))
+		// payload: OK, affected rows, last insert id, status flags, warnings
+		b = append(b, mysql.OKPacket, n, 0, status, 0, 0, 0)
+	}
+	return b
+}
+
+func TestRawResult_NextResult(t *testing.T) {
+	conn := &BackendConnection{c: newConn(new(mockConn))}
+	conn.c.conn.(*mockConn).data = mockOKPackets(1, 2, 3)
+
+	var closed int
+	res := newResult(conn)
+	res.SetCloser(func() error {
+		closed++
+		return nil
+	})
+
+	var (
+		cur      proto.Result = res
+		affected []uint64
+	)
+	for {
+		n, err := cur.RowsAffected()
+		assert.NoError(t, err)
+		affected = append(affected, n)
+
+		mr := cur.(proto.MultiResult)
+		if !mr.HasMore() {
+			break
+		}
+		assert.Equal(t, 0, closed, "should keep the connection until all results are consumed")
+
+		cur, err = mr.NextResult()
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []uint64{1, 2, 3}, affected)
+	assert.Equal(t, 1, closed)
+
+	_, err := cur.(proto.MultiResult).NextResult()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestRawResult_CloseWithMore(t *testing.T) {
+	conn := &BackendConnection{c: newConn(new(mockConn))}
+	conn.c.conn.(*mockConn).data = mockOKPackets(1, 2, 3)
+
+	var closed int
+	res := newResult(conn)
+	res.SetCloser(func() error {
+		closed++
+		return nil
+	})
+
+	n, err := res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), n)
+	assert.True(t, res.HasMore())
+	assert.Equal(t, 0, closed)
+
+	// should drain the rest results
+	assert.NoError(t, res.Close())
+	assert.False(t, res.HasMore())
+	assert.Equal(t, 1, closed)
+	assert.Equal(t, uint8(3), conn.c.sequence)
+}
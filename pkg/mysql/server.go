@@ -45,6 +45,7 @@ import (
 	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/security"
 	"github.com/arana-db/arana/pkg/util/log"
+	utilnet "github.com/arana-db/arana/pkg/util/net"
 )
 
 const initClientConnStatus = mysql.ServerStatusAutocommit
@@ -72,6 +73,9 @@ type Listener struct {
 
 	executor proto.Executor
 
+	// proxy is the PROXY protocol policy, nil if the PROXY protocol is disabled.
+	proxy *proxyPolicy
+
 	// Incrementing ID for connection id.
 	connectionID uint32
 	// connReadBufferSize is size of buffer for reads from underlying connection.
@@ -83,8 +87,9 @@ type Listener struct {
 	// the client and the server, and currently in use.
 	// It is set during the initial handshake.
 	//
-	// It is only used for CapabilityClientDeprecateEOF
-	// and CapabilityClientFoundRows.
+	// It is only used for CapabilityClientDeprecateEOF,
+	// CapabilityClientFoundRows, CapabilityClientMultiStatements
+	// and CapabilityClientMultiResults.
 	capabilities uint32
 
 	// characterSet is the character set used by the other side of the
@@ -106,6 +111,12 @@ type Listener struct {
 	// stmts is the map to use a prepared statement.
 	// key is uint32 value is *proto.Stmt
 	stmts sync.Map
+
+	// conns is the alive connections, it is used by draining.
+	connsMu  sync.Mutex
+	conns    map[uint32]*Conn
+	draining atomic.Bool
+	handlers sync.WaitGroup
 }
 
 func NewListener(conf *config.Listener) (proto.Listener, error) {
@@ -113,9 +124,9 @@ func NewListener(conf *config.Listener) (proto.Listener, error) {
 		ServerVersion: conf.ServerVersion,
 	}
 
-	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", conf.SocketAddress.Address, conf.SocketAddress.Port))
+	l, err := utilnet.Listen(conf.SocketAddress.Address, conf.SocketAddress.Port, conf.SocketAddress.Mode)
 	if err != nil {
-		log.Errorf("listen %s:%d error, %s", conf.SocketAddress.Address, conf.SocketAddress.Port, err)
+		log.Errorf("listen %s error, %s", conf.SocketAddress, err)
 		return nil, err
 	}
 
@@ -123,6 +134,14 @@ func NewListener(conf *config.Listener) (proto.Listener, error) {
 		conf:     cfg,
 		listener: l,
 	}
+
+	if pp := conf.ProxyProtocol; pp != nil && pp.Enable {
+		if listener.proxy, err = newProxyPolicy(pp.TrustedCIDRs); err != nil {
+			_ = l.Close()
+			return nil, err
+		}
+	}
+
 	return listener, nil
 }
 
@@ -151,12 +170,30 @@ func (l *Listener) Listen() {
 }
 
 func (l *Listener) Close() {
+	// the unix socket file will be removed after closed
+	_ = l.listener.Close()
 }
 
 func (l *Listener) handle(conn net.Conn, connectionID uint32) {
+	// connections from trusted proxies must carry the PROXY protocol header
+	if l.proxy != nil && l.proxy.isTrusted(conn.RemoteAddr()) {
+		pc, err := acceptProxy(conn)
+		if err != nil {
+			log.Errorf("failed to accept PROXY protocol from %s: %v", conn.RemoteAddr(), err)
+			_ = conn.Close()
+			return
+		}
+		conn = pc
+	}
+
 	c := newConn(conn)
 	c.ConnectionID = connectionID
 
+	if !l.track(c) { // draining
+		_ = conn.Close()
+		return
+	}
+
 	// Catch panics, and close the connection in any case.
 	defer func() {
 		if x := recover(); x != nil {
@@ -167,6 +204,7 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32) {
 			Context:      context.Background(),
 			ConnectionID: c.ConnectionID,
 		})
+		l.untrack(c)
 	}()
 
 	err := l.handshake(c)
@@ -186,6 +224,7 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32) {
 		log.Errorf("Cannot write OK packet to %s: %v", c, err)
 		return
 	}
+	c.state.Store(connStateIdle)
 
 	for {
 		c.sequence = 0
@@ -198,6 +237,11 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32) {
 			return
 		}
 
+		// the connection is closed by draining
+		if !c.state.CAS(connStateIdle, connStateBusy) {
+			return
+		}
+
 		content := make([]byte, len(data))
 		copy(content, data)
 		ctx := &proto.Context{
@@ -206,19 +250,24 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32) {
 			Tenant:             c.Tenant,
 			ServerVersion:      l.conf.ServerVersion,
 			ConnectionID:       c.ConnectionID,
+			RemoteAddr:         c.RemoteAddr(),
 			Data:               content,
 			TransientVariables: c.TransientVariables,
 			CharacterSet:       c.CharacterSet,
+			MultiStatements:    c.Capabilities&mysql.CapabilityClientMultiStatements != 0,
+			MultiResults:       c.Capabilities&mysql.CapabilityClientMultiResults != 0,
 		}
 
 		if err = l.ExecuteCommand(c, ctx); err != nil {
 			if err == io.EOF {
-				log.Debugf("the connection#%d of remote client %s requests quit", c.ConnectionID, c.conn.(*net.TCPConn).RemoteAddr())
+				log.Debugf("the connection#%d of remote client %s requests quit", c.ConnectionID, c.RemoteAddr())
 			} else {
 				log.Errorf("failed to execute command: %v", err)
 			}
 			return
 		}
+
+		c.state.CAS(connStateBusy, connStateIdle)
 	}
 }
 
@@ -390,6 +439,11 @@ func (l *Listener) parseClientHandshakePacket(firstTime bool, data []byte) (*han
 		l.capabilities |= mysql.CapabilityClientMultiStatements
 	}
 
+	// set connection capability for returning multi results, CLIENT_MULTI_STATEMENTS implies it
+	if clientFlags&(mysql.CapabilityClientMultiResults|mysql.CapabilityClientMultiStatements) > 0 {
+		l.capabilities |= mysql.CapabilityClientMultiResults
+	}
+
 	// Max packet size. Don't do anything with this now.
 	// See doc.go for more information.
 	_, pos, ok = readUint32(data, pos)
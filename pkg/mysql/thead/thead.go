@@ -32,6 +32,41 @@ var (
 	Database = Thead{
 		Col{Name: "Database", FieldType: consts.FieldTypeVarString},
 	}
+	DDLJobs = Thead{
+		Col{Name: "job_id", FieldType: consts.FieldTypeLongLong},
+		Col{Name: "schema_name", FieldType: consts.FieldTypeVarString},
+		Col{Name: "table_name", FieldType: consts.FieldTypeVarString},
+		Col{Name: "state", FieldType: consts.FieldTypeVarString},
+		Col{Name: "done", FieldType: consts.FieldTypeLongLong},
+		Col{Name: "failed", FieldType: consts.FieldTypeLongLong},
+		Col{Name: "total", FieldType: consts.FieldTypeLongLong},
+		Col{Name: "query", FieldType: consts.FieldTypeVarString},
+		Col{Name: "created_at", FieldType: consts.FieldTypeVarString},
+		Col{Name: "updated_at", FieldType: consts.FieldTypeVarString},
+		Col{Name: "error", FieldType: consts.FieldTypeVarString},
+	}
+	SchemaDrift = Thead{
+		Col{Name: "table_name", FieldType: consts.FieldTypeVarString},
+		Col{Name: "group_name", FieldType: consts.FieldTypeVarString},
+		Col{Name: "physical_table", FieldType: consts.FieldTypeVarString},
+		Col{Name: "kind", FieldType: consts.FieldTypeVarString},
+		Col{Name: "name", FieldType: consts.FieldTypeVarString},
+		Col{Name: "expected", FieldType: consts.FieldTypeVarString},
+		Col{Name: "actual", FieldType: consts.FieldTypeVarString},
+	}
+	GlobalIndexCheck = Thead{
+		Col{Name: "table_name", FieldType: consts.FieldTypeVarString},
+		Col{Name: "index_name", FieldType: consts.FieldTypeVarString},
+		Col{Name: "group_name", FieldType: consts.FieldTypeVarString},
+		Col{Name: "physical_table", FieldType: consts.FieldTypeVarString},
+		Col{Name: "kind", FieldType: consts.FieldTypeVarString},
+		Col{Name: "value", FieldType: consts.FieldTypeVarString},
+		Col{Name: "sharding_keys", FieldType: consts.FieldTypeVarString},
+	}
+	CancelDDLJobs = Thead{
+		Col{Name: "job_id", FieldType: consts.FieldTypeLongLong},
+		Col{Name: "result", FieldType: consts.FieldTypeVarString},
+	}
 )
 
 type Col struct {
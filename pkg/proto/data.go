@@ -103,4 +103,16 @@ type (
 		// query.
 		RowsAffected() (uint64, error)
 	}
+
+	// MultiResult represents a Result which may be followed by more results, eg: the results of CALL.
+	MultiResult interface {
+		Result
+
+		// HasMore returns true if more results follow the current one.
+		// NOTICE: it is reliable only after the current result has been consumed.
+		HasMore() bool
+
+		// NextResult returns the next result, the current one will be discarded.
+		NextResult() (Result, error)
+	}
 )
@@ -0,0 +1,127 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrorDDLJobNotFound = errors.New("ddl job not found")
+
+	_defaultDDLJobManager DDLJobManager
+)
+
+func RegisterDDLJobManager(m DDLJobManager) {
+	_defaultDDLJobManager = m
+}
+
+func LoadDDLJobManager() DDLJobManager {
+	cur := _defaultDDLJobManager
+	if cur == nil {
+		return noopDDLJobManager{}
+	}
+	return cur
+}
+
+const (
+	DDLStatePending   DDLState = iota // waiting to be executed
+	DDLStateRunning                   // executing
+	DDLStateDone                      // executed successfully
+	DDLStateFailed                    // failed after all the retries
+	DDLStateCancelled                 // cancelled before executing
+)
+
+var _ddlStateNames = [...]string{
+	DDLStatePending:   "pending",
+	DDLStateRunning:   "running",
+	DDLStateDone:      "done",
+	DDLStateFailed:    "failed",
+	DDLStateCancelled: "cancelled",
+}
+
+// DDLState represents the state of DDL job or the DDL of one physical table.
+type DDLState uint8
+
+func (s DDLState) String() string {
+	return _ddlStateNames[s]
+}
+
+// IsFinished returns true if the state will never be changed.
+func (s DDLState) IsFinished() bool {
+	return s == DDLStateDone || s == DDLStateFailed || s == DDLStateCancelled
+}
+
+type (
+	// DDLTask represents the DDL of one physical table.
+	DDLTask struct {
+		DB       string   `json:"db"`
+		Table    string   `json:"table"`
+		SQL      string   `json:"sql"`
+		State    DDLState `json:"state"`
+		Attempts int      `json:"attempts"`
+		Error    string   `json:"error,omitempty"`
+	}
+
+	// DDLJob represents an asynchronous DDL of a logical table, which will be executed on all the physical tables.
+	// The job is owned by the proxy which submitted it, and it will be executed, resumed and cancelled by the owner only.
+	DDLJob struct {
+		ID        int64      `json:"id"`
+		Owner     string     `json:"owner"` // the proxy which submitted the job, only the owner executes it
+		Tenant    string     `json:"tenant"`
+		Schema    string     `json:"schema"`
+		Table     string     `json:"table"`
+		Query     string     `json:"query"`
+		State     DDLState   `json:"state"`
+		Tasks     []*DDLTask `json:"tasks"`
+		CreatedAt time.Time  `json:"created_at"`
+		UpdatedAt time.Time  `json:"updated_at"`
+	}
+
+	// DDLJobManager represents the coordinator of asynchronous DDL jobs.
+	DDLJobManager interface {
+		// Submit submits a new job, returns the job id.
+		Submit(ctx context.Context, job *DDLJob) (int64, error)
+		// List returns the snapshots of all jobs of the tenant owned by current proxy, the latest job comes first.
+		List(ctx context.Context, tenant string) ([]*DDLJob, error)
+		// Cancel cancels the job, the running tasks will still be finished.
+		Cancel(ctx context.Context, tenant string, id int64) error
+		// Resume loads the persisted jobs of the tenant owned by current proxy and resumes the unfinished ones.
+		Resume(ctx context.Context, tenant string) error
+	}
+)
+
+type noopDDLJobManager struct{}
+
+func (n noopDDLJobManager) Submit(_ context.Context, _ *DDLJob) (int64, error) {
+	return 0, errors.New("no ddl job manager found")
+}
+
+func (n noopDDLJobManager) List(_ context.Context, _ string) ([]*DDLJob, error) {
+	return nil, nil
+}
+
+func (n noopDDLJobManager) Cancel(_ context.Context, _ string, _ int64) error {
+	return ErrorDDLJobNotFound
+}
+
+func (n noopDDLJobManager) Resume(_ context.Context, _ string) error {
+	return nil
+}
@@ -32,22 +32,28 @@ import (
 )
 
 const (
-	_            Type = iota
-	TypeMaster        // force route to master node
-	TypeSlave         // force route to slave node
-	TypeRoute         // custom route
-	TypeFullScan      // enable full-scan
-	TypeDirect        // direct route
-	TypeTrace         // distributed tracing
+	_              Type = iota
+	TypeMaster          // force route to master node
+	TypeSlave           // force route to slave node
+	TypeRoute           // custom route
+	TypeFullScan        // enable full-scan
+	TypeDirect          // direct route
+	TypeTrace           // distributed tracing
+	TypeAtomic          // wrap the statement in a distributed transaction
+	TypeAsync           // execute the DDL as an asynchronous job
+	TypeShardValue      // route by the given values of sharding keys
 )
 
 var _hintTypes = [...]string{
-	TypeMaster:   "MASTER",
-	TypeSlave:    "SLAVE",
-	TypeRoute:    "ROUTE",
-	TypeFullScan: "FULLSCAN",
-	TypeDirect:   "DIRECT",
-	TypeTrace:    "TRACE",
+	TypeMaster:     "MASTER",
+	TypeSlave:      "SLAVE",
+	TypeRoute:      "ROUTE",
+	TypeFullScan:   "FULLSCAN",
+	TypeDirect:     "DIRECT",
+	TypeTrace:      "TRACE",
+	TypeAtomic:     "ATOMIC",
+	TypeAsync:      "ASYNC",
+	TypeShardValue: "SHARD_VALUE",
 }
 
 // KeyValue represents a pair of key and value.
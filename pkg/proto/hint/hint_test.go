@@ -39,6 +39,9 @@ func TestParse(t *testing.T) {
 		{"not_exist_hint(1,2,3)", "", false},
 		{"route(,,,)", "ROUTE()", true},
 		{"fullscan()", "FULLSCAN()", true},
+		{"atomic", "ATOMIC()", true},
+		{"async", "ASYNC()", true},
+		{"shard_value(student.uid = 7, student.name = 'foo')", "SHARD_VALUE(student.uid=7,student.name='foo')", true},
 		{"route(foo=111,bar=222,qux=333,)", "ROUTE(foo=111,bar=222,qux=333)", true},
 	} {
 		t.Run(next.input, func(t *testing.T) {
@@ -19,6 +19,7 @@ package proto
 
 import (
 	"context"
+	"net"
 	"sort"
 )
 
@@ -45,6 +46,10 @@ type (
 
 		ConnectionID uint32
 
+		// RemoteAddr is the address of client, it will be the real client address if the connection
+		// comes from a trusted proxy with PROXY protocol.
+		RemoteAddr net.Addr
+
 		// sql Data
 		Data []byte
 
@@ -52,16 +57,38 @@ type (
 
 		CharacterSet uint8
 
+		// MultiStatements is true if the client allows multiple statements in one COM_QUERY,
+		// it is negotiated by CLIENT_MULTI_STATEMENTS and can be switched by COM_SET_OPTION.
+		MultiStatements bool
+
+		// MultiResults is true if the client can handle multiple results of one statement, eg: CALL.
+		MultiResults bool
+
 		// TransientVariables stores the transient local variables, it will sync with the remote node automatically.
 		//   - SYSTEM: @@xxx
 		//   - USER: @xxx
 		TransientVariables map[string]Value
 	}
 
+	// ComQueryCallback is called once for each statement of a COM_QUERY, hasMore is true if more statements follow.
+	ComQueryCallback func(result Result, warns uint16, failure error, hasMore bool) error
+
 	Listener interface {
 		SetExecutor(executor Executor)
 		Listen()
 		Close()
+		// Drain stops accepting new connections, closes the idle sessions and waits for the pending
+		// transactions. When the context is done, all remaining sessions will be terminated and reported.
+		Drain(ctx context.Context) []*SessionState
+	}
+
+	// SessionState describes a client session which is terminated forcibly by draining.
+	SessionState struct {
+		ConnectionID  uint32
+		Tenant        string
+		Schema        string
+		RemoteAddr    string
+		InTransaction bool
 	}
 
 	Executor interface {
@@ -70,7 +97,7 @@ type (
 		InGlobalTransaction(ctx *Context) bool
 		ExecuteUseDB(ctx *Context) error
 		ExecuteFieldList(ctx *Context) ([]Field, error)
-		ExecutorComQuery(ctx *Context, callback func(Result, uint16, error) error) error
+		ExecutorComQuery(ctx *Context, callback ComQueryCallback) error
 		ExecutorComStmtExecute(ctx *Context) (Result, uint16, error)
 		ConnectionClose(ctx *Context)
 	}
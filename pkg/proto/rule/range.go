@@ -139,7 +139,15 @@ func (s Stepper) computeRange(offset interface{}, cnt int, reverse bool) (Range,
 			return s.iterTime(cur, cnt, duWeek, reverse), nil
 		}
 	case Umonth:
+		switch cur := offset.(type) {
+		case time.Time:
+			return s.iterMonth(cur, cnt, 1, reverse), nil
+		}
 	case Uyear:
+		switch cur := offset.(type) {
+		case time.Time:
+			return s.iterMonth(cur, cnt, 12, reverse), nil
+		}
 	case Ustr:
 		return &iterStr{length: 16, cnt: cnt}, nil
 	}
@@ -188,7 +196,15 @@ func (s Stepper) compute(offset interface{}, n int) (interface{}, error) {
 			return cur.Add(time.Duration(n) * duWeek), nil
 		}
 	case Umonth:
+		switch cur := offset.(type) {
+		case time.Time:
+			return addMonths(cur, n), nil
+		}
 	case Uyear:
+		switch cur := offset.(type) {
+		case time.Time:
+			return addMonths(cur, 12*n), nil
+		}
 	case Ustr:
 		return &iterStr{length: 16, cnt: n}, nil
 	}
@@ -208,6 +224,18 @@ func (s Stepper) iterTime(offset time.Time, cnt int, timeUnit time.Duration, rev
 	}
 }
 
+func (s Stepper) iterMonth(offset time.Time, cnt int, months int, reverse bool) Range {
+	step := s.N * months
+	if reverse {
+		step *= -1
+	}
+	return &iterMonth{
+		offset: offset,
+		step:   step,
+		cnt:    cnt,
+	}
+}
+
 func (s Stepper) iterInt32(offset int32, cnt int, reverse bool) Range {
 	step := int32(s.N)
 	if reverse {
@@ -342,3 +370,35 @@ func (i *iterTime) Next() interface{} {
 	i.cnt -= 1
 	return prev
 }
+
+type iterMonth struct {
+	offset time.Time
+	step   int // months of each step
+	n      int
+	cnt    int
+}
+
+func (i *iterMonth) HasNext() bool {
+	return i.cnt > 0
+}
+
+func (i *iterMonth) Next() interface{} {
+	if i.cnt == 0 {
+		panic("iterator is exhausted!")
+	}
+	// always compute from the offset, so that the day of month won't drift after clamping, eg: 01-31 -> 02-28 -> 03-31
+	ret := addMonths(i.offset, i.n*i.step)
+	i.n++
+	i.cnt -= 1
+	return ret
+}
+
+// addMonths adds n months to t, the day of month will be clamped to the last day of the target month.
+func addMonths(t time.Time, n int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
@@ -62,6 +62,8 @@ func TestStepper_After(t *testing.T) {
 		{Stepper{N: 1, U: Uhour}, date, parseDate("2022-01-01 01:00:00")},
 		{Stepper{N: 1, U: Uday}, date, parseDate("2022-01-02 00:00:00")},
 		{Stepper{N: 1, U: Uweek}, date, parseDate("2022-01-08 00:00:00")},
+		{Stepper{N: 1, U: Umonth}, parseDate("2022-01-31 00:00:00"), parseDate("2022-02-28 00:00:00")},
+		{Stepper{N: 1, U: Uyear}, parseDate("2020-02-29 00:00:00"), parseDate("2021-02-28 00:00:00")},
 	} {
 		t.Run(it.st.String(), func(t *testing.T) {
 			val, err := it.st.After(it.offset)
@@ -87,6 +89,8 @@ func TestStepper_Before(t *testing.T) {
 		{Stepper{N: 1, U: Uhour}, date, parseDate("2021-12-31 23:00:00")},
 		{Stepper{N: 1, U: Uday}, date, parseDate("2021-12-31 00:00:00")},
 		{Stepper{N: 1, U: Uweek}, date, parseDate("2021-12-25 00:00:00")},
+		{Stepper{N: 1, U: Umonth}, date, parseDate("2021-12-01 00:00:00")},
+		{Stepper{N: 1, U: Uyear}, date, parseDate("2021-01-01 00:00:00")},
 	} {
 		t.Run(it.st.String(), func(t *testing.T) {
 			val, err := it.st.Before(it.offset)
@@ -118,6 +122,8 @@ func TestStepper_Ascend(t *testing.T) {
 		{Stepper{N: 1, U: Unum}, int64(100), 3, []interface{}{int64(100), int64(101), int64(102)}},
 		{Stepper{N: 1, U: Uhour}, date, 3, []interface{}{parseDate("2022-01-01 00:00:00"), parseDate("2022-01-01 01:00:00"), parseDate("2022-01-01 02:00:00")}},
 		{Stepper{N: 1, U: Uday}, date, 3, []interface{}{parseDate("2022-01-01 00:00:00"), parseDate("2022-01-02 00:00:00"), parseDate("2022-01-03 00:00:00")}},
+		{Stepper{N: 1, U: Umonth}, parseDate("2022-01-31 00:00:00"), 3, []interface{}{parseDate("2022-01-31 00:00:00"), parseDate("2022-02-28 00:00:00"), parseDate("2022-03-31 00:00:00")}},
+		{Stepper{N: 1, U: Uyear}, date, 3, []interface{}{parseDate("2022-01-01 00:00:00"), parseDate("2023-01-01 00:00:00"), parseDate("2024-01-01 00:00:00")}},
 	} {
 		t.Run(it.st.String(), func(t *testing.T) {
 			rng, err := it.st.Ascend(it.offset, it.n)
@@ -154,6 +160,7 @@ func TestStepper_Descend(t *testing.T) {
 		{Stepper{N: 1, U: Unum}, int64(100), 3, []interface{}{int64(100), int64(99), int64(98)}},
 		{Stepper{N: 1, U: Uhour}, date, 3, []interface{}{parseDate("2022-01-01 00:00:00"), parseDate("2021-12-31 23:00:00"), parseDate("2021-12-31 22:00:00")}},
 		{Stepper{N: 1, U: Uday}, date, 3, []interface{}{parseDate("2022-01-01 00:00:00"), parseDate("2021-12-31 00:00:00"), parseDate("2021-12-30 00:00:00")}},
+		{Stepper{N: 1, U: Umonth}, parseDate("2022-03-31 00:00:00"), 3, []interface{}{parseDate("2022-03-31 00:00:00"), parseDate("2022-02-28 00:00:00"), parseDate("2022-01-31 00:00:00")}},
 	} {
 		t.Run(it.st.String(), func(t *testing.T) {
 			rng, err := it.st.Descend(it.offset, it.n)
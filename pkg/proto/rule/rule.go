@@ -20,6 +20,7 @@ package rule
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -33,6 +34,9 @@ type (
 		Steps    int           // steps
 		Stepper  Stepper       // stepper
 		Computer ShardComputer // compute shards
+		// Columns is the columns of a composite sharding key, the value to be computed will be
+		// a []interface{} in the same order. It is empty if the sharding key is a single column.
+		Columns []string
 	}
 
 	// ShardComputer computes the shard index from an input value.
@@ -42,14 +46,29 @@ type (
 	}
 
 	DirectShardComputer func(interface{}) (int, error)
+
+	// GlobalIndex represents a global secondary index of the sharding table, the index table is sharded by the
+	// indexed column, and each row of it contains the indexed column and the sharding keys of the base row.
+	GlobalIndex struct {
+		Name   string // name of index
+		Column string // the indexed column
+		Table  string // the logical index table
+	}
 )
 
 func (d DirectShardComputer) Compute(value interface{}) (int, error) {
 	return d(value)
 }
 
+// IsComposite returns true if the shard metadata is computed from multiple columns.
+func (sm *ShardMetadata) IsComposite() bool {
+	return len(sm.Columns) > 1
+}
+
 const (
-	attrAllowFullScan byte = 0x01
+	attrAllowFullScan       byte = 0x01
+	attrAllowUpdateShardKey byte = 0x02
+	attrBroadcast           byte = 0x03
 )
 
 // VTable represents a virtual/logical table.
@@ -59,6 +78,7 @@ type VTable struct {
 	autoIncrement *AutoIncrement
 	topology      *Topology
 	shards        map[string][2]*ShardMetadata // column -> [db shard metadata,table shard metadata]
+	globalIndexes []*GlobalIndex
 }
 
 func (vt *VTable) HasColumn(column string) bool {
@@ -87,6 +107,48 @@ func (vt *VTable) AllowFullScan() bool {
 	return ret
 }
 
+// SetAllowUpdateShardKey sets whether the sharding key can be updated, the rows will be moved between shards.
+func (vt *VTable) SetAllowUpdateShardKey(allow bool) {
+	vt.setAttributeBool(attrAllowUpdateShardKey, allow)
+}
+
+// AllowUpdateShardKey returns true if the sharding key can be updated.
+func (vt *VTable) AllowUpdateShardKey() bool {
+	ret, _ := vt.attributeBool(attrAllowUpdateShardKey)
+	return ret
+}
+
+// SetBroadcast sets whether the table is a broadcast table, which is replicated in every database group.
+func (vt *VTable) SetBroadcast(broadcast bool) {
+	vt.setAttributeBool(attrBroadcast, broadcast)
+}
+
+// IsBroadcast returns true if the table is a broadcast table.
+func (vt *VTable) IsBroadcast() bool {
+	ret, _ := vt.attributeBool(attrBroadcast)
+	return ret
+}
+
+// SetGlobalIndexes sets the global secondary indexes.
+func (vt *VTable) SetGlobalIndexes(indexes ...*GlobalIndex) {
+	vt.globalIndexes = indexes
+}
+
+// GlobalIndexes returns the global secondary indexes.
+func (vt *VTable) GlobalIndexes() []*GlobalIndex {
+	return vt.globalIndexes
+}
+
+// GlobalIndex returns the global secondary index with given name.
+func (vt *VTable) GlobalIndex(name string) (*GlobalIndex, bool) {
+	for _, it := range vt.globalIndexes {
+		if strings.EqualFold(it.Name, name) {
+			return it, true
+		}
+	}
+	return nil, false
+}
+
 func (vt *VTable) GetShardKeys() []string {
 	keys := make([]string, 0, len(vt.shards))
 	for k := range vt.shards {
@@ -125,6 +187,81 @@ func (vt *VTable) Shard(column string, value interface{}) (uint32 /* db */, uint
 	return uint32(db), uint32(table), nil
 }
 
+// ShardBy computes the shards from the values of sharding keys, the composite sharding key will be computed
+// only if all of its columns are given. If only the database or only the table can be computed, the other one
+// will be expanded with the topology. Returns nil if neither of them can be computed.
+func (vt *VTable) ShardBy(values map[string]interface{}) (*Shards, error) {
+	var (
+		indexes [2]int
+		known   [2]bool
+	)
+
+	for column := range values {
+		sm, ok := vt.shards[column]
+		if !ok {
+			continue
+		}
+		for i := range sm {
+			if sm[i] == nil {
+				continue
+			}
+
+			var value interface{}
+			if sm[i].IsComposite() {
+				composite := make([]interface{}, 0, len(sm[i].Columns))
+				for _, it := range sm[i].Columns {
+					v, ok := values[it]
+					if !ok {
+						break
+					}
+					composite = append(composite, v)
+				}
+				if len(composite) < len(sm[i].Columns) {
+					continue
+				}
+				value = composite
+			} else {
+				value = values[column]
+			}
+
+			idx, err := sm[i].Computer.Compute(value)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			// conflict indexes, eg: a = 1 AND b = 2 but they are in different shards
+			if known[i] && indexes[i] != idx {
+				return NewShards(), nil
+			}
+			indexes[i], known[i] = idx, true
+		}
+	}
+
+	ret := NewShards()
+	switch {
+	case known[0] && known[1]:
+		ret.Add(uint32(indexes[0]), uint32(indexes[1]))
+	case known[0]:
+		vt.Topology().Each(func(dbIdx, tbIdx int) bool {
+			if dbIdx == indexes[0] {
+				ret.Add(uint32(dbIdx), uint32(tbIdx))
+			}
+			return true
+		})
+	case known[1]:
+		vt.Topology().Each(func(dbIdx, tbIdx int) bool {
+			if tbIdx == indexes[1] {
+				ret.Add(uint32(dbIdx), uint32(tbIdx))
+			}
+			return true
+		})
+	default:
+		return nil, nil
+	}
+
+	return ret, nil
+}
+
 // GetShardMetadata returns the shard metadata with given column.
 func (vt *VTable) GetShardMetadata(column string) (db *ShardMetadata, tbl *ShardMetadata, ok bool) {
 	var exist [2]*ShardMetadata
@@ -164,8 +301,75 @@ func (vt *VTable) SetName(name string) {
 
 // Rule represents sharding rule, a Rule contains multiple logical tables.
 type Rule struct {
-	mu    sync.RWMutex
-	vtabs map[string]*VTable // table name -> *VTable
+	mu       sync.RWMutex
+	vtabs    map[string]*VTable // table name -> *VTable
+	bindings map[string]int     // table name -> index of binding table group
+	singles  map[string]string  // table name -> group of single table
+}
+
+// SetSingleTables sets the locations of single tables, which are not sharded but placed on the specific groups.
+func (ru *Rule) SetSingleTables(singles map[string]string) {
+	copied := make(map[string]string, len(singles))
+	for k, v := range singles {
+		copied[k] = v
+	}
+	ru.mu.Lock()
+	ru.singles = copied
+	ru.mu.Unlock()
+}
+
+// SingleTable returns the group of the single table.
+func (ru *Rule) SingleTable(table string) (string, bool) {
+	if ru == nil {
+		return "", false
+	}
+	ru.mu.RLock()
+	group, ok := ru.singles[table]
+	ru.mu.RUnlock()
+	return group, ok
+}
+
+// SingleTables returns the locations of all the single tables, table name -> group.
+func (ru *Rule) SingleTables() map[string]string {
+	if ru == nil {
+		return nil
+	}
+	ru.mu.RLock()
+	defer ru.mu.RUnlock()
+	ret := make(map[string]string, len(ru.singles))
+	for k, v := range ru.singles {
+		ret[k] = v
+	}
+	return ret
+}
+
+// SetBindingTables sets the binding table groups, the tables in a group are sharded by the same key with
+// the same topology, so they can be joined inside each physical shard.
+func (ru *Rule) SetBindingTables(groups ...[]string) {
+	bindings := make(map[string]int)
+	for i, group := range groups {
+		for _, table := range group {
+			bindings[table] = i
+		}
+	}
+	ru.mu.Lock()
+	ru.bindings = bindings
+	ru.mu.Unlock()
+}
+
+// IsBindingTable returns true if the two tables are in the same binding table group.
+func (ru *Rule) IsBindingTable(table, other string) bool {
+	if ru == nil {
+		return false
+	}
+	ru.mu.RLock()
+	defer ru.mu.RUnlock()
+	x, ok := ru.bindings[table]
+	if !ok {
+		return false
+	}
+	y, ok := ru.bindings[other]
+	return ok && x == y
 }
 
 // HasColumn returns true if the table and columns exists.
@@ -137,6 +137,49 @@ func TestRule(t *testing.T) {
 	assert.False(t, (*Rule)(nil).Has("student"))
 }
 
+func TestRule_IsBindingTable(t *testing.T) {
+	var ru Rule
+	assert.False(t, ru.IsBindingTable("order", "order_item"))
+
+	ru.SetBindingTables([]string{"order", "order_item"}, []string{"user", "user_address"})
+	assert.True(t, ru.IsBindingTable("order", "order_item"))
+	assert.True(t, ru.IsBindingTable("user_address", "user"))
+	assert.False(t, ru.IsBindingTable("order", "user"))
+	assert.False(t, ru.IsBindingTable("order", "student"))
+
+	ru.SetBindingTables()
+	assert.False(t, ru.IsBindingTable("order", "order_item"))
+}
+
+func TestRule_SingleTable(t *testing.T) {
+	var ru Rule
+	_, ok := ru.SingleTable("config")
+	assert.False(t, ok)
+
+	singles := map[string]string{"config": "employees_0001"}
+	ru.SetSingleTables(singles)
+	singles["config"] = "employees_0002" // should be copied
+
+	group, ok := ru.SingleTable("config")
+	assert.True(t, ok)
+	assert.Equal(t, "employees_0001", group)
+	assert.Equal(t, map[string]string{"config": "employees_0001"}, ru.SingleTables())
+}
+
+func TestVTable_GlobalIndex(t *testing.T) {
+	var vt VTable
+	assert.Empty(t, vt.GlobalIndexes())
+
+	vt.SetGlobalIndexes(&GlobalIndex{Name: "idx_order_no", Column: "order_no", Table: "order_no_idx"})
+	idx, ok := vt.GlobalIndex("IDX_ORDER_NO")
+	assert.True(t, ok)
+	assert.Equal(t, "order_no", idx.Column)
+	assert.Equal(t, "order_no_idx", idx.Table)
+
+	_, ok = vt.GlobalIndex("idx_foo")
+	assert.False(t, ok)
+}
+
 func TestDirectShardComputer_Compute(t *testing.T) {
 	dc := DirectShardComputer(func(i interface{}) (int, error) {
 		n, _ := strconv.Atoi(fmt.Sprintf("%v", i))
@@ -146,3 +189,40 @@ func TestDirectShardComputer_Compute(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, res, "should compute correctly")
 }
+
+func TestVTable_ShardBy(t *testing.T) {
+	mod := func(n int) ShardComputer {
+		return DirectShardComputer(func(i interface{}) (int, error) {
+			return i.(int) % n, nil
+		})
+	}
+
+	var (
+		vtab VTable
+		topo Topology
+	)
+	// 2 databases, each database contains 4 tables
+	topo.SetTopology(0, 0, 1, 2, 3)
+	topo.SetTopology(1, 0, 1, 2, 3)
+	vtab.SetTopology(&topo)
+
+	// database is sharded by tenant_id, table is sharded by order_id
+	vtab.SetShardMetadata("tenant_id", &ShardMetadata{Computer: mod(2)}, nil)
+	vtab.SetShardMetadata("order_id", nil, &ShardMetadata{Computer: mod(4)})
+
+	shards, err := vtab.ShardBy(map[string]interface{}{"tenant_id": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, shards.Len())
+
+	shards, err = vtab.ShardBy(map[string]interface{}{"order_id": 6})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, shards.Len())
+
+	shards, err = vtab.ShardBy(map[string]interface{}{"tenant_id": 3, "order_id": 6})
+	assert.NoError(t, err)
+	assert.Equal(t, "1:2", shards.String())
+
+	shards, err = vtab.ShardBy(map[string]interface{}{"name": "foo"})
+	assert.NoError(t, err)
+	assert.Nil(t, shards)
+}
@@ -36,6 +36,15 @@ type (
 		Version(ctx context.Context) (string, error)
 	}
 
+	// DatabaseSupport executes the statements of physical databases, eg: CREATE/DROP DATABASE.
+	DatabaseSupport interface {
+		// PhysicalDatabase returns the physical database name of the group.
+		PhysicalDatabase(ctx context.Context, group string) (string, error)
+
+		// ExecWithoutSchema executes the statement in the master of group with a new connection without default schema.
+		ExecWithoutSchema(ctx context.Context, group string, query string) (Result, error)
+	}
+
 	// VConn represents a virtual connection which can be used to query/exec from a db.
 	VConn interface {
 		// Query requests a query command.
@@ -43,6 +43,10 @@ func DoRegistry(ctx context.Context, registryInstance base.Registry, name string
 		return fmt.Errorf("service registry register error because get local host err:%v", err)
 	}
 	for _, listener := range listeners {
+		// unix domain socket is only available for local clients
+		if listener.SocketAddress.IsUnix() {
+			continue
+		}
 		tmpLister := *listener
 		if tmpLister.SocketAddress.Address == "0.0.0.0" || tmpLister.SocketAddress.Address == "127.0.0.1" {
 			tmpLister.SocketAddress.Address = serverAddr
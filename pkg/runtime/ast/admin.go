@@ -0,0 +1,158 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	_ Statement = (*ShowDDLJobsStatement)(nil)
+	_ Restorer  = (*ShowDDLJobsStatement)(nil)
+	_ Statement = (*CancelDDLJobsStatement)(nil)
+	_ Restorer  = (*CancelDDLJobsStatement)(nil)
+	_ Statement = (*CheckTableStatement)(nil)
+	_ Restorer  = (*CheckTableStatement)(nil)
+	_ Statement = (*CheckIndexStatement)(nil)
+	_ Restorer  = (*CheckIndexStatement)(nil)
+	_ Statement = (*RecoverIndexStatement)(nil)
+	_ Restorer  = (*RecoverIndexStatement)(nil)
+)
+
+// ShowDDLJobsStatement represents the statement which shows the asynchronous DDL jobs, eg: ADMIN SHOW DDL JOBS [n]
+type ShowDDLJobsStatement struct {
+	Limit int64 // shows the latest n jobs only if it's positive
+}
+
+func (s *ShowDDLJobsStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
+	sb.WriteString("ADMIN SHOW DDL JOBS")
+	if s.Limit > 0 {
+		sb.WriteByte(' ')
+		sb.WriteString(strconv.FormatInt(s.Limit, 10))
+	}
+	return nil
+}
+
+func (s *ShowDDLJobsStatement) CntParams() int {
+	return 0
+}
+
+func (s *ShowDDLJobsStatement) Mode() SQLType {
+	return SQLTypeShowDDLJobs
+}
+
+// CancelDDLJobsStatement represents the statement which cancels the asynchronous DDL jobs, eg: ADMIN CANCEL DDL JOBS 1, 2
+type CancelDDLJobsStatement struct {
+	IDs []int64
+}
+
+func (c *CancelDDLJobsStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
+	sb.WriteString("ADMIN CANCEL DDL JOBS ")
+	for i, id := range c.IDs {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(strconv.FormatInt(id, 10))
+	}
+	return nil
+}
+
+func (c *CancelDDLJobsStatement) CntParams() int {
+	return 0
+}
+
+func (c *CancelDDLJobsStatement) Mode() SQLType {
+	return SQLTypeCancelDDLJobs
+}
+
+// CheckTableStatement represents the statement which checks the schema drift of sharding tables, eg: ADMIN CHECK TABLE student
+type CheckTableStatement struct {
+	Tables []TableName
+}
+
+func (c *CheckTableStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
+	sb.WriteString("ADMIN CHECK TABLE ")
+	for i, it := range c.Tables {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if err := it.Restore(flag, sb, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CheckTableStatement) CntParams() int {
+	return 0
+}
+
+func (c *CheckTableStatement) Mode() SQLType {
+	return SQLTypeCheckTable
+}
+
+// CheckIndexStatement represents the statement which checks the consistency between the sharding table and
+// its global index, eg: ADMIN CHECK INDEX orders idx_order_no
+type CheckIndexStatement struct {
+	Table TableName
+	Index string
+}
+
+func (c *CheckIndexStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
+	sb.WriteString("ADMIN CHECK INDEX ")
+	if err := c.Table.Restore(flag, sb, args); err != nil {
+		return err
+	}
+	sb.WriteByte(' ')
+	WriteID(sb, c.Index)
+	return nil
+}
+
+func (c *CheckIndexStatement) CntParams() int {
+	return 0
+}
+
+func (c *CheckIndexStatement) Mode() SQLType {
+	return SQLTypeCheckIndex
+}
+
+// RecoverIndexStatement represents the statement which rebuilds the entries of global index from the sharding
+// table, eg: ADMIN RECOVER INDEX orders idx_order_no
+type RecoverIndexStatement struct {
+	Table TableName
+	Index string
+}
+
+func (r *RecoverIndexStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
+	sb.WriteString("ADMIN RECOVER INDEX ")
+	if err := r.Table.Restore(flag, sb, args); err != nil {
+		return err
+	}
+	sb.WriteByte(' ')
+	WriteID(sb, r.Index)
+	return nil
+}
+
+func (r *RecoverIndexStatement) CntParams() int {
+	return 0
+}
+
+func (r *RecoverIndexStatement) Mode() SQLType {
+	return SQLTypeRecoverIndex
+}
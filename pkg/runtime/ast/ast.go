@@ -26,6 +26,7 @@ import (
 import (
 	"github.com/arana-db/parser"
 	"github.com/arana-db/parser/ast"
+	"github.com/arana-db/parser/format"
 	"github.com/arana-db/parser/mysql"
 	"github.com/arana-db/parser/opcode"
 	"github.com/arana-db/parser/test_driver"
@@ -81,12 +82,22 @@ func FromStmtNode(node ast.StmtNode) (Statement, error) {
 		return cc.convUnionStmt(stmt), nil
 	case *ast.DeleteStmt:
 		if stmt.IsMultiTable {
-			return nil, errors.New("todo: DELETE with multiple tables")
+			if stmt.TableRefs.TableRefs.Right == nil {
+				return nil, errors.New("todo: DELETE with multiple tables")
+			}
+			if err := checkJoinedTables(stmt.TableRefs); err != nil {
+				return nil, err
+			}
 		}
 		return cc.convDeleteStmt(stmt), nil
 	case *ast.InsertStmt:
 		return cc.convInsertStmt(stmt), nil
 	case *ast.UpdateStmt:
+		if stmt.TableRefs.TableRefs.Right != nil {
+			if err := checkJoinedTables(stmt.TableRefs); err != nil {
+				return nil, err
+			}
+		}
 		return cc.convUpdateStmt(stmt), nil
 	case *ast.ShowStmt:
 		return cc.convShowStmt(stmt), nil
@@ -121,11 +132,41 @@ func FromStmtNode(node ast.StmtNode) (Statement, error) {
 		return cc.convOptimizeTable(stmt), nil
 	case *ast.KillStmt:
 		return cc.convKill(stmt), nil
+	case *ast.CallStmt:
+		return cc.convCall(stmt), nil
+	case *ast.CreateTableStmt:
+		return cc.convCreateTableStmt(stmt)
+	case *ast.RenameTableStmt:
+		return cc.convRenameTableStmt(stmt), nil
+	case *ast.CreateDatabaseStmt:
+		return cc.convCreateDatabaseStmt(stmt)
+	case *ast.DropDatabaseStmt:
+		return &DropDatabaseStatement{Name: stmt.Name, IfExists: stmt.IfExists}, nil
+	case *ast.AdminStmt:
+		return cc.convAdminStmt(stmt)
 	default:
 		return nil, errors.Errorf("unimplement: stmt type %T!", stmt)
 	}
 }
 
+// checkJoinedTables checks the joined tables of multi-table UPDATE/DELETE, only two tables joined with ON are supported.
+func checkJoinedTables(refs *ast.TableRefsClause) error {
+	join := refs.TableRefs
+	for _, it := range []ast.ResultSetNode{join.Left, join.Right} {
+		source, ok := it.(*ast.TableSource)
+		if !ok {
+			return errors.New("unimplement: join more than two tables in UPDATE/DELETE")
+		}
+		if _, ok = source.Source.(*ast.TableName); !ok {
+			return errors.Errorf("unimplement: join %T in UPDATE/DELETE", source.Source)
+		}
+	}
+	if join.On == nil {
+		return errors.New("unimplement: join without ON condition in UPDATE/DELETE")
+	}
+	return nil
+}
+
 func (cc *convCtx) convDropIndexStmt(stmt *ast.DropIndexStmt) *DropIndexStatement {
 	var tableName TableName
 	if db := stmt.Table.Schema.O; len(db) > 0 {
@@ -356,6 +397,114 @@ func (cc *convCtx) convConstraint(c *ast.Constraint) *Constraint {
 	}
 }
 
+func (cc *convCtx) convCreateTableStmt(stmt *ast.CreateTableStmt) (*CreateTableStatement, error) {
+	ret := &CreateTableStatement{
+		Table:       cc.convTableNameOnly(stmt.Table),
+		IfNotExists: stmt.IfNotExists,
+		HasSelect:   stmt.Select != nil,
+	}
+
+	switch stmt.TemporaryKeyword {
+	case ast.TemporaryLocal:
+		ret.Temporary = true
+	case ast.TemporaryGlobal:
+		return nil, errors.New("unimplement: CREATE GLOBAL TEMPORARY TABLE")
+	}
+
+	if stmt.ReferTable != nil {
+		ret.Like = cc.convTableNameOnly(stmt.ReferTable)
+	}
+
+	// restore the definition after the table name, the table name will be rewritten for each shard
+	var (
+		full, head strings.Builder
+		clone      = *stmt
+	)
+	clone.IfNotExists = false
+	clone.TemporaryKeyword = ast.TemporaryNone
+	if err := clone.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &full)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	rc := format.NewRestoreCtx(format.DefaultRestoreFlags, &head)
+	rc.WriteKeyWord("CREATE TABLE ")
+	if err := stmt.Table.Restore(rc); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ret.definition = strings.TrimPrefix(full.String(), head.String())
+
+	return ret, nil
+}
+
+func (cc *convCtx) convRenameTableStmt(stmt *ast.RenameTableStmt) *RenameTableStatement {
+	ret := &RenameTableStatement{
+		Tables: make([]*TableToTable, 0, len(stmt.TableToTables)),
+	}
+	for _, it := range stmt.TableToTables {
+		ret.Tables = append(ret.Tables, &TableToTable{
+			Old: cc.convTableNameOnly(it.OldTable),
+			New: cc.convTableNameOnly(it.NewTable),
+		})
+	}
+	return ret
+}
+
+func (cc *convCtx) convCreateDatabaseStmt(stmt *ast.CreateDatabaseStmt) (*CreateDatabaseStatement, error) {
+	ret := &CreateDatabaseStatement{
+		Name:        stmt.Name,
+		IfNotExists: stmt.IfNotExists,
+	}
+
+	// restore the options only, the database name will be rewritten for each group
+	var sb strings.Builder
+	rc := format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)
+	for _, option := range stmt.Options {
+		rc.WritePlain(" ")
+		if err := option.Restore(rc); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	ret.options = sb.String()
+
+	return ret, nil
+}
+
+func (cc *convCtx) convAdminStmt(stmt *ast.AdminStmt) (Statement, error) {
+	switch stmt.Tp {
+	case ast.AdminShowDDLJobs:
+		return &ShowDDLJobsStatement{Limit: stmt.JobNumber}, nil
+	case ast.AdminCancelDDLJobs:
+		return &CancelDDLJobsStatement{IDs: stmt.JobIDs}, nil
+	case ast.AdminCheckTable:
+		ret := &CheckTableStatement{
+			Tables: make([]TableName, 0, len(stmt.Tables)),
+		}
+		for _, it := range stmt.Tables {
+			ret.Tables = append(ret.Tables, cc.convTableNameOnly(it))
+		}
+		return ret, nil
+	case ast.AdminCheckIndex:
+		return &CheckIndexStatement{
+			Table: cc.convTableNameOnly(stmt.Tables[0]),
+			Index: stmt.Index,
+		}, nil
+	case ast.AdminRecoverIndex:
+		return &RecoverIndexStatement{
+			Table: cc.convTableNameOnly(stmt.Tables[0]),
+			Index: stmt.Index,
+		}, nil
+	default:
+		return nil, errors.Errorf("unimplement: admin stmt type %d!", stmt.Tp)
+	}
+}
+
+func (cc *convCtx) convTableNameOnly(table *ast.TableName) TableName {
+	var ret TableName
+	if db := table.Schema.O; len(db) > 0 {
+		ret = append(ret, db)
+	}
+	return append(ret, table.Name.O)
+}
+
 func (cc *convCtx) convDropTableStmt(stmt *ast.DropTableStmt) *DropTableStatement {
 	tables := make([]*TableName, len(stmt.Tables))
 	for i, table := range stmt.Tables {
@@ -395,6 +544,10 @@ func (cc *convCtx) convUpdateStmt(stmt *ast.UpdateStmt) *UpdateStatement {
 		ret.enableIgnore()
 	}
 
+	if stmt.TableRefs.TableRefs.Right != nil {
+		ret.Join, _ = cc.convFrom(stmt.TableRefs)[0].Join()
+	}
+
 	var tableName TableName
 	switch left := stmt.TableRefs.TableRefs.Left.(type) {
 	case *ast.TableSource:
@@ -492,7 +645,7 @@ func (cc *convCtx) convSelectStmt(stmt *ast.SelectStmt) *SelectStatement {
 	if stmt.LockInfo != nil {
 		switch stmt.LockInfo.LockType {
 		case ast.SelectLockForUpdate:
-			ret.enableForUpdate()
+			ret.EnableForUpdate()
 		case ast.SelectLockForShare:
 			ret.enableLockInShareMode()
 		}
@@ -517,8 +670,15 @@ func (cc *convCtx) convDeleteStmt(stmt *ast.DeleteStmt) Statement {
 		ret.enableLowPriority()
 	}
 
-	// TODO: Now only support single table delete clause, need to fill flag OrderBy field
-	ret.Table = cc.convFrom(stmt.TableRefs)[0].TableName()
+	if stmt.IsMultiTable {
+		ret.Join, _ = cc.convFrom(stmt.TableRefs)[0].Join()
+		ret.Table = ret.Join.Left.TableName()
+		for _, it := range stmt.Tables.Tables {
+			ret.Tables = append(ret.Tables, cc.convTableNameOnly(it))
+		}
+	} else {
+		ret.Table = cc.convFrom(stmt.TableRefs)[0].TableName()
+	}
 
 	if stmt.Where != nil {
 		ret.Where = toExpressionNode(cc.convExpr(stmt.Where))
@@ -581,6 +741,12 @@ func (cc *convCtx) convInsertStmt(stmt *ast.InsertStmt) Statement {
 	}
 
 	if stmt.IsReplace {
+		if sel, ok := stmt.Select.(*ast.SelectStmt); ok { // REPLACE INTO xxx(...) SELECT ...
+			return &ReplaceSelectStatement{
+				baseInsertStatement: &bi,
+				Select:              cc.convSelectStmt(sel),
+			}
+		}
 		return &ReplaceStatement{
 			baseInsertStatement: &bi,
 			Values:              values,
@@ -1665,3 +1831,15 @@ func (cc *convCtx) convKill(stmt *ast.KillStmt) Statement {
 		ConnectionID: stmt.ConnectionID,
 	}
 }
+
+func (cc *convCtx) convCall(stmt *ast.CallStmt) Statement {
+	args := make([]ExpressionNode, 0, len(stmt.Procedure.Args))
+	for _, it := range stmt.Procedure.Args {
+		args = append(args, toExpressionNode(cc.convExpr(it)))
+	}
+	return &CallStatement{
+		Schema:    stmt.Procedure.Schema.O,
+		Procedure: stmt.Procedure.FnName.O,
+		Args:      args,
+	}
+}
@@ -180,6 +180,14 @@ func TestParse_DeleteStmt(t *testing.T) {
 	for _, it := range []tt{
 		{"delete from student where id = 1 limit 1", "DELETE FROM `student` WHERE `id` = 1 LIMIT 1"},
 		{"delete low_priority quick ignore from student where id = 1", "DELETE LOW_PRIORITY QUICK IGNORE FROM `student` WHERE `id` = 1"},
+		{
+			"delete s from student s join score c on s.uid = c.uid where c.score < 60",
+			"DELETE `s` FROM `student` AS `s` INNER JOIN `score` AS `c` ON `s`.`uid` = `c`.`uid` WHERE `c`.`score` < 60",
+		},
+		{
+			"delete student, score from student left join score on student.uid = score.uid where student.id = ?",
+			"DELETE `student`, `score` FROM `student` LEFT JOIN `score` ON `student`.`uid` = `score`.`uid` WHERE `student`.`id` = ?",
+		},
 	} {
 		t.Run(it.input, func(t *testing.T) {
 			_, stmt, err := Parse(it.input)
@@ -193,6 +201,19 @@ func TestParse_DeleteStmt(t *testing.T) {
 	}
 }
 
+func TestParse_MultiTableUnsupported(t *testing.T) {
+	for _, it := range []string{
+		"update student s, score c set s.level = c.score where s.uid = c.uid",
+		"delete s from student s join score c on s.uid = c.uid join clazz z on z.id = s.clazz_id",
+		"update student s join (select uid, max(score) score from score group by uid) c on s.uid = c.uid set s.level = c.score",
+	} {
+		t.Run(it, func(t *testing.T) {
+			_, _, err := Parse(it)
+			assert.Error(t, err)
+		})
+	}
+}
+
 func TestParse_DescribeStatement(t *testing.T) {
 	type tt struct {
 		input  string
@@ -307,6 +328,10 @@ func TestParse_UpdateStmt(t *testing.T) {
 	for _, it := range []tt{
 		{"update `student` set version=version+1,modified_at=NOW() where id = 1", "UPDATE `student` SET `version` = `version`+1, `modified_at` = NOW() WHERE `id` = 1"},
 		{"update low_priority student set nickname = ? where id = 1 limit 1", "UPDATE LOW_PRIORITY `student` SET `nickname` = ? WHERE `id` = 1 LIMIT 1"},
+		{
+			"update student s join score c on s.uid = c.uid and c.score > ? set s.level = c.score where s.id = ?",
+			"UPDATE `student` AS `s` INNER JOIN `score` AS `c` ON `s`.`uid` = `c`.`uid` AND `c`.`score` > ? SET `s`.`level` = `c`.`score` WHERE `s`.`id` = ?",
+		},
 	} {
 		t.Run(it.input, func(t *testing.T) {
 			_, stmt, err := Parse(it.input)
@@ -447,6 +472,116 @@ func TestParse_AlterTableStmt(t *testing.T) {
 	}
 }
 
+func TestParse_CreateTableStmt(t *testing.T) {
+	type tt struct {
+		input  string
+		expect string
+	}
+
+	for _, it := range []tt{
+		{
+			"create table student (id bigint not null auto_increment primary key, uid bigint not null, name varchar(64) default null comment 'name', key idx_uid (uid)) engine=innodb default charset=utf8mb4",
+			"CREATE TABLE `student` (`id` BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,`uid` BIGINT NOT NULL,`name` VARCHAR(64) DEFAULT NULL COMMENT 'name',INDEX `idx_uid`(`uid`)) ENGINE = innodb DEFAULT CHARACTER SET = UTF8MB4",
+		},
+		{
+			"create table if not exists employees.student (id bigint primary key)",
+			"CREATE TABLE IF NOT EXISTS `employees`.`student` (`id` BIGINT PRIMARY KEY)",
+		},
+		{
+			"create temporary table student_tmp like student",
+			"CREATE TEMPORARY TABLE `student_tmp` LIKE `student`",
+		},
+	} {
+		t.Run(it.input, func(t *testing.T) {
+			_, stmt, err := Parse(it.input)
+			assert.NoError(t, err)
+			assert.IsTypef(t, (*CreateTableStatement)(nil), stmt, "should be create table statement")
+
+			actual, err := RestoreToString(RestoreDefault, stmt.(Restorer))
+			assert.NoError(t, err, "should restore ok")
+			assert.Equal(t, it.expect, actual)
+
+			actual, err = RestoreToString(RestoreDefault, stmt.(*CreateTableStatement).ResetTable("student_0001"))
+			assert.NoError(t, err)
+			assert.Contains(t, actual, "`student_0001`")
+		})
+	}
+}
+
+func TestParse_RenameTableStmt(t *testing.T) {
+	_, stmt, err := Parse("rename table student to pupil, employees.score to employees.grade")
+	assert.NoError(t, err)
+	rename := stmt.(*RenameTableStatement)
+	assert.Len(t, rename.Tables, 2)
+	assert.Equal(t, "student", rename.Tables[0].Old.Suffix())
+	assert.Equal(t, "pupil", rename.Tables[0].New.Suffix())
+	assert.Equal(t, "employees", rename.Tables[1].Old.Prefix())
+
+	actual, err := RestoreToString(RestoreDefault, rename)
+	assert.NoError(t, err)
+	assert.Equal(t, "RENAME TABLE `student` TO `pupil`, `employees`.`score` TO `employees`.`grade`", actual)
+}
+
+func TestParse_DatabaseStmt(t *testing.T) {
+	type tt struct {
+		input  string
+		expect string
+	}
+
+	for _, it := range []tt{
+		{"create database employees", "CREATE DATABASE `employees_0000`"},
+		{"create database if not exists employees default charset utf8mb4", "CREATE DATABASE IF NOT EXISTS `employees_0000` CHARACTER SET = utf8mb4"},
+		{"drop database employees", "DROP DATABASE `employees_0000`"},
+		{"drop database if exists employees", "DROP DATABASE IF EXISTS `employees_0000`"},
+	} {
+		t.Run(it.input, func(t *testing.T) {
+			_, stmt, err := Parse(it.input)
+			assert.NoError(t, err)
+
+			var reset Restorer
+			switch x := stmt.(type) {
+			case *CreateDatabaseStatement:
+				assert.Equal(t, "employees", x.Name)
+				reset = x.ResetName("employees_0000")
+			case *DropDatabaseStatement:
+				assert.Equal(t, "employees", x.Name)
+				reset = x.ResetName("employees_0000")
+			default:
+				t.Fatalf("unexpected statement %T", stmt)
+			}
+
+			actual, err := RestoreToString(RestoreDefault, reset)
+			assert.NoError(t, err)
+			assert.Equal(t, it.expect, actual)
+		})
+	}
+}
+
+func TestParse_AdminDDLJobsStmt(t *testing.T) {
+	for _, it := range []struct {
+		input  string
+		expect string
+	}{
+		{"admin show ddl jobs", "ADMIN SHOW DDL JOBS"},
+		{"admin show ddl jobs 5", "ADMIN SHOW DDL JOBS 5"},
+		{"admin cancel ddl jobs 1, 2", "ADMIN CANCEL DDL JOBS 1, 2"},
+		{"admin check table student, score", "ADMIN CHECK TABLE `student`, `score`"},
+		{"admin check index orders idx_order_no", "ADMIN CHECK INDEX `orders` `idx_order_no`"},
+		{"admin recover index orders idx_order_no", "ADMIN RECOVER INDEX `orders` `idx_order_no`"},
+	} {
+		t.Run(it.input, func(t *testing.T) {
+			_, stmt, err := Parse(it.input)
+			assert.NoError(t, err)
+			actual, err := RestoreToString(RestoreDefault, stmt.(Restorer))
+			assert.NoError(t, err)
+			assert.Equal(t, it.expect, actual)
+		})
+	}
+
+	_, _, err := Parse("admin checksum table student")
+	assert.Error(t, err)
+}
+
 func TestParse_DescStmt(t *testing.T) {
 	_, stmt := MustParse("desc student id")
 	// In MySQL, the case of "desc student 'id'" will be parsed successfully,
@@ -487,3 +622,69 @@ func TestRestore(t *testing.T) {
 		})
 	}
 }
+
+func TestParse_CallStmt(t *testing.T) {
+	type tt struct {
+		input  string
+		output string
+		params int
+	}
+
+	for _, next := range []tt{
+		{"call foo", "CALL `foo`()", 0},
+		{"call employees.foo(1, 'bar', @out)", "CALL `employees`.`foo`(1, 'bar', @`out`)", 0},
+		{"call foo(?, ?)", "CALL `foo`(?, ?)", 2},
+	} {
+		t.Run(next.input, func(t *testing.T) {
+			_, stmt, err := Parse(next.input)
+			assert.NoError(t, err)
+			assert.IsType(t, (*CallStatement)(nil), stmt)
+			assert.Equal(t, next.params, stmt.CntParams())
+
+			var sb strings.Builder
+			err = stmt.Restore(RestoreDefault, &sb, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, next.output, sb.String())
+		})
+	}
+}
+
+func TestParse_ReplaceStmt(t *testing.T) {
+	type tt struct {
+		input  string
+		expect string
+	}
+
+	for _, it := range []tt{
+		{"replace into student values (?,?)", "REPLACE INTO `student` VALUES (?, ?)"},
+		{"replace low_priority into student set id=1,name='foo'", "REPLACE LOW_PRIORITY INTO `student` SET `id` = 1, `name` = 'foo'"},
+		{"replace into student(id,name) values(1,'foo'),(2,'bar')", "REPLACE INTO `student`(`id`, `name`) VALUES (1, 'foo'),(2, 'bar')"},
+	} {
+		t.Run(it.input, func(t *testing.T) {
+			_, stmt, err := Parse(it.input)
+			assert.NoError(t, err)
+			assert.IsType(t, (*ReplaceStatement)(nil), stmt)
+			assert.Equal(t, SQLTypeReplace, stmt.Mode())
+
+			actual, err := RestoreToString(RestoreDefault, stmt.(Restorer))
+			assert.NoError(t, err)
+			assert.Equal(t, it.expect, actual)
+		})
+	}
+
+	for _, it := range []tt{
+		{"replace into student select * from student_tmp", "REPLACE INTO `student` SELECT * FROM `student_tmp`"},
+		{"replace into student(id,name) select emp_no, name from employees where emp_no > ?", "REPLACE INTO `student`(`id`, `name`) SELECT `emp_no`,`name` FROM `employees` WHERE `emp_no` > ?"},
+	} {
+		t.Run(it.input, func(t *testing.T) {
+			_, stmt, err := Parse(it.input)
+			assert.NoError(t, err)
+			assert.IsType(t, (*ReplaceSelectStatement)(nil), stmt)
+			assert.Equal(t, SQLTypeReplace, stmt.Mode())
+
+			actual, err := RestoreToString(RestoreDefault, stmt.(Restorer))
+			assert.NoError(t, err)
+			assert.Equal(t, it.expect, actual)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package ast
+
+import (
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+var _ Statement = (*CallStatement)(nil)
+
+// CallStatement represents the CALL statement of stored procedure.
+type CallStatement struct {
+	Schema    string
+	Procedure string
+	Args      []ExpressionNode
+}
+
+func (c *CallStatement) CntParams() int {
+	var n int
+	for _, it := range c.Args {
+		n += it.CntParams()
+	}
+	return n
+}
+
+func (c *CallStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
+	sb.WriteString("CALL ")
+	if len(c.Schema) > 0 {
+		WriteID(sb, c.Schema)
+		sb.WriteByte('.')
+	}
+	WriteID(sb, c.Procedure)
+	sb.WriteByte('(')
+	for i, it := range c.Args {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if err := it.Restore(flag, sb, args); err != nil {
+			return errors.Wrapf(err, "an error occurred while restore CallStatement.Args[%d]", i)
+		}
+	}
+	sb.WriteByte(')')
+	return nil
+}
+
+func (c *CallStatement) Mode() SQLType {
+	return SQLTypeCall
+}
@@ -0,0 +1,70 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"strings"
+)
+
+var (
+	_ Statement = (*CreateTableStatement)(nil)
+	_ Restorer  = (*CreateTableStatement)(nil)
+)
+
+// CreateTableStatement represents mysql create table statement. see https://dev.mysql.com/doc/refman/8.0/en/create-table.html
+type CreateTableStatement struct {
+	Table       TableName
+	IfNotExists bool
+	Temporary   bool
+	Like        TableName // CREATE TABLE ... LIKE ...
+	HasSelect   bool      // CREATE TABLE ... SELECT ...
+	// definition is the restored part after the table name, including columns, constraints and options.
+	definition string
+}
+
+// ResetTable returns a copy of the statement with the new table name.
+func (c *CreateTableStatement) ResetTable(table string) *CreateTableStatement {
+	ret := new(CreateTableStatement)
+	*ret = *c
+	ret.Table = TableName{table}
+	return ret
+}
+
+func (c *CreateTableStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
+	sb.WriteString("CREATE ")
+	if c.Temporary {
+		sb.WriteString("TEMPORARY ")
+	}
+	sb.WriteString("TABLE ")
+	if c.IfNotExists {
+		sb.WriteString("IF NOT EXISTS ")
+	}
+	if err := c.Table.Restore(flag, sb, args); err != nil {
+		return err
+	}
+	sb.WriteString(c.definition)
+	return nil
+}
+
+func (c *CreateTableStatement) CntParams() int {
+	return 0
+}
+
+func (c *CreateTableStatement) Mode() SQLType {
+	return SQLTypeCreateTable
+}
@@ -0,0 +1,94 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"strings"
+)
+
+var (
+	_ Statement = (*CreateDatabaseStatement)(nil)
+	_ Restorer  = (*CreateDatabaseStatement)(nil)
+	_ Statement = (*DropDatabaseStatement)(nil)
+	_ Restorer  = (*DropDatabaseStatement)(nil)
+)
+
+// CreateDatabaseStatement represents mysql create database statement. see https://dev.mysql.com/doc/refman/8.0/en/create-database.html
+type CreateDatabaseStatement struct {
+	Name        string
+	IfNotExists bool
+	// options is the restored part after the database name, such as charset and collate.
+	options string
+}
+
+// ResetName returns a copy of the statement with the new database name.
+func (c *CreateDatabaseStatement) ResetName(name string) *CreateDatabaseStatement {
+	ret := new(CreateDatabaseStatement)
+	*ret = *c
+	ret.Name = name
+	return ret
+}
+
+func (c *CreateDatabaseStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
+	sb.WriteString("CREATE DATABASE ")
+	if c.IfNotExists {
+		sb.WriteString("IF NOT EXISTS ")
+	}
+	WriteID(sb, c.Name)
+	sb.WriteString(c.options)
+	return nil
+}
+
+func (c *CreateDatabaseStatement) CntParams() int {
+	return 0
+}
+
+func (c *CreateDatabaseStatement) Mode() SQLType {
+	return SQLTypeCreateDatabase
+}
+
+// DropDatabaseStatement represents mysql drop database statement. see https://dev.mysql.com/doc/refman/8.0/en/drop-database.html
+type DropDatabaseStatement struct {
+	Name     string
+	IfExists bool
+}
+
+// ResetName returns a copy of the statement with the new database name.
+func (d *DropDatabaseStatement) ResetName(name string) *DropDatabaseStatement {
+	return &DropDatabaseStatement{
+		Name:     name,
+		IfExists: d.IfExists,
+	}
+}
+
+func (d *DropDatabaseStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
+	sb.WriteString("DROP DATABASE ")
+	if d.IfExists {
+		sb.WriteString("IF EXISTS ")
+	}
+	WriteID(sb, d.Name)
+	return nil
+}
+
+func (d *DropDatabaseStatement) CntParams() int {
+	return 0
+}
+
+func (d *DropDatabaseStatement) Mode() SQLType {
+	return SQLTypeDropDatabase
+}
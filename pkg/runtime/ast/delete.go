@@ -37,6 +37,8 @@ const (
 type DeleteStatement struct {
 	flag    uint8
 	Table   TableName
+	Tables  []TableName // the tables to delete from of multi-table delete
+	Join    *JoinNode   // the joined tables of multi-table delete, Table is the left one
 	Where   ExpressionNode
 	OrderBy OrderByNode
 	Limit   *LimitNode
@@ -58,9 +60,25 @@ func (ds *DeleteStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *
 		sb.WriteString("IGNORE ")
 	}
 
+	if ds.IsMultiTable() {
+		for i, it := range ds.Tables {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			if err := it.Restore(flag, sb, args); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		sb.WriteByte(' ')
+	}
+
 	sb.WriteString("FROM ")
 
-	if err := ds.Table.Restore(flag, sb, args); err != nil {
+	if ds.Join != nil {
+		if err := ds.Join.Restore(flag, sb, args); err != nil {
+			return errors.WithStack(err)
+		}
+	} else if err := ds.Table.Restore(flag, sb, args); err != nil {
 		return errors.WithStack(err)
 	}
 	// TODO: partitions
@@ -91,6 +109,9 @@ func (ds *DeleteStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *
 
 func (ds *DeleteStatement) CntParams() int {
 	var n int
+	if ds.Join != nil {
+		n += ds.Join.CntParams()
+	}
 	if ds.Where != nil {
 		n += ds.Where.CntParams()
 	}
@@ -104,6 +125,11 @@ func (ds *DeleteStatement) Mode() SQLType {
 	return SQLTypeDelete
 }
 
+// IsMultiTable returns true if the statement deletes from joined tables.
+func (ds *DeleteStatement) IsMultiTable() bool {
+	return len(ds.Tables) > 0
+}
+
 func (ds *DeleteStatement) IsLowPriority() bool {
 	return ds.flag&_deleteLowPriority != 0
 }
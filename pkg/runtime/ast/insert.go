@@ -109,14 +109,138 @@ func (b *baseInsertStatement) enableSetSyntax() {
 	b.flag |= _flagInsertSetSyntax
 }
 
+// restoreHead writes the head of statement, eg: 'INSERT LOW_PRIORITY IGNORE INTO `t`'.
+func (b *baseInsertStatement) restoreHead(verb string, flag RestoreFlag, sb *strings.Builder, args *[]int) error {
+	sb.WriteString(verb)
+	sb.WriteByte(' ')
+
+	// write priority
+	if b.IsLowPriority() {
+		sb.WriteString("LOW_PRIORITY ")
+	} else if b.IsHighPriority() {
+		sb.WriteString("HIGH_PRIORITY ")
+	} else if b.IsDelayed() {
+		sb.WriteString("DELAYED ")
+	}
+
+	if b.IsIgnore() {
+		sb.WriteString("IGNORE ")
+	}
+
+	sb.WriteString("INTO ")
+
+	if err := b.Table.Restore(flag, sb, args); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// restoreColumns writes the columns, eg: '(`a`, `b`) '.
+func (b *baseInsertStatement) restoreColumns(sb *strings.Builder) {
+	if len(b.Columns) > 0 {
+		sb.WriteByte('(')
+		WriteID(sb, b.Columns[0])
+		for i := 1; i < len(b.Columns); i++ {
+			sb.WriteString(", ")
+			WriteID(sb, b.Columns[i])
+		}
+		sb.WriteString(") ")
+	} else {
+		sb.WriteByte(' ')
+	}
+}
+
+// restoreValues writes the columns and values, eg: '(`a`, `b`) VALUES (1, 2),(3, 4)' or ' SET `a` = 1, `b` = 2'.
+func (b *baseInsertStatement) restoreValues(flag RestoreFlag, sb *strings.Builder, args *[]int, values [][]ExpressionNode) error {
+	if b.IsSetSyntax() {
+		sb.WriteString(" SET ")
+		_ = b.Columns[0]
+		_ = values[0]
+
+		if len(b.Columns) != len(values[0]) {
+			return errors.Errorf("length of column and value doesn't match: %d<>%d", len(b.Columns), len(values[0]))
+		}
+
+		WriteID(sb, b.Columns[0])
+		sb.WriteString(" = ")
+		if err := values[0][0].Restore(flag, sb, args); err != nil {
+			return errors.WithStack(err)
+		}
+
+		for i := 1; i < len(b.Columns); i++ {
+			sb.WriteString(", ")
+			WriteID(sb, b.Columns[i])
+			sb.WriteString(" = ")
+			if err := values[0][i].Restore(flag, sb, args); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+
+	b.restoreColumns(sb)
+
+	sb.WriteString("VALUES ")
+
+	writeOne := func(flag RestoreFlag, sb *strings.Builder, args *[]int, values []ExpressionNode) error {
+		sb.WriteByte('(')
+
+		if len(values) > 0 {
+			if err := values[0].Restore(flag, sb, args); err != nil {
+				return errors.WithStack(err)
+			}
+			for i := 1; i < len(values); i++ {
+				sb.WriteString(", ")
+				if err := values[i].Restore(flag, sb, args); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+
+		}
+
+		sb.WriteByte(')')
+
+		return nil
+	}
+
+	if err := writeOne(flag, sb, args, values[0]); err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i := 1; i < len(values); i++ {
+		sb.WriteByte(',')
+		if err := writeOne(flag, sb, args, values[i]); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+// ReplaceStatement represents mysql replace statement. see https://dev.mysql.com/doc/refman/8.0/en/replace.html
 type ReplaceStatement struct {
 	*baseInsertStatement
 	Values [][]ExpressionNode
 }
 
+func NewReplaceStatement(table TableName, columns []string) *ReplaceStatement {
+	return &ReplaceStatement{
+		baseInsertStatement: &baseInsertStatement{
+			Table:   table,
+			Columns: columns,
+		},
+	}
+}
+
 func (r *ReplaceStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
-	// TODO implement me
-	panic("implement me")
+	if err := r.restoreHead("REPLACE", flag, sb, args); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := r.restoreValues(flag, sb, args, r.Values); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
 }
 
 func (r *ReplaceStatement) Mode() SQLType {
@@ -152,96 +276,12 @@ func NewInsertStatement(table TableName, columns []string) *InsertStatement {
 }
 
 func (is *InsertStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
-	sb.WriteString("INSERT ")
-
-	// write priority
-	if is.IsLowPriority() {
-		sb.WriteString("LOW_PRIORITY ")
-	} else if is.IsHighPriority() {
-		sb.WriteString("HIGH_PRIORITY ")
-	} else if is.IsDelayed() {
-		sb.WriteString("DELAYED ")
-	}
-
-	if is.IsIgnore() {
-		sb.WriteString("IGNORE ")
-	}
-
-	sb.WriteString("INTO ")
-
-	if err := is.Table.Restore(flag, sb, args); err != nil {
+	if err := is.restoreHead("INSERT", flag, sb, args); err != nil {
 		return errors.WithStack(err)
 	}
 
-	if is.IsSetSyntax() {
-		sb.WriteString(" SET ")
-		_ = is.Columns[0]
-		_ = is.Values[0]
-
-		if len(is.Columns) != len(is.Values[0]) {
-			return errors.Errorf("length of column and value doesn't match: %d<>%d", len(is.Columns), len(is.Values[0]))
-		}
-
-		WriteID(sb, is.Columns[0])
-		sb.WriteString(" = ")
-		if err := is.Values[0][0].Restore(flag, sb, args); err != nil {
-			return errors.WithStack(err)
-		}
-
-		for i := 1; i < len(is.Columns); i++ {
-			sb.WriteString(", ")
-			WriteID(sb, is.Columns[i])
-			sb.WriteString(" = ")
-			if err := is.Values[0][i].Restore(flag, sb, args); err != nil {
-				return errors.WithStack(err)
-			}
-		}
-	} else if len(is.Columns) > 0 {
-		sb.WriteByte('(')
-		WriteID(sb, is.Columns[0])
-		for i := 1; i < len(is.Columns); i++ {
-			sb.WriteString(", ")
-			WriteID(sb, is.Columns[i])
-		}
-		sb.WriteString(") ")
-	} else {
-		sb.WriteByte(' ')
-	}
-
-	if !is.IsSetSyntax() {
-		sb.WriteString("VALUES ")
-
-		writeOne := func(flag RestoreFlag, sb *strings.Builder, args *[]int, values []ExpressionNode) error {
-			sb.WriteByte('(')
-
-			if len(values) > 0 {
-				if err := values[0].Restore(flag, sb, args); err != nil {
-					return errors.WithStack(err)
-				}
-				for i := 1; i < len(values); i++ {
-					sb.WriteString(", ")
-					if err := values[i].Restore(flag, sb, args); err != nil {
-						return errors.WithStack(err)
-					}
-				}
-
-			}
-
-			sb.WriteByte(')')
-
-			return nil
-		}
-
-		if err := writeOne(flag, sb, args, is.Values[0]); err != nil {
-			return errors.WithStack(err)
-		}
-
-		for i := 1; i < len(is.Values); i++ {
-			sb.WriteByte(',')
-			if err := writeOne(flag, sb, args, is.Values[i]); err != nil {
-				return errors.WithStack(err)
-			}
-		}
+	if err := is.restoreValues(flag, sb, args, is.Values); err != nil {
+		return errors.WithStack(err)
 	}
 
 	if len(is.DuplicatedUpdates) > 0 {
@@ -288,8 +328,17 @@ type ReplaceSelectStatement struct {
 }
 
 func (r *ReplaceSelectStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
-	// TODO implement me
-	panic("implement me")
+	if err := r.restoreHead("REPLACE", flag, sb, args); err != nil {
+		return errors.WithStack(err)
+	}
+
+	r.restoreColumns(sb)
+
+	if err := r.Select.Restore(flag, sb, args); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
 }
 
 func (r *ReplaceSelectStatement) CntParams() int {
@@ -308,38 +357,11 @@ type InsertSelectStatement struct {
 }
 
 func (is *InsertSelectStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
-	sb.WriteString("INSERT ")
-
-	// write priority
-	if is.IsLowPriority() {
-		sb.WriteString("LOW_PRIORITY ")
-	} else if is.IsHighPriority() {
-		sb.WriteString("HIGH_PRIORITY ")
-	} else if is.IsDelayed() {
-		sb.WriteString("DELAYED ")
-	}
-
-	if is.IsIgnore() {
-		sb.WriteString("IGNORE ")
-	}
-
-	sb.WriteString("INTO ")
-
-	if err := is.Table.Restore(flag, sb, args); err != nil {
+	if err := is.restoreHead("INSERT", flag, sb, args); err != nil {
 		return errors.WithStack(err)
 	}
 
-	if len(is.Columns) > 0 {
-		sb.WriteByte('(')
-		WriteID(sb, is.Columns[0])
-		for i := 1; i < len(is.Columns); i++ {
-			sb.WriteString(", ")
-			WriteID(sb, is.Columns[i])
-		}
-		sb.WriteString(") ")
-	} else {
-		sb.WriteByte(' ')
-	}
+	is.restoreColumns(sb)
 
 	if is.sel != nil {
 		if err := is.sel.Restore(flag, sb, args); err != nil {
@@ -374,6 +396,14 @@ func (is *InsertSelectStatement) Select() *SelectStatement {
 	return is.sel
 }
 
+func (is *InsertSelectStatement) UnionSelect() *UnionSelectStatement {
+	return is.unionSel
+}
+
+func (is *InsertSelectStatement) DuplicatedUpdates() []*UpdateElement {
+	return is.duplicatedUpdates
+}
+
 func (is *InsertSelectStatement) CntParams() int {
 	if is.unionSel != nil {
 		return is.unionSel.CntParams()
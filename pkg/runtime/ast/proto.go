@@ -58,6 +58,16 @@ const (
 	SQLTypeShowProcessList           // SHOW PROCESSLIST
 	SQLTypeShowReplicaStatus         // SHOW REPLICA STATUS
 	SQLTypeKill                      // KILL
+	SQLTypeCall                      // CALL
+	SQLTypeCreateTable               // CREATE TABLE
+	SQLTypeRenameTable               // RENAME TABLE
+	SQLTypeCreateDatabase            // CREATE DATABASE
+	SQLTypeDropDatabase              // DROP DATABASE
+	SQLTypeShowDDLJobs               // ADMIN SHOW DDL JOBS
+	SQLTypeCancelDDLJobs             // ADMIN CANCEL DDL JOBS
+	SQLTypeCheckTable                // ADMIN CHECK TABLE
+	SQLTypeCheckIndex                // ADMIN CHECK INDEX
+	SQLTypeRecoverIndex              // ADMIN RECOVER INDEX
 )
 
 var _sqlTypeNames = [...]string{
@@ -91,6 +101,16 @@ var _sqlTypeNames = [...]string{
 	SQLTypeShowProcessList:   "SHOW PROCESSLIST",
 	SQLTypeShowReplicaStatus: "SHOW REPLICA STATUS",
 	SQLTypeKill:              "KILL",
+	SQLTypeCall:              "CALL",
+	SQLTypeCreateTable:       "CREATE TABLE",
+	SQLTypeRenameTable:       "RENAME TABLE",
+	SQLTypeCreateDatabase:    "CREATE DATABASE",
+	SQLTypeDropDatabase:      "DROP DATABASE",
+	SQLTypeShowDDLJobs:       "ADMIN SHOW DDL JOBS",
+	SQLTypeCancelDDLJobs:     "ADMIN CANCEL DDL JOBS",
+	SQLTypeCheckTable:        "ADMIN CHECK TABLE",
+	SQLTypeCheckIndex:        "ADMIN CHECK INDEX",
+	SQLTypeRecoverIndex:      "ADMIN RECOVER INDEX",
 }
 
 // SQLType represents the type of SQL.
@@ -249,7 +249,8 @@ func (ss *SelectStatement) enableDistinct() {
 	ss.flag |= _selectDistinct
 }
 
-func (ss *SelectStatement) enableForUpdate() {
+// EnableForUpdate locks the selected rows, eg: SELECT ... FOR UPDATE.
+func (ss *SelectStatement) EnableForUpdate() {
 	ss.flag |= _selectForUpdate
 }
 
@@ -37,6 +37,21 @@ type TableSourceNode struct {
 	IndexHints []*IndexHint
 }
 
+// NewTableSourceNode creates the table source of the table, eg: `t` AS `alias`.
+func NewTableSourceNode(table TableName, alias string) *TableSourceNode {
+	return &TableSourceNode{
+		source: table,
+		Alias:  alias,
+	}
+}
+
+// NewJoinTableSourceNode creates the table source of the joined tables.
+func NewJoinTableSourceNode(join *JoinNode) *TableSourceNode {
+	return &TableSourceNode{
+		source: join,
+	}
+}
+
 func (t *TableSourceNode) Accept(visitor Visitor) (interface{}, error) {
 	return visitor.VisitTableSource(t)
 }
@@ -53,6 +68,16 @@ func (t *TableSourceNode) ResetTableName(newTableName string) bool {
 	return false
 }
 
+// ResetJoin resets the join node, returns false if the source is not a join node.
+func (t *TableSourceNode) ResetJoin(join *JoinNode) bool {
+	switch t.source.(type) {
+	case *JoinNode:
+		t.source = join
+		return true
+	}
+	return false
+}
+
 func (t *TableSourceNode) Restore(flag RestoreFlag, sb *strings.Builder, args *[]int) error {
 	switch source := t.source.(type) {
 	case TableName:
@@ -36,6 +36,7 @@ type UpdateStatement struct {
 	flag       uint8
 	Table      TableName
 	TableAlias string
+	Join       *JoinNode // the joined tables of multi-table update, Table is the left one
 	Updated    []*UpdateElement
 	Where      ExpressionNode
 	OrderBy    OrderByNode
@@ -62,8 +63,18 @@ func (u *UpdateStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[
 	if u.IsIgnore() {
 		sb.WriteString("IGNORE ")
 	}
-	if err := u.Table.Restore(flag, sb, args); err != nil {
-		return err
+	if u.Join != nil {
+		if err := u.Join.Restore(flag, sb, args); err != nil {
+			return err
+		}
+	} else {
+		if err := u.Table.Restore(flag, sb, args); err != nil {
+			return err
+		}
+		if len(u.TableAlias) > 0 {
+			sb.WriteString(" AS ")
+			WriteID(sb, u.TableAlias)
+		}
 	}
 	sb.WriteString(" SET ")
 
@@ -103,6 +114,11 @@ func (u *UpdateStatement) Restore(flag RestoreFlag, sb *strings.Builder, args *[
 	return nil
 }
 
+// IsMultiTable returns true if the statement updates joined tables.
+func (u *UpdateStatement) IsMultiTable() bool {
+	return u.Join != nil
+}
+
 func (u *UpdateStatement) IsEnableLowPriority() bool {
 	return u.flag&_flagUpdateLowPriority != 0
 }
@@ -121,6 +137,9 @@ func (u *UpdateStatement) enableIgnore() {
 
 func (u *UpdateStatement) CntParams() int {
 	var n int
+	if u.Join != nil {
+		n += u.Join.CntParams()
+	}
 	for _, it := range u.Updated {
 		n += it.CntParams()
 	}
@@ -37,6 +37,8 @@ type (
 	keyNodeLabel      struct{}
 	keyDefaultDBGroup struct{}
 	keyHints          struct{}
+	keyLastInsertID   struct{}
+	keyTxGroups       struct{}
 )
 
 type cFlag uint8
@@ -67,6 +69,16 @@ func WithHints(ctx context.Context, hints []*hint.Hint) context.Context {
 	return context.WithValue(ctx, keyHints{}, hints)
 }
 
+// WithLastInsertID binds the last insert id of current connection, it will be used by LAST_INSERT_ID().
+func WithLastInsertID(ctx context.Context, id uint64) context.Context {
+	return context.WithValue(ctx, keyLastInsertID{}, id)
+}
+
+// WithTxGroups binds the groups which the current transaction has already began on.
+func WithTxGroups(ctx context.Context, groups []string) context.Context {
+	return context.WithValue(ctx, keyTxGroups{}, groups)
+}
+
 // Tenant extracts the tenant.
 func Tenant(ctx context.Context) string {
 	tenant, ok := ctx.Value(proto.ContextKeyTenant{}).(string)
@@ -130,6 +142,18 @@ func Hints(ctx context.Context) []*hint.Hint {
 	return hints
 }
 
+// LastInsertID returns the last insert id of current connection.
+func LastInsertID(ctx context.Context) uint64 {
+	id, _ := ctx.Value(keyLastInsertID{}).(uint64)
+	return id
+}
+
+// TxGroups returns the groups which the current transaction has already began on, the result is sorted.
+func TxGroups(ctx context.Context) []string {
+	groups, _ := ctx.Value(keyTxGroups{}).([]string)
+	return groups
+}
+
 func TransientVariables(ctx context.Context) map[string]proto.Value {
 	if val, ok := ctx.Value(proto.ContextKeyTransientVariables{}).(map[string]proto.Value); ok {
 		return val
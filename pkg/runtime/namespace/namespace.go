@@ -51,6 +51,17 @@ func Load(namespace string) *Namespace {
 	return exist.(*Namespace)
 }
 
+// List lists the names of all namespaces.
+func List() []string {
+	var names []string
+	_namespaces.Range(func(key, _ interface{}) bool {
+		names = append(names, key.(string))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
 // Register registers a namespace.
 func Register(namespace *Namespace) error {
 	name := namespace.Name()
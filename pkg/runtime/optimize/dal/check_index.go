@@ -0,0 +1,84 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dal
+
+import (
+	"context"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan/dal"
+	"github.com/arana-db/arana/pkg/runtime/plan/dml"
+)
+
+func init() {
+	optimize.Register(ast.SQLTypeCheckIndex, optimizeCheckIndex)
+	optimize.Register(ast.SQLTypeRecoverIndex, optimizeRecoverIndex)
+}
+
+func optimizeCheckIndex(_ context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	stmt := o.Stmt.(*ast.CheckIndexStatement)
+	return newCheckIndexPlan(o, stmt.Table, stmt.Index, false)
+}
+
+func optimizeRecoverIndex(_ context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	stmt := o.Stmt.(*ast.RecoverIndexStatement)
+	return newCheckIndexPlan(o, stmt.Table, stmt.Index, true)
+}
+
+func newCheckIndexPlan(o *optimize.Optimizer, table ast.TableName, name string, recover bool) (proto.Plan, error) {
+	vt, ok := o.Rule.VTable(table.Suffix())
+	if !ok {
+		return nil, errors.Errorf("cannot check global index of non-sharding table '%s'", table.Suffix())
+	}
+	index, ok := vt.GlobalIndex(name)
+	if !ok {
+		return nil, errors.Errorf("no global index '%s' found in table '%s'", name, table.Suffix())
+	}
+	indexTable, ok := o.Rule.VTable(index.Table)
+	if !ok {
+		return nil, errors.Errorf("no sharding table '%s' found for global index '%s'", index.Table, index.Name)
+	}
+	route, err := optimize.RouteGlobalIndex(o.Rule, index)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &dal.CheckIndexPlan{
+		Table:       table.Suffix(),
+		Index:       index.Name,
+		Recover:     recover,
+		Shards:      vt.Topology().Enumerate(),
+		IndexShards: indexTable.Topology().Enumerate(),
+		IndexTable: &dml.GlobalIndexTable{
+			Column: index.Column,
+			Keys:   optimize.ShardKeys(vt),
+			Route:  route,
+		},
+	}
+	ret.BindArgs(o.Args)
+
+	return ret, nil
+}
@@ -41,8 +41,11 @@ func optimizeShowColumns(_ context.Context, o *optimize.Optimizer) (proto.Plan,
 	ret.BindArgs(o.Args)
 
 	if vTable, ok := vts[vtName]; ok {
-		_, tblName, _ := vTable.Topology().Smallest()
+		dbName, tblName, _ := vTable.Topology().Smallest()
+		ret.Database = dbName
 		ret.Table = tblName
+	} else if group, ok := o.Rule.SingleTable(vtName); ok {
+		ret.Database = group
 	}
 
 	return ret, nil
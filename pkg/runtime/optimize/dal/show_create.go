@@ -59,6 +59,7 @@ func optimizeShowCreate(_ context.Context, o *optimize.Optimizer) (proto.Plan, e
 			return nil, errors.Errorf("failed to render table:%s ", table)
 		}
 	} else {
+		ret.Database, _ = o.Rule.SingleTable(table)
 		ret.Table = table
 	}
 
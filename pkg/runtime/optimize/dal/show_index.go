@@ -41,6 +41,9 @@ func optimizeShowIndex(_ context.Context, o *optimize.Optimizer) (proto.Plan, er
 
 	vt, ok := o.Rule.VTable(stmt.TableName.Suffix())
 	if !ok {
+		if group, ok := o.Rule.SingleTable(stmt.TableName.Suffix()); ok {
+			ret.Shards = rule.DatabaseTables{group: []string{stmt.TableName.Suffix()}}
+		}
 		return ret, nil
 	}
 
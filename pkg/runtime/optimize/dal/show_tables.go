@@ -51,5 +51,6 @@ func optimizeShowTables(_ context.Context, o *optimize.Optimizer) (proto.Plan, e
 	ret := dal.NewShowTablesPlan(stmt)
 	ret.BindArgs(o.Args)
 	ret.SetInvertedShards(invertedIndex)
+	ret.SetSingleTables(o.Rule.SingleTables())
 	return ret, nil
 }
@@ -23,6 +23,7 @@ import (
 
 import (
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/hint"
 	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/runtime/ast"
 	"github.com/arana-db/arana/pkg/runtime/optimize"
@@ -45,6 +46,9 @@ func optimizeAlterTable(_ context.Context, o *optimize.Optimizer) (proto.Plan, e
 
 	// non-sharding update
 	if vt, ok = o.Rule.VTable(table.Suffix()); !ok {
+		if group, ok := o.Rule.SingleTable(table.Suffix()); ok {
+			ret.Shards = rule.DatabaseTables{group: []string{table.Suffix()}}
+		}
 		return ret, nil
 	}
 
@@ -57,5 +61,6 @@ func optimizeAlterTable(_ context.Context, o *optimize.Optimizer) (proto.Plan, e
 
 	// sharding
 	ret.Shards = vt.Topology().Enumerate()
+	ret.Async = hint.Contains(hint.TypeAsync, o.Hints)
 	return ret, nil
 }
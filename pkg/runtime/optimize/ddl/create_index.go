@@ -23,6 +23,7 @@ import (
 
 import (
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/runtime/ast"
 	"github.com/arana-db/arana/pkg/runtime/optimize"
 	"github.com/arana-db/arana/pkg/runtime/plan/ddl"
@@ -39,6 +40,9 @@ func optimizeCreateIndex(_ context.Context, o *optimize.Optimizer) (proto.Plan,
 
 	// table shard
 	if !ok {
+		if group, ok := o.Rule.SingleTable(stmt.Table.Suffix()); ok {
+			ret.SetShard(rule.DatabaseTables{group: []string{stmt.Table.Suffix()}})
+		}
 		return ret, nil
 	}
 
@@ -0,0 +1,68 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddl
+
+import (
+	"context"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan/ddl"
+)
+
+func init() {
+	optimize.Register(ast.SQLTypeCreateTable, optimizeCreateTable)
+}
+
+func optimizeCreateTable(_ context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	var (
+		stmt = o.Stmt.(*ast.CreateTableStatement)
+		ret  = ddl.NewCreateTablePlan(stmt)
+	)
+	ret.BindArgs(o.Args)
+
+	// non-sharding create table
+	vt, ok := o.Rule.VTable(stmt.Table.Suffix())
+	if !ok {
+		if group, ok := o.Rule.SingleTable(stmt.Table.Suffix()); ok {
+			ret.Shards = rule.DatabaseTables{group: []string{stmt.Table.Suffix()}}
+		}
+		return ret, nil
+	}
+
+	switch {
+	case stmt.Temporary:
+		return nil, errors.Errorf("do not support create temporary sharding table '%s'", stmt.Table.Suffix())
+	case len(stmt.Like) > 0:
+		return nil, errors.Errorf("do not support create sharding table '%s' with LIKE", stmt.Table.Suffix())
+	case stmt.HasSelect:
+		return nil, errors.Errorf("do not support create sharding table '%s' with SELECT", stmt.Table.Suffix())
+	}
+
+	// create all the physical tables
+	ret.Shards = vt.Topology().Enumerate()
+	return ret, nil
+}
@@ -0,0 +1,143 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddl_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeCreateTable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+
+	var (
+		mu    sync.Mutex
+		execs []string
+	)
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			mu.Lock()
+			execs = append(execs, db+": "+sql)
+			mu.Unlock()
+			// the physical table student_0003 exists already
+			if strings.Contains(sql, "`student_0003`") && !strings.Contains(sql, "IF NOT EXISTS") {
+				return nil, errors.New("Table 'student_0003' already exists")
+			}
+			return resultx.New(), nil
+		}).AnyTimes()
+
+	var (
+		ctx      = context.Background()
+		ru       rule.Rule
+		tab      rule.VTable
+		topology rule.Topology
+	)
+
+	// 2 databases, 2 tables for each database
+	topology.SetRender(func(i int) string {
+		return fmt.Sprintf("fake_db_%04d", i)
+	}, func(i int) string {
+		return fmt.Sprintf("student_%04d", i)
+	})
+	topology.SetTopology(0, 0, 1)
+	topology.SetTopology(1, 2, 3)
+	tab.SetTopology(&topology)
+	ru.SetVTable("student", &tab)
+
+	optimize := func(sql string) (proto.Plan, error) {
+		stmt, _ := parser.New().ParseOneStmt(sql, "", "")
+		opt, err := NewOptimizer(&ru, nil, stmt, nil)
+		assert.NoError(t, err)
+		return opt.Optimize(ctx)
+	}
+
+	t.Run("sharding", func(t *testing.T) {
+		execs = nil
+
+		plan, err := optimize("create table student (id bigint primary key, uid bigint not null)")
+		assert.NoError(t, err)
+
+		_, err = plan.ExecIn(ctx, conn)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create 1 of 4 physical tables")
+		assert.Contains(t, err.Error(), "fake_db_0001.student_0003")
+		assert.Len(t, execs, 4)
+	})
+
+	t.Run("if not exists", func(t *testing.T) {
+		execs = nil
+
+		plan, err := optimize("create table if not exists student (id bigint primary key, uid bigint not null)")
+		assert.NoError(t, err)
+
+		res, err := plan.ExecIn(ctx, conn)
+		assert.NoError(t, err)
+		created, _ := res.RowsAffected()
+		assert.Equal(t, uint64(4), created, "the created physical tables should be responded")
+
+		sort.Strings(execs)
+		assert.Equal(t, []string{
+			"fake_db_0000: CREATE TABLE IF NOT EXISTS `student_0000` (`id` BIGINT PRIMARY KEY,`uid` BIGINT NOT NULL)",
+			"fake_db_0000: CREATE TABLE IF NOT EXISTS `student_0001` (`id` BIGINT PRIMARY KEY,`uid` BIGINT NOT NULL)",
+			"fake_db_0001: CREATE TABLE IF NOT EXISTS `student_0002` (`id` BIGINT PRIMARY KEY,`uid` BIGINT NOT NULL)",
+			"fake_db_0001: CREATE TABLE IF NOT EXISTS `student_0003` (`id` BIGINT PRIMARY KEY,`uid` BIGINT NOT NULL)",
+		}, execs)
+	})
+
+	t.Run("non-sharding", func(t *testing.T) {
+		execs = nil
+
+		plan, err := optimize("create table employees (id bigint primary key)")
+		assert.NoError(t, err)
+
+		_, err = plan.ExecIn(ctx, conn)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{": CREATE TABLE `employees` (`id` BIGINT PRIMARY KEY)"}, execs)
+	})
+
+	t.Run("like", func(t *testing.T) {
+		_, err := optimize("create table student like student_template")
+		assert.Error(t, err)
+	})
+}
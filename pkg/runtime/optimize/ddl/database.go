@@ -0,0 +1,64 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddl
+
+import (
+	"context"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/runtime/namespace"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan/ddl"
+)
+
+func init() {
+	optimize.Register(ast.SQLTypeCreateDatabase, optimizeCreateDatabase)
+	optimize.Register(ast.SQLTypeDropDatabase, optimizeDropDatabase)
+}
+
+func optimizeCreateDatabase(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	stmt := o.Stmt.(*ast.CreateDatabaseStatement)
+	ret := ddl.NewCreateDatabasePlan(stmt)
+	ret.BindArgs(o.Args)
+	ret.Groups = logicalGroups(ctx, stmt.Name)
+	return ret, nil
+}
+
+func optimizeDropDatabase(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	stmt := o.Stmt.(*ast.DropDatabaseStatement)
+	ret := ddl.NewDropDatabasePlan(stmt)
+	ret.BindArgs(o.Args)
+	ret.Groups = logicalGroups(ctx, stmt.Name)
+	return ret, nil
+}
+
+// logicalGroups returns all the groups if the name is the current logical schema, otherwise returns nil.
+func logicalGroups(ctx context.Context, name string) []string {
+	if name != rcontext.Schema(ctx) {
+		return nil
+	}
+	ns := namespace.Load(name)
+	if ns == nil {
+		return nil
+	}
+	return ns.DBGroups()
+}
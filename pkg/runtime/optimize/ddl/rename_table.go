@@ -0,0 +1,262 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddl
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan/ddl"
+)
+
+func init() {
+	optimize.Register(ast.SQLTypeRenameTable, optimizeRenameTable)
+}
+
+func optimizeRenameTable(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	var (
+		stmt = o.Stmt.(*ast.RenameTableStatement)
+		ret  = ddl.NewRenameTablePlan(stmt)
+	)
+	ret.BindArgs(o.Args)
+
+	var sharding, single int
+	for _, it := range stmt.Tables {
+		if _, ok := o.Rule.VTable(it.Old.Suffix()); ok {
+			sharding++
+		} else if _, ok = o.Rule.SingleTable(it.Old.Suffix()); ok {
+			single++
+		}
+	}
+
+	switch {
+	case sharding == 0 && single == 0:
+		// non-sharding rename table
+		return ret, nil
+	case sharding+single < len(stmt.Tables):
+		return nil, errors.New("do not support rename sharding table and non-sharding table together")
+	}
+
+	renames := make(map[string][]*ast.TableToTable)
+	for _, it := range stmt.Tables {
+		var (
+			from = it.Old.Suffix()
+			to   = it.New.Suffix()
+		)
+		if it.Old.Prefix() != it.New.Prefix() {
+			return nil, errors.Errorf("do not support rename table '%s' to another schema", from)
+		}
+		if _, ok := o.Rule.VTable(to); ok {
+			return nil, errors.Errorf("sharding table '%s' exists already", to)
+		}
+		if _, ok := o.Rule.SingleTable(to); ok {
+			return nil, errors.Errorf("single table '%s' exists already", to)
+		}
+
+		// the single table is renamed on the group where it's placed
+		if group, ok := o.Rule.SingleTable(from); ok {
+			renames[group] = append(renames[group], &ast.TableToTable{
+				Old: ast.TableName{from},
+				New: ast.TableName{to},
+			})
+			continue
+		}
+
+		// the physical tables are renamed by replacing the prefix, eg: student_0001 -> pupil_0001
+		vt := o.Rule.MustVTable(from)
+		for db, tables := range vt.Topology().Enumerate() {
+			for _, table := range tables {
+				if !strings.HasPrefix(table, from) {
+					return nil, errors.Errorf("cannot rename physical table '%s' of sharding table '%s'", table, from)
+				}
+				renames[db] = append(renames[db], &ast.TableToTable{
+					Old: ast.TableName{table},
+					New: ast.TableName{to + strings.TrimPrefix(table, from)},
+				})
+			}
+		}
+	}
+	ret.Renames = renames
+
+	tenant, schema := rcontext.Tenant(ctx), rcontext.Schema(ctx)
+	ret.UpdateRule = func(ctx context.Context) error {
+		return renameShardingRule(ctx, tenant, schema, stmt.Tables)
+	}
+	if config.GetStoreOperate() != nil {
+		ret.Journal = &configRenameJournal{
+			tenant: tenant,
+			schema: schema,
+			tables: stmt.Tables,
+		}
+	}
+
+	return ret, nil
+}
+
+// renameShardingRule renames the sharding tables and single tables in the config store, the rule of runtime
+// will be refreshed by the config watcher.
+func renameShardingRule(ctx context.Context, tenant, schema string, pairs []*ast.TableToTable) error {
+	op := config.GetStoreOperate()
+	if op == nil {
+		return errors.New("config store is not initialized")
+	}
+
+	center, err := config.NewCenter(tenant, op, config.WithReader(true), config.WithWriter(true))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		_ = center.Close()
+	}()
+
+	tenantCfg, err := center.Load(ctx, config.ConfigItemShardingRule)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if tenantCfg == nil || tenantCfg.ShardingRule == nil {
+		return errors.Errorf("no sharding rule found for tenant '%s'", tenant)
+	}
+
+	for _, it := range pairs {
+		db := it.Old.Prefix()
+		if len(db) < 1 {
+			db = schema
+		}
+
+		var (
+			from  = it.Old.Suffix()
+			to    = it.New.Suffix()
+			found bool
+		)
+		for _, table := range tenantCfg.ShardingRule.Tables {
+			if table.Name != db+"."+from {
+				continue
+			}
+			table.Name = db + "." + to
+			if table.Topology != nil && strings.HasPrefix(table.Topology.TblPattern, from) {
+				table.Topology.TblPattern = to + strings.TrimPrefix(table.Topology.TblPattern, from)
+			}
+			found = true
+			break
+		}
+		for _, table := range tenantCfg.ShardingRule.SingleTables {
+			if found || table == nil || table.Name != db+"."+from {
+				continue
+			}
+			table.Name = db + "." + to
+			found = true
+		}
+		if !found {
+			return errors.Errorf("no sharding rule found for table '%s.%s'", db, from)
+		}
+	}
+
+	return center.Write(ctx, config.ConfigItemShardingRule, tenantCfg)
+}
+
+// pendingRename is the persisted renaming which is not finished yet.
+type pendingRename struct {
+	// Tables is the renamed logical tables.
+	Tables []*ast.TableToTable `json:"tables"`
+	// Renames is the physical renaming pairs of each database.
+	Renames map[string][]*ast.TableToTable `json:"renames"`
+}
+
+// configRenameJournal persists the pending renaming of schema into the config store.
+type configRenameJournal struct {
+	tenant, schema string
+	tables         []*ast.TableToTable
+}
+
+func (j *configRenameJournal) Save(_ context.Context, renames map[string][]*ast.TableToTable) error {
+	b, err := json.Marshal(&pendingRename{
+		Tables:  j.tables,
+		Renames: renames,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return savePendingRename(j.tenant, j.schema, b)
+}
+
+func (j *configRenameJournal) Clear(_ context.Context) error {
+	return savePendingRename(j.tenant, j.schema, nil)
+}
+
+// RecoverRenames recovers the interrupted renaming of sharding tables or single tables in the schema, the physical tables will be
+// renamed forward if the sharding rule has been updated already, otherwise they will be renamed back.
+func RecoverRenames(ctx context.Context, tenant, schema string, conn proto.VConn, ru *rule.Rule) error {
+	op := config.GetStoreOperate()
+	if op == nil {
+		return nil
+	}
+
+	b, err := op.Get(pendingRenamePath(tenant, schema))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(b) < 1 {
+		return nil
+	}
+
+	var pending pendingRename
+	if err = json.Unmarshal(b, &pending); err != nil {
+		return errors.Wrapf(err, "failed to load pending renaming of schema '%s'", schema)
+	}
+
+	forward := len(pending.Tables) > 0
+	for _, it := range pending.Tables {
+		if _, ok := ru.VTable(it.New.Suffix()); ok {
+			continue
+		}
+		if _, ok := ru.SingleTable(it.New.Suffix()); ok {
+			continue
+		}
+		forward = false
+		break
+	}
+	ddl.RecoverRenames(ctx, conn, pending.Renames, forward)
+
+	return savePendingRename(tenant, schema, nil)
+}
+
+func savePendingRename(tenant, schema string, b []byte) error {
+	op := config.GetStoreOperate()
+	if op == nil {
+		return errors.New("config store is not initialized")
+	}
+	return errors.WithStack(op.Save(pendingRenamePath(tenant, schema), b))
+}
+
+func pendingRenamePath(tenant, schema string) config.PathKey {
+	return config.PathKey(filepath.Join(string(config.NewPathInfo(tenant).DefaultTenantBaseConfigPath), "pendingRenames", schema))
+}
@@ -0,0 +1,136 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddl_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeRenameTable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+
+	var execs []string
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			execs = append(execs, db+": "+sql)
+			return resultx.New(), nil
+		}).AnyTimes()
+
+	var (
+		ctx      = context.Background()
+		ru       rule.Rule
+		tab      rule.VTable
+		topology rule.Topology
+	)
+
+	topology.SetRender(func(i int) string {
+		return fmt.Sprintf("fake_db_%04d", i)
+	}, func(i int) string {
+		return fmt.Sprintf("student_%04d", i)
+	})
+	topology.SetTopology(0, 0, 1)
+	topology.SetTopology(1, 2, 3)
+	tab.SetTopology(&topology)
+	ru.SetVTable("student", &tab)
+	ru.SetVTable("score", &tab)
+	ru.SetSingleTables(map[string]string{"teacher": "fake_db_0001"})
+
+	optimize := func(sql string) (proto.Plan, error) {
+		stmt, _ := parser.New().ParseOneStmt(sql, "", "")
+		opt, err := NewOptimizer(&ru, nil, stmt, nil)
+		assert.NoError(t, err)
+		return opt.Optimize(ctx)
+	}
+
+	t.Run("sharding", func(t *testing.T) {
+		execs = nil
+
+		plan, err := optimize("rename table student to pupil")
+		assert.NoError(t, err)
+
+		// the config store is not available, all the physical tables should be renamed back
+		_, err = plan.ExecIn(ctx, conn)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to update sharding rule")
+		assert.Equal(t, []string{
+			"fake_db_0000: RENAME TABLE `student_0000` TO `pupil_0000`, `student_0001` TO `pupil_0001`",
+			"fake_db_0001: RENAME TABLE `student_0002` TO `pupil_0002`, `student_0003` TO `pupil_0003`",
+			"fake_db_0000: RENAME TABLE `pupil_0000` TO `student_0000`, `pupil_0001` TO `student_0001`",
+			"fake_db_0001: RENAME TABLE `pupil_0002` TO `student_0002`, `pupil_0003` TO `student_0003`",
+		}, execs)
+	})
+
+	t.Run("single", func(t *testing.T) {
+		execs = nil
+
+		plan, err := optimize("rename table teacher to tutor")
+		assert.NoError(t, err)
+
+		// the config store is not available, the single table should be renamed back on its group
+		_, err = plan.ExecIn(ctx, conn)
+		assert.Error(t, err)
+		assert.Equal(t, []string{
+			"fake_db_0001: RENAME TABLE `teacher` TO `tutor`",
+			"fake_db_0001: RENAME TABLE `tutor` TO `teacher`",
+		}, execs)
+	})
+
+	t.Run("non-sharding", func(t *testing.T) {
+		execs = nil
+
+		plan, err := optimize("rename table employees to staff")
+		assert.NoError(t, err)
+
+		_, err = plan.ExecIn(ctx, conn)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{": RENAME TABLE `employees` TO `staff`"}, execs)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := optimize("rename table student to pupil, employees to staff")
+		assert.Error(t, err)
+		_, err = optimize("rename table student to score")
+		assert.Error(t, err)
+		_, err = optimize("rename table teacher to score")
+		assert.Error(t, err)
+		_, err = optimize("rename table student to teacher")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,243 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dml
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan/dml"
+)
+
+// broadcastTable returns the broadcast table of the table source, returns nil if it is not a broadcast table.
+func broadcastTable(ru *rule.Rule, source *ast.TableSourceNode) *rule.VTable {
+	if source == nil {
+		return nil
+	}
+	tn := source.TableName()
+	if tn == nil {
+		return nil
+	}
+	if vt, ok := ru.VTable(tn.Suffix()); ok && vt.IsBroadcast() {
+		return vt
+	}
+	return nil
+}
+
+// pickBroadcastDatabase picks a database which contains all the broadcast tables. The database which the current
+// transaction has already began on is preferred, so that no more database will be involved in the transaction,
+// otherwise the database is chosen randomly to spread the reads.
+func pickBroadcastDatabase(ctx context.Context, tables ...*rule.VTable) (string, error) {
+	var candidates []string
+	for i, vt := range tables {
+		databases := vt.Topology().EnumerateDatabases()
+		if i == 0 {
+			candidates = databases
+			continue
+		}
+		var common []string
+		for _, db := range candidates {
+			if idx := sort.SearchStrings(databases, db); idx < len(databases) && databases[idx] == db {
+				common = append(common, db)
+			}
+		}
+		candidates = common
+	}
+
+	if len(candidates) < 1 {
+		return "", errors.New("no database contains all the broadcast tables")
+	}
+
+	groups := rcontext.TxGroups(ctx)
+	for _, db := range candidates {
+		if idx := sort.SearchStrings(groups, db); idx < len(groups) && groups[idx] == db {
+			return db, nil
+		}
+	}
+	return candidates[rand.Intn(len(candidates))], nil
+}
+
+// newBroadcastWrite creates the plan which writes all the replicas of broadcast table in a transaction.
+func newBroadcastWrite(o *optimize.Optimizer, vt *rule.VTable, stmt ast.Statement) *dml.BroadcastPlan {
+	ret := dml.NewBroadcastPlan(stmt, vt.Topology().EnumerateDatabases())
+	ret.BindArgs(o.Args)
+	return ret
+}
+
+// newBroadcastInsert creates the plan which inserts the rows into all the replicas of broadcast table, the primary key
+// will be generated once before broadcasting if the sequence is configured, so that the replicas are identical.
+func newBroadcastInsert(
+	ctx context.Context,
+	o *optimize.Optimizer,
+	vt *rule.VTable,
+	stmt ast.Statement,
+	columns *[]string,
+	values [][]ast.ExpressionNode,
+) (*dml.BroadcastPlan, error) {
+	var lastInsertID uint64
+	if vt.GetAutoIncrement() != nil {
+		var err error
+		if lastInsertID, err = generateInsertIDs(ctx, vt, columns, values); err != nil {
+			return nil, err
+		}
+	}
+	ret := newBroadcastWrite(o, vt, stmt)
+	ret.SetLastInsertID(lastInsertID)
+	return ret, nil
+}
+
+// optimizeBroadcastSelect reads the broadcast table from any single database.
+func optimizeBroadcastSelect(ctx context.Context, o *optimize.Optimizer, stmt *ast.SelectStatement, vt *rule.VTable) (proto.Plan, error) {
+	db, err := pickBroadcastDatabase(ctx, vt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err = rewriteSelectStatement(ctx, stmt, vt.Name()); err != nil {
+		return nil, err
+	}
+
+	normalizedFields := make([]string, 0, len(stmt.Select))
+	for i := range stmt.Select {
+		normalizedFields = append(normalizedFields, stmt.Select[i].DisplayName())
+	}
+
+	ret := &dml.SimpleQueryPlan{
+		Stmt:     stmt,
+		Database: db,
+		Tables:   []string{vt.Name()},
+	}
+	ret.BindArgs(o.Args)
+
+	return &dml.RenamePlan{
+		Plan:       ret,
+		RenameList: normalizedFields,
+	}, nil
+}
+
+// optimizeBroadcastJoin pushes down the join with broadcast table, the join will be executed in the database of
+// each shard of the other side, because the broadcast table exists in every database.
+// The returned ok will be false if neither side of join is a broadcast table.
+func optimizeBroadcastJoin(ctx context.Context, o *optimize.Optimizer, stmt *ast.SelectStatement, join *ast.JoinNode) (proto.Plan, bool, error) {
+	var (
+		left  = broadcastTable(o.Rule, join.Left)
+		right = broadcastTable(o.Rule, join.Right)
+	)
+	if left == nil && right == nil {
+		return nil, false, nil
+	}
+
+	newJoinTable := func(source *ast.TableSourceNode, physical string) *dml.JoinTable {
+		alias := source.Alias
+		if alias == "" {
+			alias = source.TableName().Suffix()
+		}
+		return &dml.JoinTable{
+			Tables: []string{physical},
+			Alias:  alias,
+		}
+	}
+	newJoin := func(db, leftTable, rightTable string) proto.Plan {
+		ret := &dml.SimpleJoinPlan{
+			Database: db,
+			Left:     newJoinTable(join.Left, leftTable),
+			Join:     join,
+			Right:    newJoinTable(join.Right, rightTable),
+			Stmt:     stmt,
+		}
+		ret.BindArgs(o.Args)
+		return ret
+	}
+
+	// both are broadcast tables, join them in any database
+	if left != nil && right != nil {
+		db, err := pickBroadcastDatabase(ctx, left, right)
+		if err != nil {
+			return nil, true, errors.WithStack(err)
+		}
+		return newJoin(db, left.Name(), right.Name()), true, nil
+	}
+
+	var (
+		broadcast = left
+		other     = join.Right
+	)
+	if broadcast == nil {
+		broadcast, other = right, join.Left
+	}
+
+	table := other.TableName()
+	if table == nil {
+		return nil, true, errors.New("must table, not statement or join node")
+	}
+
+	shards, err := o.ComputeShards(table, nil, o.Args)
+	if err != nil {
+		return nil, true, err
+	}
+
+	// the other one is not a sharding table, join it in the default database
+	if shards == nil {
+		if left != nil {
+			return newJoin("", left.Name(), table.Suffix()), true, nil
+		}
+		return newJoin("", table.Suffix(), right.Name()), true, nil
+	}
+
+	// the unmatched rows of broadcast table would be duplicated by each shard
+	if shards.Len() > 1 && ((left != nil && join.Typ == ast.LeftJoin) || (right != nil && join.Typ == ast.RightJoin)) {
+		return nil, true, errors.Errorf("not support outer join which preserves broadcast table '%s' on multiple shards", broadcast.Name())
+	}
+
+	databases := broadcast.Topology().EnumerateDatabases()
+	keys := make([]string, 0, len(shards))
+	for db := range shards {
+		keys = append(keys, db)
+	}
+	sort.Strings(keys)
+
+	plans := make([]proto.Plan, 0, shards.Len())
+	for _, db := range keys {
+		if idx := sort.SearchStrings(databases, db); idx >= len(databases) || databases[idx] != db {
+			return nil, true, errors.Errorf("broadcast table '%s' doesn't exist in database '%s'", broadcast.Name(), db)
+		}
+		for _, physical := range shards[db] {
+			if left != nil {
+				plans = append(plans, newJoin(db, left.Name(), physical))
+			} else {
+				plans = append(plans, newJoin(db, physical, right.Name()))
+			}
+		}
+	}
+
+	if len(plans) == 1 {
+		return plans[0], true, nil
+	}
+	return &dml.CompositePlan{Plans: plans}, true, nil
+}
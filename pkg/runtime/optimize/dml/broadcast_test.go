@@ -0,0 +1,237 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeBroadcast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+
+	var ru rule.Rule
+
+	// country is replicated in db_0000 and db_0001
+	var (
+		country  rule.VTable
+		topology rule.Topology
+	)
+	topology.SetRender(func(i int) string {
+		return fmt.Sprintf("db_%04d", i)
+	}, func(_ int) string {
+		return "country"
+	})
+	topology.SetTopology(0, 0)
+	topology.SetTopology(1, 0)
+	country.SetTopology(&topology)
+	country.SetName("country")
+	country.SetBroadcast(true)
+	country.SetAllowFullScan(true)
+	ru.SetVTable("country", &country)
+
+	// student is sharded by uid into db_0000.student_0000,db_0000.student_0001,db_0001.student_0002,db_0001.student_0003
+	var (
+		student rule.VTable
+		stuTopo rule.Topology
+	)
+	stuTopo.SetRender(func(i int) string {
+		return fmt.Sprintf("db_%04d", i)
+	}, func(i int) string {
+		return fmt.Sprintf("student_%04d", i)
+	})
+	stuTopo.SetTopology(0, 0, 1)
+	stuTopo.SetTopology(1, 2, 3)
+	student.SetTopology(&stuTopo)
+	student.SetName("student")
+	student.SetAllowFullScan(true)
+	student.SetShardMetadata("uid", &rule.ShardMetadata{
+		Steps: 4,
+		Computer: rule.DirectShardComputer(func(value interface{}) (int, error) {
+			n, err := strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return 0, err
+			}
+			return n % 4 / 2, nil
+		}),
+	}, &rule.ShardMetadata{
+		Steps: 4,
+		Computer: rule.DirectShardComputer(func(value interface{}) (int, error) {
+			n, err := strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return 0, err
+			}
+			return n % 4, nil
+		}),
+	})
+	ru.SetVTable("student", &student)
+
+	var calls []string // db: sql
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			calls = append(calls, db+": "+sql)
+			fields := []proto.Field{mysql.NewField("name", consts.FieldTypeVarChar)}
+			return resultx.New(resultx.WithDataset(&dataset.VirtualDataset{Columns: fields})), nil
+		}).
+		AnyTimes()
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			calls = append(calls, db+": "+sql)
+			return resultx.New(resultx.WithRowsAffected(1)), nil
+		}).
+		AnyTimes()
+
+	ctx := context.Background()
+
+	for _, it := range []struct {
+		sql    string
+		expect []string
+	}{
+		{
+			"insert into country(id,name) values(1,'China')",
+			[]string{
+				"db_0000: INSERT INTO `country`(`id`, `name`) VALUES (1, 'China')",
+				"db_0001: INSERT INTO `country`(`id`, `name`) VALUES (1, 'China')",
+			},
+		},
+		{
+			"update country set name = 'PRC' where id = 1",
+			[]string{
+				"db_0000: UPDATE `country` SET `name` = 'PRC' WHERE `id` = 1",
+				"db_0001: UPDATE `country` SET `name` = 'PRC' WHERE `id` = 1",
+			},
+		},
+		{
+			"delete from country where id = 1",
+			[]string{
+				"db_0000: DELETE FROM `country` WHERE `id` = 1",
+				"db_0001: DELETE FROM `country` WHERE `id` = 1",
+			},
+		},
+		{
+			"select s.name from student s join country c on s.country_id = c.id",
+			[]string{
+				"db_0000: SELECT `s`.`name` FROM student_0000  AS s INNER JOIN country  AS c  ON `s`.`country_id` = `c`.`id`",
+				"db_0000: SELECT `s`.`name` FROM student_0001  AS s INNER JOIN country  AS c  ON `s`.`country_id` = `c`.`id`",
+				"db_0001: SELECT `s`.`name` FROM student_0002  AS s INNER JOIN country  AS c  ON `s`.`country_id` = `c`.`id`",
+				"db_0001: SELECT `s`.`name` FROM student_0003  AS s INNER JOIN country  AS c  ON `s`.`country_id` = `c`.`id`",
+			},
+		},
+	} {
+		t.Run(it.sql, func(t *testing.T) {
+			calls = nil
+
+			stmt, _ := parser.New().ParseOneStmt(it.sql, "", "")
+			opt, err := NewOptimizer(&ru, nil, stmt, nil)
+			assert.NoError(t, err)
+
+			plan, err := opt.Optimize(ctx)
+			assert.NoError(t, err)
+
+			res, err := plan.ExecIn(ctx, fakeTx{conn: conn})
+			assert.NoError(t, err)
+			if plan.Type() == proto.PlanTypeExec {
+				affected, _ := res.RowsAffected()
+				assert.Equal(t, uint64(1), affected)
+			} else {
+				ds, err := res.Dataset()
+				assert.NoError(t, err)
+				_, err = ds.Next()
+				assert.ErrorIs(t, err, io.EOF)
+			}
+
+			sort.Strings(calls)
+			assert.Equal(t, it.expect, calls)
+		})
+	}
+
+	t.Run("read from any replica", func(t *testing.T) {
+		calls = nil
+
+		stmt, _ := parser.New().ParseOneStmt("select name from country where id = 1", "", "")
+		opt, err := NewOptimizer(&ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+
+		_, err = plan.ExecIn(ctx, conn)
+		assert.NoError(t, err)
+
+		assert.Len(t, calls, 1)
+		assert.Regexp(t, "^db_000[01]: SELECT `name` FROM `country` WHERE `id` = 1$", calls[0])
+	})
+
+	t.Run("read from the replica in transaction", func(t *testing.T) {
+		// the transaction has already began on db_0001, reading from it avoids involving another database
+		ctx := rcontext.WithTxGroups(ctx, []string{"db_0001"})
+		for i := 0; i < 8; i++ {
+			calls = nil
+
+			stmt, _ := parser.New().ParseOneStmt("select name from country where id = 1", "", "")
+			opt, err := NewOptimizer(&ru, nil, stmt, nil)
+			assert.NoError(t, err)
+
+			plan, err := opt.Optimize(ctx)
+			assert.NoError(t, err)
+
+			_, err = plan.ExecIn(ctx, fakeTx{conn: conn})
+			assert.NoError(t, err)
+
+			assert.Equal(t, []string{"db_0001: SELECT `name` FROM `country` WHERE `id` = 1"}, calls)
+		}
+	})
+
+	t.Run("outer join preserves broadcast table", func(t *testing.T) {
+		stmt, _ := parser.New().ParseOneStmt("select s.name from country c left join student s on s.country_id = c.id", "", "")
+		opt, err := NewOptimizer(&ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		_, err = opt.Optimize(ctx)
+		assert.Error(t, err)
+	})
+}
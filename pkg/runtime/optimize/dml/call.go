@@ -0,0 +1,98 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dml
+
+import (
+	"context"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/hint"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/runtime/namespace"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan/dml"
+)
+
+func init() {
+	optimize.Register(ast.SQLTypeCall, optimizeCall)
+}
+
+// optimizeCall routes the CALL statement to a single group.
+// The group can be specified by ROUTE hint, eg: /*A! ROUTE(employees_0001) */ CALL foo(),
+// the default group will be used if no route hint found.
+func optimizeCall(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	stmt := o.Stmt.(*ast.CallStatement)
+
+	// the logical schema doesn't exist in physical databases
+	if strings.EqualFold(stmt.Schema, rcontext.Schema(ctx)) {
+		stmt.Schema = ""
+	}
+
+	group, err := routeCall(ctx, o.Hints)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to optimize CALL statement")
+	}
+
+	ret := dml.NewCallPlan(stmt)
+	ret.BindArgs(o.Args)
+	ret.SetDatabase(group)
+
+	return ret, nil
+}
+
+func routeCall(ctx context.Context, hints []*hint.Hint) (string, error) {
+	var group string
+	for _, h := range hints {
+		if h.Type != hint.TypeRoute {
+			continue
+		}
+		for _, in := range h.Inputs {
+			// accept both 'group' and 'group.table'
+			next := in.V
+			if i := strings.IndexByte(next, '.'); i != -1 {
+				next = next[:i]
+			}
+			if len(group) > 0 && group != next {
+				return "", errors.Errorf("cannot route CALL to multiple groups: %s, %s", group, next)
+			}
+			group = next
+		}
+	}
+
+	if len(group) < 1 {
+		return "", nil
+	}
+
+	ns := namespace.Load(rcontext.Schema(ctx))
+	if ns == nil {
+		return "", errors.Errorf("no such logical database %s", rcontext.Schema(ctx))
+	}
+	for _, it := range ns.DBGroups() {
+		if it == group {
+			return group, nil
+		}
+	}
+	return "", errors.Errorf("no such group %s", group)
+}
@@ -40,6 +40,18 @@ func init() {
 func optimizeDelete(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
 	stmt := o.Stmt.(*ast.DeleteStatement)
 
+	if stmt.IsMultiTable() {
+		return optimizeMultiTableDelete(ctx, o)
+	}
+
+	if vt, ok := o.Rule.VTable(stmt.Table.Suffix()); ok && vt.IsBroadcast() {
+		return newBroadcastWrite(o, vt, stmt), nil
+	}
+
+	if ret, err := optimizeGlobalIndexLookup(o, proto.PlanTypeExec, stmt.Table, stmt.Where, optimizeDelete); ret != nil || err != nil {
+		return ret, err
+	}
+
 	shards, err := o.ComputeShards(stmt.Table, stmt.Where, o.Args)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to optimize DELETE statement")
@@ -51,9 +63,37 @@ func optimizeDelete(ctx context.Context, o *optimize.Optimizer) (proto.Plan, err
 		return plan.Transparent(stmt, o.Args), nil
 	}
 
-	ret := dml.NewSimpleDeletePlan(stmt)
-	ret.BindArgs(o.Args)
-	ret.SetShards(shards)
+	var ret proto.Plan
+
+	// the ORDER BY and LIMIT should be applied to all shards
+	if stmt.Limit != nil && shards.Len() > 1 {
+		ret, err = optimizeOrderedLimit(ctx, o, o.Rule.MustVTable(stmt.Table.Suffix()), &orderedLimit{
+			table:   stmt.Table,
+			where:   stmt.Where,
+			orderBy: stmt.OrderBy,
+			limit:   stmt.Limit,
+			write: func(physical string) ast.Statement {
+				ret := new(ast.DeleteStatement)
+				*ret = *stmt
+				ret.Table = stmt.Table.ResetSuffix(physical)
+				ret.Where, ret.OrderBy, ret.Limit = nil, nil, nil
+				return ret
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		simple := dml.NewSimpleDeletePlan(stmt)
+		simple.BindArgs(o.Args)
+		simple.SetShards(shards)
+		ret = simple
+	}
+
+	// remove the entries of deleted rows from the global indexes
+	if vt, ok := o.Rule.VTable(stmt.Table.Suffix()); ok && len(vt.GlobalIndexes()) > 0 && !shards.IsEmpty() {
+		return newGlobalIndexWrite(o, vt, ret, stmt.Where, shards)
+	}
 
 	return ret, nil
 }
@@ -0,0 +1,313 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml
+
+import (
+	"context"
+	"sort"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/hint"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/cmp"
+	"github.com/arana-db/arana/pkg/runtime/misc/extvalue"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan/dml"
+)
+
+// optimizeGlobalIndexLookup returns the plan which looks up the global secondary index for the shards first, if no
+// sharding key but an indexed column is found in WHERE, eg: the orders sharded by user_id with WHERE order_no = ?.
+// The statement will be optimized again with the shards of lookup, returns nil if the index cannot be used.
+func optimizeGlobalIndexLookup(
+	o *optimize.Optimizer,
+	typ proto.PlanType,
+	table ast.TableName,
+	where ast.ExpressionNode,
+	process optimize.Processor,
+) (proto.Plan, error) {
+	if table == nil || where == nil || hasShardingHints(o.Hints) {
+		return nil, nil
+	}
+	vt, ok := o.Rule.VTable(table.Suffix())
+	if !ok || len(vt.GlobalIndexes()) < 1 {
+		return nil, nil
+	}
+
+	// the sharding keys are found, or the errors will be returned by the default optimization
+	if shards, err := optimize.NewXSharder(o.Rule, o.Args).SimpleShard(table, where); err != nil || shards != nil {
+		return nil, nil
+	}
+
+	index, filter := globalIndexFilter(vt, where)
+	if index == nil {
+		return nil, nil
+	}
+
+	indexTable, ok := o.Rule.VTable(index.Table)
+	if !ok {
+		return nil, errors.Errorf("no sharding table '%s' found for global index '%s'", index.Table, index.Name)
+	}
+	shards, err := optimize.NewXSharder(o.Rule, o.Args).SimpleShard(ast.TableName{index.Table}, filter)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot compute shards of global index '%s'", index.Name)
+	}
+	if shards == nil || shards.IsFullScan() {
+		shards = indexTable.Topology().Enumerate()
+	}
+
+	keys := optimize.ShardKeys(vt)
+	ret := &dml.GlobalIndexLookupPlan{
+		PlanType: typ,
+		Shards:   shards,
+		Filter:   filter,
+		Keys:     keys,
+		Route: func(values []proto.Value) (rule.DatabaseTables, error) {
+			shards, err := optimize.ShardByValues(o.Rule, table, keys, values)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if shards == nil || shards.IsFullScan() {
+				return vt.Topology().Enumerate(), nil
+			}
+			return shards, nil
+		},
+		Build: func(ctx context.Context, shards rule.DatabaseTables) (proto.Plan, error) {
+			// route to the shards found in the index
+			hints := make([]*hint.Hint, 0, len(o.Hints)+1)
+			hints = append(hints, o.Hints...)
+			hints = append(hints, routeHint(shards))
+			return process(ctx, &optimize.Optimizer{
+				Rule:  o.Rule,
+				Hints: hints,
+				Stmt:  o.Stmt,
+				Args:  o.Args,
+			})
+		},
+	}
+	ret.BindArgs(o.Args)
+
+	return ret, nil
+}
+
+// newGlobalIndexWrite wraps the plan which updates or deletes the rows in the shards, the entries of
+// global secondary indexes will be synchronized in the same transaction.
+func newGlobalIndexWrite(o *optimize.Optimizer, vt *rule.VTable, p proto.Plan, where ast.ExpressionNode, shards rule.DatabaseTables) (proto.Plan, error) {
+	indexes, err := newGlobalIndexTables(o, vt)
+	if err != nil {
+		return nil, err
+	}
+
+	dbs := make([]string, 0, len(shards))
+	for db := range shards {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	var scopes []*dml.GlobalIndexScope
+	for _, db := range dbs {
+		for _, table := range shards[db] {
+			scopes = append(scopes, &dml.GlobalIndexScope{DB: db, Table: table})
+		}
+	}
+
+	ret := &dml.GlobalIndexWritePlan{
+		Plan:    p,
+		Where:   where,
+		Scopes:  scopes,
+		Indexes: indexes,
+	}
+	ret.BindArgs(o.Args)
+
+	return ret, nil
+}
+
+// newGlobalIndexInsert wraps the plan which inserts the rows, the slots are the indexes of rows grouped by db and table.
+// The entries of global secondary indexes will be written in the same transaction.
+func newGlobalIndexInsert(
+	o *optimize.Optimizer,
+	vt *rule.VTable,
+	p proto.Plan,
+	columns []string,
+	values [][]ast.ExpressionNode,
+	slots map[string]map[string][]int,
+) (proto.Plan, error) {
+	indexes, err := newGlobalIndexTables(o, vt)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := optimize.ShardKeys(vt)
+	positions := make([]int, 0, len(keys))
+	for _, key := range keys {
+		i := 0
+		for i < len(columns) && columns[i] != key {
+			i++
+		}
+		if i == len(columns) {
+			return nil, errors.Errorf("the value of sharding key `%s` is required for the table with global index", key)
+		}
+		positions = append(positions, i)
+	}
+
+	dbs := make([]string, 0, len(slots))
+	for db := range slots {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	var scopes []*dml.GlobalIndexScope
+	for _, db := range dbs {
+		tables := make([]string, 0, len(slots[db]))
+		for table := range slots[db] {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+
+		for _, table := range tables {
+			scope := &dml.GlobalIndexScope{DB: db, Table: table}
+			for _, i := range slots[db][table] {
+				row := make([]proto.Value, 0, len(positions))
+				for _, pos := range positions {
+					v, err := extvalue.Compute(values[i][pos], o.Args...)
+					if err != nil {
+						return nil, errors.Wrapf(err, "cannot compute the value of sharding key `%s`", columns[pos])
+					}
+					row = append(row, v)
+				}
+				scope.Keys = append(scope.Keys, row)
+			}
+			scopes = append(scopes, scope)
+		}
+	}
+
+	ret := &dml.GlobalIndexWritePlan{
+		Plan:    p,
+		Scopes:  scopes,
+		Indexes: indexes,
+	}
+	ret.BindArgs(o.Args)
+
+	return ret, nil
+}
+
+// newGlobalIndexTables creates the index tables of all the global secondary indexes of VTable.
+func newGlobalIndexTables(o *optimize.Optimizer, vt *rule.VTable) ([]*dml.GlobalIndexTable, error) {
+	keys := optimize.ShardKeys(vt)
+	ret := make([]*dml.GlobalIndexTable, 0, len(vt.GlobalIndexes()))
+	for _, it := range vt.GlobalIndexes() {
+		route, err := optimize.RouteGlobalIndex(o.Rule, it)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &dml.GlobalIndexTable{
+			Column: it.Column,
+			Keys:   keys,
+			Route:  route,
+		})
+	}
+	return ret, nil
+}
+
+// isGlobalIndexColumn returns true if the column is indexed by a global secondary index.
+func isGlobalIndexColumn(vt *rule.VTable, column string) bool {
+	for _, it := range vt.GlobalIndexes() {
+		if it.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// globalIndexFilter returns the global index and the condition of indexed column in WHERE, eg: order_no = ?
+// or order_no IN (?,?), the column of condition will be unqualified, so it can be applied to the index table.
+func globalIndexFilter(vt *rule.VTable, where ast.ExpressionNode) (*rule.GlobalIndex, ast.ExpressionNode) {
+	for _, index := range vt.GlobalIndexes() {
+		var filter ast.ExpressionNode
+		eachConjunct(where, func(expr ast.ExpressionNode) bool {
+			column := keyColumn(expr)
+			if column == nil || column.Suffix() != index.Column {
+				return true
+			}
+			atom := &ast.AtomPredicateNode{
+				A: ast.ColumnNameExpressionAtom([]string{index.Column}),
+			}
+			switch p := expr.(*ast.PredicateExpressionNode).P.(type) {
+			case *ast.BinaryComparisonPredicateNode:
+				if p.Op != cmp.Ceq {
+					return true
+				}
+				value := p.Right
+				if columnOf(p.Left) == nil {
+					value = p.Left
+				}
+				filter = &ast.PredicateExpressionNode{
+					P: &ast.BinaryComparisonPredicateNode{Left: atom, Op: cmp.Ceq, Right: value},
+				}
+			case *ast.InPredicateNode:
+				if p.Not {
+					return true
+				}
+				filter = &ast.PredicateExpressionNode{
+					P: &ast.InPredicateNode{P: atom, E: p.E},
+				}
+			default:
+				return true
+			}
+			return false
+		})
+		if filter != nil {
+			return index, filter
+		}
+	}
+	return nil, nil
+}
+
+// hasShardingHints returns true if the shards are specified by hints.
+func hasShardingHints(hints []*hint.Hint) bool {
+	for _, it := range hints {
+		switch it.Type {
+		case hint.TypeFullScan, hint.TypeDirect, hint.TypeRoute, hint.TypeShardValue:
+			return true
+		}
+	}
+	return false
+}
+
+// routeHint creates the ROUTE hint of shards, eg: ROUTE(employees_0000.student_0001,employees_0001.student_0003)
+func routeHint(shards rule.DatabaseTables) *hint.Hint {
+	dbs := make([]string, 0, len(shards))
+	for db := range shards {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	ret := &hint.Hint{Type: hint.TypeRoute}
+	for _, db := range dbs {
+		for _, table := range shards[db] {
+			ret.Inputs = append(ret.Inputs, hint.KeyValue{V: db + "." + table})
+		}
+	}
+	return ret
+}
@@ -0,0 +1,279 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	_ "github.com/arana-db/arana/pkg/runtime/optimize/dal"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeGlobalIndex(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	loader := testdata.NewMockSchemaLoader(ctrl)
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(map[string]*proto.TableMetadata{
+		"orders_0000": {
+			Name:        "orders",
+			ColumnNames: []string{"id", "uid", "order_no"},
+		},
+	}, nil).AnyTimes()
+
+	oldLoader := proto.LoadSchemaLoader()
+	proto.RegisterSchemaLoader(loader)
+	defer proto.RegisterSchemaLoader(oldLoader)
+
+	modulo := func(n int) rule.ShardComputer {
+		return rule.DirectShardComputer(func(value interface{}) (int, error) {
+			v, err := strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return 0, err
+			}
+			return v % n, nil
+		})
+	}
+
+	var ru rule.Rule
+
+	// orders is sharded by uid into db_0000.orders_0000,db_0000.orders_0001,db_0001.orders_0002,db_0001.orders_0003
+	var (
+		orders     rule.VTable
+		ordersTopo rule.Topology
+	)
+	ordersTopo.SetRender(func(i int) string {
+		return fmt.Sprintf("db_%04d", i)
+	}, func(i int) string {
+		return fmt.Sprintf("orders_%04d", i)
+	})
+	ordersTopo.SetTopology(0, 0, 1)
+	ordersTopo.SetTopology(1, 2, 3)
+	orders.SetTopology(&ordersTopo)
+	orders.SetName("orders")
+	orders.SetAllowFullScan(true)
+	orders.SetShardMetadata("uid", &rule.ShardMetadata{
+		Steps: 4,
+		Computer: rule.DirectShardComputer(func(value interface{}) (int, error) {
+			n, err := strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return 0, err
+			}
+			return n % 4 / 2, nil
+		}),
+	}, &rule.ShardMetadata{
+		Steps:    4,
+		Computer: modulo(4),
+	})
+	orders.SetGlobalIndexes(&rule.GlobalIndex{Name: "idx_order_no", Column: "order_no", Table: "order_index"})
+	ru.SetVTable("orders", &orders)
+
+	// order_index is sharded by order_no into db_0000.order_index_0000,db_0001.order_index_0001
+	var (
+		index     rule.VTable
+		indexTopo rule.Topology
+	)
+	indexTopo.SetRender(func(i int) string {
+		return fmt.Sprintf("db_%04d", i)
+	}, func(i int) string {
+		return fmt.Sprintf("order_index_%04d", i)
+	})
+	indexTopo.SetTopology(0, 0)
+	indexTopo.SetTopology(1, 1)
+	index.SetTopology(&indexTopo)
+	index.SetName("order_index")
+	index.SetAllowFullScan(true)
+	index.SetShardMetadata("order_no", &rule.ShardMetadata{
+		Steps:    2,
+		Computer: modulo(2),
+	}, &rule.ShardMetadata{
+		Steps:    2,
+		Computer: modulo(2),
+	})
+	ru.SetVTable("order_index", &index)
+
+	var (
+		calls   []string                    // db: sql
+		entries = make(map[string][][2]int) // physical table -> (order_no, uid)
+		tableOf = regexp.MustCompile("(orders|order_index)_[0-9]{4}")
+	)
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			calls = append(calls, db+": "+sql)
+			if !strings.HasPrefix(sql, "SELECT DISTINCT") {
+				fields := []proto.Field{mysql.NewField("id", consts.FieldTypeLongLong)}
+				return resultx.New(resultx.WithDataset(&dataset.VirtualDataset{Columns: fields})), nil
+			}
+			fields := []proto.Field{
+				mysql.NewField("order_no", consts.FieldTypeLongLong),
+				mysql.NewField("uid", consts.FieldTypeLongLong),
+			}
+			if !strings.Contains(sql, "`order_no`,") { // lookup the sharding keys only
+				fields = fields[1:]
+			}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			for _, it := range entries[tableOf.FindString(sql)] {
+				values := []proto.Value{proto.NewValueInt64(int64(it[0])), proto.NewValueInt64(int64(it[1]))}
+				ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, values[2-len(fields):]))
+			}
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			calls = append(calls, db+": "+sql)
+			switch table := tableOf.FindString(sql); {
+			case strings.HasPrefix(sql, "INSERT"):
+				entries[table] = append(entries[table], [2]int{13, 5})
+			case strings.HasPrefix(sql, "DELETE FROM"):
+				delete(entries, table)
+			}
+			return resultx.New(resultx.WithRowsAffected(1)), nil
+		}).
+		AnyTimes()
+
+	ctx := context.Background()
+
+	for _, it := range []struct {
+		sql    string
+		expect []string
+	}{
+		{
+			"insert into orders(id,uid,order_no) values(1,5,13)",
+			[]string{
+				"db_0000: SELECT DISTINCT `order_no`, `uid` FROM `orders_0001` WHERE `uid` IN (?) FOR UPDATE",
+				"db_0000: INSERT INTO `orders_0001`(`id`, `uid`, `order_no`) VALUES (1, 5, 13)",
+				"db_0000: SELECT DISTINCT `order_no`, `uid` FROM `orders_0001` WHERE `uid` IN (?)",
+				"db_0001: INSERT IGNORE INTO `order_index_0001`(`order_no`, `uid`) VALUES (?, ?)",
+			},
+		},
+		{
+			"select id from orders where order_no = 13",
+			[]string{
+				"db_0001: SELECT DISTINCT `uid` FROM `order_index_0001` WHERE `order_no` = 13",
+				"db_0000: SELECT `id` FROM `orders_0001` WHERE `order_no` = 13",
+			},
+		},
+		{
+			"delete from orders where order_no = 13",
+			[]string{
+				"db_0001: SELECT DISTINCT `uid` FROM `order_index_0001` WHERE `order_no` = 13",
+				"db_0000: SELECT DISTINCT `order_no`, `uid` FROM `orders_0001` WHERE `order_no` = 13 FOR UPDATE",
+				"db_0000: DELETE FROM `orders_0001` WHERE `order_no` = 13",
+				"db_0000: SELECT DISTINCT `order_no`, `uid` FROM `orders_0001` WHERE `uid` IN (?)",
+				"db_0001: DELETE FROM `order_index_0001` WHERE (`order_no`, `uid`) IN ((?, ?))",
+			},
+		},
+	} {
+		t.Run(it.sql, func(t *testing.T) {
+			calls = nil
+
+			stmt, _ := parser.New().ParseOneStmt(it.sql, "", "")
+			opt, err := NewOptimizer(&ru, nil, stmt, nil)
+			assert.NoError(t, err)
+
+			plan, err := opt.Optimize(ctx)
+			assert.NoError(t, err)
+
+			_, err = plan.ExecIn(ctx, fakeTx{conn: conn})
+			assert.NoError(t, err)
+			assert.Equal(t, it.expect, calls)
+		})
+	}
+
+	t.Run("check and recover", func(t *testing.T) {
+		// 13 is stored in a wrong index table, and 14 points to no row
+		entries = map[string][][2]int{
+			"orders_0001":      {{13, 5}},
+			"order_index_0000": {{13, 5}, {14, 6}},
+		}
+
+		stmt, _ := parser.New().ParseOneStmt("admin check index orders idx_order_no", "", "")
+		opt, err := NewOptimizer(&ru, nil, stmt, nil)
+		assert.NoError(t, err)
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, proto.PlanTypeQuery, plan.Type())
+
+		res, err := plan.ExecIn(ctx, conn)
+		assert.NoError(t, err)
+		ds, err := res.Dataset()
+		assert.NoError(t, err)
+
+		var reports []string
+		for {
+			row, err := ds.Next()
+			if err != nil {
+				assert.ErrorIs(t, err, io.EOF)
+				break
+			}
+			values := make([]proto.Value, 7)
+			assert.NoError(t, row.Scan(values))
+			reports = append(reports, fmt.Sprintf("%s %s.%s %s %s", values[4], values[2], values[3], values[5], values[6]))
+		}
+		assert.Equal(t, []string{
+			"misplaced db_0000.order_index_0000 13 uid=5",
+			"missing db_0001.order_index_0001 13 uid=5",
+			"dangling db_0000.order_index_0000 14 uid=6",
+		}, reports)
+
+		calls = nil
+		stmt, _ = parser.New().ParseOneStmt("admin recover index orders idx_order_no", "", "")
+		opt, err = NewOptimizer(&ru, nil, stmt, nil)
+		assert.NoError(t, err)
+		plan, err = opt.Optimize(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, proto.PlanTypeExec, plan.Type())
+
+		res, err = plan.ExecIn(ctx, conn)
+		assert.NoError(t, err)
+		affected, _ := res.RowsAffected()
+		assert.Equal(t, uint64(2), affected)
+		assert.Equal(t, []string{
+			"db_0001: INSERT IGNORE INTO `order_index_0001`(`order_no`, `uid`) VALUES (?, ?)",
+			"db_0000: DELETE FROM `order_index_0000` WHERE (`order_no`, `uid`) IN ((?, ?),(?, ?))",
+		}, calls[len(calls)-2:])
+	})
+}
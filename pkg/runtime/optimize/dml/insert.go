@@ -27,10 +27,12 @@ import (
 
 import (
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/hint"
 	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/runtime/ast"
 	"github.com/arana-db/arana/pkg/runtime/cmp"
 	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/runtime/logical"
 	"github.com/arana-db/arana/pkg/runtime/optimize"
 	"github.com/arana-db/arana/pkg/runtime/plan/dml"
 )
@@ -53,56 +55,273 @@ func optimizeInsert(ctx context.Context, o *optimize.Optimizer) (proto.Plan, err
 	)
 
 	if vt, ok = o.Rule.VTable(stmt.Table.Suffix()); !ok { // insert into non-sharding table
-		ret.Put("", stmt)
+		group, _ := o.Rule.SingleTable(stmt.Table.Suffix())
+		ret.Put(group, stmt)
 		return ret, nil
 	}
 
-	// TODO: handle multiple shard keys.
+	if vt.IsBroadcast() {
+		ret, err := newBroadcastInsert(ctx, o, vt, stmt, &stmt.Columns, stmt.Values)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to insert")
+		}
+		return ret, nil
+	}
 
-	bingo := -1
-	// check existing shard columns
-	for i, col := range stmt.Columns {
-		if _, _, ok = vt.GetShardMetadata(col); ok {
-			bingo = i
-			break
+	// check on duplicated key update
+	for _, upd := range stmt.DuplicatedUpdates {
+		if _, _, ok := vt.GetShardMetadata(upd.Column.Suffix()); !ok {
+			continue
+		}
+		if !vt.AllowUpdateShardKey() {
+			return nil, errors.New("do not support update sharding key")
+		}
+		if len(vt.GlobalIndexes()) > 0 {
+			return nil, errors.New("do not support update sharding key of table with global index")
+		}
+		mover, err := newShardKeyMover(ctx, o, vt, tableName)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to insert")
+		}
+		if _, err = checkShardKeyUpsert(stmt.Columns, stmt.DuplicatedUpdates, mover); err != nil {
+			return nil, err
 		}
+		ret.SetShardKeyMover(mover)
+		break
 	}
 
-	if bingo < 0 {
+	// generate the distributed primary key before routing, it may be the sharding key
+	lastInsertID, err := generateInsertIDs(ctx, vt, &stmt.Columns, stmt.Values)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to insert")
+	}
+	ret.SetLastInsertID(lastInsertID)
+
+	bingo := findShardKeys(vt, stmt.Columns)
+	if len(bingo) < 1 {
 		return nil, errors.Wrap(optimize.ErrNoShardKeyFound, "failed to insert")
 	}
 
-	// check on duplicated key update
-	for _, upd := range stmt.DuplicatedUpdates {
-		if upd.Column.Suffix() == stmt.Columns[bingo] {
+	slots, err := shardValues(o, tableName, stmt.Columns, bingo, stmt.Values)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to insert")
+	}
+
+	for db, slot := range slots {
+		for table, indexes := range slot {
+			// clone insert stmt without values
+			newborn := ast.NewInsertStatement(ast.TableName{table}, stmt.Columns)
+			newborn.SetFlag(stmt.Flag())
+			newborn.DuplicatedUpdates = stmt.DuplicatedUpdates
+
+			// collect values with same table
+			values := make([][]ast.ExpressionNode, 0, len(indexes))
+			for _, i := range indexes {
+				values = append(values, stmt.Values[i])
+			}
+			newborn.Values = values
+
+			ret.Put(db, newborn)
+		}
+	}
+
+	if len(vt.GlobalIndexes()) > 0 {
+		return newGlobalIndexInsert(o, vt, ret, stmt.Columns, stmt.Values, slots)
+	}
+
+	return ret, nil
+}
+
+func optimizeInsertSelect(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	stmt := o.Stmt.(*ast.InsertSelectStatement)
+
+	vt, ok := o.Rule.VTable(stmt.Table.Suffix())
+	if !ok { // insert into non-sharding table
+		ret := dml.NewInsertSelectPlan()
+		ret.BindArgs(o.Args)
+		group, _ := o.Rule.SingleTable(stmt.Table.Suffix())
+		ret.Batch[group] = stmt
+		return ret, nil
+	}
+
+	if vt.IsBroadcast() {
+		return nil, errors.New("not support insert-select into broadcast table")
+	}
+
+	if stmt.Select() == nil {
+		return nil, errors.New("not support insert-union-select into sharding table")
+	}
+
+	if len(vt.GlobalIndexes()) > 0 {
+		return nil, errors.New("not support insert-select into table with global index")
+	}
+
+	ret, columns, err := newShardedInsertSelectPlan(ctx, o, vt, stmt.Table, stmt.Columns, stmt.Select())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to insert")
+	}
+
+	updates := stmt.DuplicatedUpdates()
+	for _, upd := range updates {
+		if _, _, ok := vt.GetShardMetadata(upd.Column.Suffix()); !ok {
+			continue
+		}
+		if !vt.AllowUpdateShardKey() {
 			return nil, errors.New("do not support update sharding key")
 		}
+		mover, err := newShardKeyMover(ctx, o, vt, stmt.Table)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to insert")
+		}
+		if ret.PrimaryKeys, err = checkShardKeyUpsert(columns, updates, mover); err != nil {
+			return nil, err
+		}
+		ret.Mover = mover
+		break
+	}
+
+	flag := stmt.Flag()
+	ret.Write = func(table string, values [][]ast.ExpressionNode) ast.Statement {
+		ret := ast.NewInsertStatement(ast.TableName{table}, columns)
+		ret.SetFlag(flag)
+		ret.DuplicatedUpdates = updates
+		ret.Values = values
+		return ret
+	}
+
+	return ret, nil
+}
+
+// newShardedInsertSelectPlan creates the plan which writes the selected rows into the sharding table, the returned
+// columns are the final columns to be written, which may include the auto-generated primary key column.
+func newShardedInsertSelectPlan(
+	ctx context.Context,
+	o *optimize.Optimizer,
+	vt *rule.VTable,
+	table ast.TableName,
+	columns []string,
+	sel *ast.SelectStatement,
+) (*dml.ShardedInsertSelectPlan, []string, error) {
+	columns, err := shardedInsertColumns(ctx, vt, columns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkColName, seq, err := autoIncrementSequence(ctx, vt, columns)
+	if err != nil {
+		return nil, nil, err
+	}
+	if seq != nil {
+		columns = append(columns[:len(columns):len(columns)], pkColName)
+	}
+
+	// each row must be routed to exactly one physical table, so all the sharding keys are required
+	bingo := findShardKeys(vt, columns)
+	if len(bingo) < len(vt.GetShardKeys()) {
+		return nil, nil, errors.WithStack(optimize.ErrNoShardKeyFound)
+	}
+
+	sub, err := optimizeSubSelect(ctx, o, sel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]string, 0, len(bingo))
+	for _, i := range bingo {
+		keys = append(keys, columns[i])
+	}
+	route, err := newRowRouter(o, table, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret := &dml.ShardedInsertSelectPlan{
+		Select:    sub,
+		Columns:   columns,
+		ShardKeys: bingo,
+		Route:     route,
+		Sequence:  seq,
+		Atomic:    hint.Contains(hint.TypeAtomic, o.Hints),
+	}
+	ret.BindArgs(o.Args)
+
+	return ret, columns, nil
+}
+
+// checkShardKeyUpsert checks the upsert which updates the sharding key, the rows are located by the values
+// of primary key, so the values must be specified and the primary key must not be updated.
+// It returns the indexes of primary keys in the columns.
+func checkShardKeyUpsert(columns []string, updates []*ast.UpdateElement, mover *dml.ShardKeyMover) ([]int, error) {
+	primaryKeys := make([]int, 0, len(mover.PrimaryKeys))
+	for _, pk := range mover.PrimaryKeys {
+		found := -1
+		for i, column := range columns {
+			if column == pk {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			return nil, errors.Errorf("the value of primary key `%s` is required when update sharding key", pk)
+		}
+		for _, upd := range updates {
+			if upd.Column.Suffix() == pk {
+				return nil, errors.New("do not support update primary key and sharding key together")
+			}
+		}
+		primaryKeys = append(primaryKeys, found)
 	}
+	return primaryKeys, nil
+}
 
+// findShardKeys returns the indexes of all shard keys in columns, the composite sharding key requires all of them.
+func findShardKeys(vt *rule.VTable, columns []string) []int {
+	var ret []int
+	for i, col := range columns {
+		if _, _, ok := vt.GetShardMetadata(col); ok {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+// shardValues routes the rows by the values of shard keys, returns the indexes of rows grouped by db and table.
+func shardValues(o *optimize.Optimizer, tableName ast.TableName, columns []string, bingo []int, rows [][]ast.ExpressionNode) (map[string]map[string][]int, error) {
 	var (
 		sharder = optimize.NewXSharder(o.Rule, o.Args)
-		left    = ast.ColumnNameExpressionAtom(make([]string, 1))
-		filter  = &ast.PredicateExpressionNode{
-			P: &ast.BinaryComparisonPredicateNode{
-				Left: &ast.AtomPredicateNode{
-					A: left,
-				},
-				Op: cmp.Ceq,
-			},
-		}
-		slots = make(map[string]map[string][]int) // (db,table,valuesIndex)
+		slots   = make(map[string]map[string][]int) // (db,table,valuesIndex)
+		err     error
 	)
 
-	// reset filter
-	resetFilter := func(column string, value ast.ExpressionNode) {
-		left[0] = column
-		filter.P.(*ast.BinaryComparisonPredicateNode).Right = value.(*ast.PredicateExpressionNode).P
+	// build filter: key1 = value1 AND key2 = value2 ...
+	makeFilter := func(values []ast.ExpressionNode) ast.ExpressionNode {
+		var filter ast.ExpressionNode
+		for _, idx := range bingo {
+			next := &ast.PredicateExpressionNode{
+				P: &ast.BinaryComparisonPredicateNode{
+					Left: &ast.AtomPredicateNode{
+						A: ast.ColumnNameExpressionAtom([]string{columns[idx]}),
+					},
+					Op:    cmp.Ceq,
+					Right: values[idx].(*ast.PredicateExpressionNode).P,
+				},
+			}
+			if filter == nil {
+				filter = next
+				continue
+			}
+			filter = &ast.LogicalExpressionNode{
+				Op:    logical.Land,
+				Left:  filter,
+				Right: next,
+			}
+		}
+		return filter
 	}
 
-	for i, values := range stmt.Values {
+	for i, values := range rows {
 		var shards rule.DatabaseTables
-		value := values[bingo]
-		resetFilter(stmt.Columns[bingo], value)
+		filter := makeFilter(values)
 
 		if len(o.Hints) > 0 {
 			if shards, err = optimize.Hints(tableName, o.Hints, o.Rule); err != nil {
@@ -117,7 +336,7 @@ func optimizeInsert(ctx context.Context, o *optimize.Optimizer) (proto.Plan, err
 		}
 
 		if shards.Len() != 1 {
-			return nil, errors.Wrap(optimize.ErrNoShardKeyFound, "failed to insert")
+			return nil, errors.WithStack(optimize.ErrNoShardKeyFound)
 		}
 
 		var (
@@ -131,49 +350,50 @@ func optimizeInsert(ctx context.Context, o *optimize.Optimizer) (proto.Plan, err
 			break
 		}
 
-		if _, ok = slots[db]; !ok {
+		if _, ok := slots[db]; !ok {
 			slots[db] = make(map[string][]int)
 		}
 		slots[db][table] = append(slots[db][table], i)
 	}
 
-	for db, slot := range slots {
-		for table, indexes := range slot {
-			// clone insert stmt without values
-			newborn := ast.NewInsertStatement(ast.TableName{table}, stmt.Columns)
-			newborn.SetFlag(stmt.Flag())
-			newborn.DuplicatedUpdates = stmt.DuplicatedUpdates
+	return slots, nil
+}
 
-			// collect values with same table
-			values := make([][]ast.ExpressionNode, 0, len(indexes))
-			for _, i := range indexes {
-				values = append(values, stmt.Values[i])
+// newRowRouter creates a router which computes the physical table of row by the values of shard key columns.
+func newRowRouter(o *optimize.Optimizer, tableName ast.TableName, columns []string) (dml.RowRouter, error) {
+	// all rows will be written into the table specified by hints
+	if len(o.Hints) > 0 {
+		shards, err := optimize.Hints(tableName, o.Hints, o.Rule)
+		if err != nil {
+			return nil, errors.Wrap(err, "calculate hints failed")
+		}
+		if shards != nil {
+			if shards.Len() != 1 {
+				return nil, errors.WithStack(optimize.ErrNoShardKeyFound)
 			}
-			newborn.Values = values
-
-			rewriteInsertStatement(ctx, o, vt, newborn)
-			ret.Put(db, newborn)
+			var db, table string
+			for k, v := range shards {
+				db, table = k, v[0]
+			}
+			return func([]proto.Value) (string, string, error) {
+				return db, table, nil
+			}, nil
 		}
 	}
 
-	return ret, nil
-}
-
-func optimizeInsertSelect(_ context.Context, o *optimize.Optimizer) (proto.Plan, error) {
-	stmt := o.Stmt.(*ast.InsertSelectStatement)
-
-	ret := dml.NewInsertSelectPlan()
-
-	ret.BindArgs(o.Args)
-
-	if _, ok := o.Rule.VTable(stmt.Table.Suffix()); !ok { // insert into non-sharding table
-		ret.Batch[""] = stmt
-		return ret, nil
-	}
-
-	// TODO: handle shard keys.
-
-	return nil, errors.New("not support insert-select into sharding table")
+	return func(values []proto.Value) (string, string, error) {
+		shards, err := optimize.ShardByValues(o.Rule, tableName, columns, values)
+		if err != nil {
+			return "", "", errors.WithStack(err)
+		}
+		if shards.Len() != 1 {
+			return "", "", errors.Wrapf(optimize.ErrNoShardKeyFound, "cannot route row with %v=%v", columns, values)
+		}
+		for db, tables := range shards {
+			return db, tables[0], nil
+		}
+		return "", "", errors.WithStack(optimize.ErrNoShardKeyFound)
+	}, nil
 }
 
 func getMetadata(ctx context.Context, vtab *rule.VTable) (*proto.TableMetadata, error) {
@@ -189,27 +409,59 @@ func getMetadata(ctx context.Context, vtab *rule.VTable) (*proto.TableMetadata,
 	return metadata, nil
 }
 
-func rewriteInsertStatement(ctx context.Context, o *optimize.Optimizer, vtab *rule.VTable, stmt *ast.InsertStatement) error {
-	_, tb0, _ := vtab.Topology().Smallest()
-	metadatas, err := proto.LoadSchemaLoader().Load(ctx, rcontext.Schema(ctx), []string{tb0})
+// generateInsertIDs generates the distributed primary key for each row if it's absent, the column and values
+// will be appended. The first generated id will be returned like LAST_INSERT_ID() of MySQL, 0 if nothing generated.
+func generateInsertIDs(ctx context.Context, vtab *rule.VTable, columns *[]string, values [][]ast.ExpressionNode) (uint64, error) {
+	pkColName, seq, err := autoIncrementSequence(ctx, vtab, *columns)
 	if err != nil {
-		return errors.WithStack(err)
+		return 0, err
 	}
-	metadata := metadatas[tb0]
-	if metadata == nil || len(metadata.ColumnNames) == 0 {
-		return errors.Errorf("optimize: cannot get metadata of `%s`.`%s`", rcontext.Schema(ctx), tb0)
+
+	if seq == nil {
+		return 0, nil
+	}
+
+	var first uint64
+	for i := range values {
+		val, err := seq.Acquire(ctx)
+		if err != nil {
+			return 0, errors.Wrap(err, "cannot generate distributed primary key")
+		}
+		if i == 0 {
+			first = uint64(val)
+		}
+		values[i] = append(values[i], &ast.PredicateExpressionNode{
+			P: &ast.AtomPredicateNode{
+				A: &ast.ConstantExpressionAtom{Inner: val},
+			},
+		})
+	}
+
+	newColumns := make([]string, 0, len(*columns)+1)
+	newColumns = append(newColumns, *columns...)
+	*columns = append(newColumns, pkColName)
+
+	return first, nil
+}
+
+// autoIncrementSequence returns the auto-generated primary key column and its sequence,
+// nil sequence will be returned if the value of primary key needn't be generated.
+func autoIncrementSequence(ctx context.Context, vtab *rule.VTable, columns []string) (string, proto.Sequence, error) {
+	metadata, err := getMetadata(ctx, vtab)
+	if err != nil {
+		return "", nil, err
 	}
 
-	if len(metadata.ColumnNames) == len(stmt.Columns) {
+	if len(metadata.ColumnNames) == len(columns) {
 		// User had explicitly specified every value
-		return nil
+		return "", nil, nil
 	}
 	columnsMetadata := metadata.Columns
 
-	for _, colName := range stmt.Columns {
+	for _, colName := range columns {
 		if columnsMetadata[colName].PrimaryKey && columnsMetadata[colName].Generated {
 			// User had explicitly specified auto-generated primary key column
-			return nil
+			return "", nil, nil
 		}
 	}
 
@@ -222,37 +474,25 @@ func rewriteInsertStatement(ctx context.Context, o *optimize.Optimizer, vtab *ru
 	}
 
 	if err := createSequenceIfAbsent(ctx, vtab, metadata); err != nil {
-		return err
+		return "", nil, err
 	}
 
 	if len(pkColName) < 1 {
 		// There's no auto-generated primary key column
-		return nil
+		return "", nil, nil
 	}
 
 	mgr := proto.LoadSequenceManager()
 
 	seq, err := mgr.GetSequence(ctx, rcontext.Tenant(ctx), rcontext.Schema(ctx), proto.BuildAutoIncrementName(vtab.Name()))
 	if err != nil {
-		return err
+		return "", nil, err
 	}
-
-	val, err := seq.Acquire(ctx)
-	if err != nil {
-		return err
+	if seq == nil {
+		return "", nil, errors.Errorf("optimize: no sequence found for `%s`.`%s`", rcontext.Schema(ctx), vtab.Name())
 	}
 
-	// TODO rewrite columns and add distributed primary key
-	stmt.Columns = append(stmt.Columns, pkColName)
-	// append value of distributed primary key
-	for i := range stmt.Values {
-		stmt.Values[i] = append(stmt.Values[i], &ast.PredicateExpressionNode{
-			P: &ast.AtomPredicateNode{
-				A: &ast.ConstantExpressionAtom{Inner: val},
-			},
-		})
-	}
-	return nil
+	return pkColName, seq, nil
 }
 
 func createSequenceIfAbsent(ctx context.Context, vtab *rule.VTable, metadata *proto.TableMetadata) error {
@@ -0,0 +1,449 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql"
+	errors2 "github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeInsertSelectIntoSharding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	loader := testdata.NewMockSchemaLoader(ctrl)
+
+	fakeStudentMetadata := map[string]*proto.TableMetadata{
+		"student_0000": {
+			Name: "student",
+			Columns: map[string]*proto.ColumnMetadata{
+				"id":   {Name: "id", DataType: "bigint", PrimaryKey: true, Generated: true},
+				"name": {Name: "name", DataType: "varchar"},
+				"uid":  {Name: "uid", DataType: "bigint"},
+			},
+			ColumnNames: []string{"id", "name", "uid"},
+		},
+	}
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeStudentMetadata, nil).AnyTimes()
+
+	oldLoader := proto.LoadSchemaLoader()
+	proto.RegisterSchemaLoader(loader)
+	defer proto.RegisterSchemaLoader(oldLoader)
+
+	var nextId int64
+	seq := testdata.NewMockSequence(ctrl)
+	seq.EXPECT().Acquire(gomock.Any()).
+		DoAndReturn(func(ctx context.Context) (int64, error) {
+			nextId++
+			return nextId, nil
+		}).
+		AnyTimes()
+
+	sequences := testdata.NewMockSequenceManager(ctrl)
+	sequences.EXPECT().GetSequence(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(seq, nil).AnyTimes()
+
+	oldSequences := proto.LoadSequenceManager()
+	proto.RegisterSequenceManager(sequences)
+	defer proto.RegisterSequenceManager(oldSequences)
+
+	var (
+		writes  map[string][]string // table -> sql
+		source  = []int64{8, 9, 16} // uid of the selected rows
+		reading *dataset.VirtualDataset
+		unread  []int // count of unread rows when writing
+	)
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			for i := 0; i < 8; i++ {
+				table := fmt.Sprintf("student_%04d", i)
+				if strings.Contains(sql, "`"+table+"`") {
+					writes[table] = append(writes[table], sql)
+				}
+			}
+			unread = append(unread, len(reading.Rows))
+			rows := strings.Count(sql, "),(") + 1
+			return resultx.New(resultx.WithRowsAffected(uint64(rows))), nil
+		}).
+		AnyTimes()
+
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			fields := []proto.Field{
+				mysql.NewField("name", consts.FieldTypeVarChar),
+				mysql.NewField("uid", consts.FieldTypeLongLong),
+			}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			for _, uid := range source {
+				ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{
+					proto.NewValueString(fmt.Sprintf("name_%d", uid)),
+					proto.NewValueInt64(uid),
+				}))
+			}
+			reading = ds
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+
+	var (
+		ctx = context.Background()
+		ru  = makeFakeRule(ctrl, 8)
+	)
+
+	t.Run("sharding", func(t *testing.T) {
+		writes = make(map[string][]string)
+		nextId = 0
+
+		sql := "insert into student(name,uid) select name,uid from student_tmp where uid > ? on duplicate key update name = ?"
+		stmt, _ := parser.New().ParseOneStmt(sql, "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, []proto.Value{
+			proto.NewValueInt64(1),
+			proto.NewValueString("dup"),
+		})
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx) // 8,16 -> student_0000, 9 -> student_0001
+		assert.NoError(t, err)
+
+		res, err := plan.ExecIn(ctx, conn)
+		assert.NoError(t, err)
+
+		affected, _ := res.RowsAffected()
+		assert.Equal(t, uint64(3), affected)
+		assert.Equal(t, int64(3), nextId, "every row should own an auto-increment id")
+		assert.Len(t, writes, 2)
+		assert.Len(t, writes["student_0000"], 1)
+		assert.Len(t, writes["student_0001"], 1)
+		for _, it := range writes {
+			assert.Contains(t, it[0], "(`name`, `uid`, `id`) VALUES")
+			assert.Contains(t, it[0], "ON DUPLICATE KEY UPDATE")
+		}
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		writes = make(map[string][]string)
+		unread = nil
+		source = make([]int64, 0, 600)
+		for i := 0; i < 600; i++ {
+			source = append(source, int64(i*8)) // all rows -> student_0000
+		}
+
+		stmt, _ := parser.New().ParseOneStmt("insert into student(name,uid) select name,uid from student_tmp", "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+
+		res, err := plan.ExecIn(ctx, conn)
+		assert.NoError(t, err)
+
+		affected, _ := res.RowsAffected()
+		assert.Equal(t, uint64(600), affected)
+		// each full batch is written before the rest rows are read
+		assert.Equal(t, []int{600 - 256, 600 - 512, 0}, unread)
+
+		// the rows are buffered in transaction
+		unread = nil
+		_, err = plan.ExecIn(ctx, fakeTx{conn: conn})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 0, 0}, unread)
+	})
+
+	t.Run("column count doesn't match", func(t *testing.T) {
+		writes = make(map[string][]string)
+		source = []int64{8, 9}
+
+		// the select returns name and uid only
+		stmt, _ := parser.New().ParseOneStmt("insert into student(name,uid,id) select name,uid from student_tmp", "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+
+		_, err = plan.ExecIn(ctx, conn)
+		sqlErr, ok := errors.Cause(err).(*errors2.SQLError)
+		assert.True(t, ok)
+		assert.Equal(t, consts.ERWrongValueCountOnRow, sqlErr.Num)
+		assert.Empty(t, writes, "should fail before any row is written")
+	})
+
+	t.Run("update sharding key", func(t *testing.T) {
+		sql := "insert into student(name,uid) select name,uid from student_tmp on duplicate key update uid = uid + 1"
+		stmt, _ := parser.New().ParseOneStmt(sql, "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		_, err = opt.Optimize(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestOptimizer_OptimizeInsertGeneratedShardKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	loader := testdata.NewMockSchemaLoader(ctrl)
+
+	// uid is the auto-generated primary key, and it's the sharding key too
+	fakeStudentMetadata := map[string]*proto.TableMetadata{
+		"student_0000": {
+			Name: "student",
+			Columns: map[string]*proto.ColumnMetadata{
+				"uid":  {Name: "uid", DataType: "bigint", PrimaryKey: true, Generated: true},
+				"name": {Name: "name", DataType: "varchar"},
+			},
+			ColumnNames: []string{"uid", "name"},
+		},
+	}
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeStudentMetadata, nil).AnyTimes()
+
+	oldLoader := proto.LoadSchemaLoader()
+	proto.RegisterSchemaLoader(loader)
+	defer proto.RegisterSchemaLoader(oldLoader)
+
+	nextId := int64(7)
+	seq := testdata.NewMockSequence(ctrl)
+	seq.EXPECT().Acquire(gomock.Any()).
+		DoAndReturn(func(ctx context.Context) (int64, error) {
+			nextId++
+			return nextId, nil
+		}).
+		AnyTimes()
+
+	sequences := testdata.NewMockSequenceManager(ctrl)
+	sequences.EXPECT().GetSequence(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(seq, nil).AnyTimes()
+
+	oldSequences := proto.LoadSequenceManager()
+	proto.RegisterSequenceManager(sequences)
+	defer proto.RegisterSequenceManager(oldSequences)
+
+	writes := make(map[string]string) // table -> sql
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			for i := 0; i < 8; i++ {
+				table := fmt.Sprintf("student_%04d", i)
+				if strings.Contains(sql, "`"+table+"`") {
+					writes[table] = sql
+				}
+			}
+			return resultx.New(resultx.WithRowsAffected(1)), nil
+		}).
+		AnyTimes()
+
+	var (
+		ctx = context.Background()
+		ru  = makeFakeRule(ctrl, 8)
+	)
+
+	stmt, _ := parser.New().ParseOneStmt("insert into student(name) values('foo'),('bar'),('qux')", "", "")
+	opt, err := NewOptimizer(ru, nil, stmt, nil)
+	assert.NoError(t, err)
+
+	plan, err := opt.Optimize(ctx) // 8 -> student_0000, 9 -> student_0001, 10 -> student_0002
+	assert.NoError(t, err)
+
+	res, err := plan.ExecIn(ctx, conn)
+	assert.NoError(t, err)
+
+	affected, _ := res.RowsAffected()
+	assert.Equal(t, uint64(3), affected)
+	lastInsertId, _ := res.LastInsertId()
+	assert.Equal(t, uint64(8), lastInsertId, "should be the first generated id")
+	assert.Equal(t, map[string]string{
+		"student_0000": "INSERT INTO `student_0000`(`name`, `uid`) VALUES ('foo', 8)",
+		"student_0001": "INSERT INTO `student_0001`(`name`, `uid`) VALUES ('bar', 9)",
+		"student_0002": "INSERT INTO `student_0002`(`name`, `uid`) VALUES ('qux', 10)",
+	}, writes)
+}
+
+func TestOptimizer_OptimizeSelectLastInsertID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			assert.Equal(t, "SELECT 8", sql)
+			fields := []proto.Field{mysql.NewField("8", consts.FieldTypeLongLong)}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{proto.NewValueInt64(8)}))
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		Times(1)
+
+	ctx := rcontext.WithLastInsertID(context.Background(), 8)
+
+	stmt, _ := parser.New().ParseOneStmt("select last_insert_id()", "", "")
+	opt, err := NewOptimizer(&rule.Rule{}, nil, stmt, nil)
+	assert.NoError(t, err)
+
+	plan, err := opt.Optimize(ctx)
+	assert.NoError(t, err)
+
+	res, err := plan.ExecIn(ctx, conn)
+	assert.NoError(t, err)
+
+	ds, err := res.Dataset()
+	assert.NoError(t, err)
+	fields, _ := ds.Fields()
+	assert.Equal(t, "last_insert_id()", fields[0].Name())
+}
+
+func TestOptimizer_OptimizeInsertSelectCompositeShardKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	loader := testdata.NewMockSchemaLoader(ctrl)
+
+	fakeOrdersMetadata := map[string]*proto.TableMetadata{
+		"orders_0000": {
+			Name: "orders",
+			Columns: map[string]*proto.ColumnMetadata{
+				"tenant_id": {Name: "tenant_id", DataType: "bigint"},
+				"order_id":  {Name: "order_id", DataType: "bigint"},
+				"name":      {Name: "name", DataType: "varchar"},
+			},
+			ColumnNames: []string{"tenant_id", "order_id", "name"},
+		},
+	}
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeOrdersMetadata, nil).AnyTimes()
+
+	oldLoader := proto.LoadSchemaLoader()
+	proto.RegisterSchemaLoader(loader)
+	defer proto.RegisterSchemaLoader(oldLoader)
+
+	var writes []string
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			writes = append(writes, db+": "+sql)
+			return resultx.New(resultx.WithRowsAffected(1)), nil
+		}).
+		AnyTimes()
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			fields := []proto.Field{
+				mysql.NewField("tenant_id", consts.FieldTypeLongLong),
+				mysql.NewField("order_id", consts.FieldTypeLongLong),
+				mysql.NewField("name", consts.FieldTypeVarChar),
+			}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			ds.Rows = append(ds.Rows,
+				rows.NewTextVirtualRow(fields, []proto.Value{proto.NewValueInt64(1), proto.NewValueInt64(6), proto.NewValueString("foo")}),
+				rows.NewTextVirtualRow(fields, []proto.Value{proto.NewValueInt64(2), proto.NewValueInt64(3), proto.NewValueString("bar")}),
+			)
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+
+	var (
+		ctx = context.Background()
+		ru  = makeCompositeRule()
+	)
+
+	stmt, _ := parser.New().ParseOneStmt("insert into orders(tenant_id,order_id,name) select tenant_id,order_id,name from orders_tmp", "", "")
+	opt, err := NewOptimizer(ru, nil, stmt, nil)
+	assert.NoError(t, err)
+
+	plan, err := opt.Optimize(ctx)
+	assert.NoError(t, err)
+
+	_, err = plan.ExecIn(ctx, conn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"db_0001: INSERT INTO `orders_0002`(`tenant_id`, `order_id`, `name`) VALUES (?, ?, ?)",
+		"db_0000: INSERT INTO `orders_0003`(`tenant_id`, `order_id`, `name`) VALUES (?, ?, ?)",
+	}, writes)
+
+	t.Run("missing sharding key", func(t *testing.T) {
+		stmt, _ := parser.New().ParseOneStmt("insert into orders(tenant_id,name) select tenant_id,name from orders_tmp", "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, nil)
+		assert.NoError(t, err)
+		_, err = opt.Optimize(ctx)
+		assert.Error(t, err)
+	})
+}
+
+// makeCompositeRule creates the rule of table orders, the database is sharded by tenant_id % 2 and
+// the table is sharded by order_id % 4, eg: tenant_id=1,order_id=6 -> db_0001.orders_0002
+func makeCompositeRule() *rule.Rule {
+	mod := func(n int) rule.ShardComputer {
+		return rule.DirectShardComputer(func(value interface{}) (int, error) {
+			v, err := strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return 0, err
+			}
+			return v % n, nil
+		})
+	}
+
+	var (
+		ru   rule.Rule
+		tab  rule.VTable
+		topo rule.Topology
+	)
+	topo.SetRender(func(i int) string {
+		return fmt.Sprintf("db_%04d", i)
+	}, func(i int) string {
+		return fmt.Sprintf("orders_%04d", i)
+	})
+	topo.SetTopology(0, 0, 1, 2, 3)
+	topo.SetTopology(1, 0, 1, 2, 3)
+	tab.SetTopology(&topo)
+	tab.SetName("orders")
+	tab.SetAllowFullScan(true)
+	tab.SetShardMetadata("tenant_id", &rule.ShardMetadata{Steps: 2, Computer: mod(2)}, nil)
+	tab.SetShardMetadata("order_id", nil, &rule.ShardMetadata{Steps: 4, Computer: mod(4)})
+	ru.SetVTable("orders", &tab)
+	return &ru
+}
@@ -0,0 +1,532 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml
+
+import (
+	"context"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/cmp"
+	"github.com/arana-db/arana/pkg/runtime/logical"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+	"github.com/arana-db/arana/pkg/runtime/plan/dml"
+)
+
+// joinedTable represents a table of multi-table UPDATE/DELETE.
+type joinedTable struct {
+	source *ast.TableSourceNode
+	name   ast.TableName
+	vt     *rule.VTable // nil if it's not a sharding table
+}
+
+// alias returns the name which qualifies the columns of table.
+func (jt *joinedTable) alias() string {
+	if len(jt.source.Alias) > 0 {
+		return jt.source.Alias
+	}
+	return jt.name.Suffix()
+}
+
+// reset returns a copy of the table source with the physical table name, the logical name will be used
+// as alias if absent, so that the qualified columns are still valid.
+func (jt *joinedTable) reset(physical string) *ast.TableSourceNode {
+	ret := *jt.source
+	ret.Alias = jt.alias()
+	ret.ResetTableName(physical)
+	return &ret
+}
+
+// multiTable represents the joined tables of multi-table statement.
+type multiTable struct {
+	join   *ast.JoinNode
+	where  ast.ExpressionNode
+	tables [2]*joinedTable
+	bound  bool // true if the tables are in the same binding table group
+}
+
+func newMultiTable(ru *rule.Rule, join *ast.JoinNode, where ast.ExpressionNode) *multiTable {
+	mt := &multiTable{
+		join:  join,
+		where: where,
+	}
+	for i, source := range []*ast.TableSourceNode{join.Left, join.Right} {
+		jt := &joinedTable{
+			source: source,
+			name:   source.TableName(),
+		}
+		if jt.name != nil {
+			jt.vt, _ = ru.VTable(jt.name.Suffix())
+		}
+		mt.tables[i] = jt
+	}
+	if mt.tables[0].name != nil && mt.tables[1].name != nil {
+		mt.bound = ru.IsBindingTable(mt.tables[0].name.Suffix(), mt.tables[1].name.Suffix())
+	}
+	return mt
+}
+
+// isSharding returns true if any of the joined tables is a sharding table.
+func (mt *multiTable) isSharding() bool {
+	return mt.tables[0].vt != nil || mt.tables[1].vt != nil
+}
+
+// singleGroup returns the group of the joined single tables, empty if none of them is placed on specific group.
+func (mt *multiTable) singleGroup(ru *rule.Rule) (string, error) {
+	var ret string
+	for _, it := range mt.tables {
+		if it.name == nil {
+			continue
+		}
+		group, ok := ru.SingleTable(it.name.Suffix())
+		if !ok {
+			continue
+		}
+		if len(ret) > 0 && ret != group {
+			return "", errors.Errorf("do not support join single tables on different groups: '%s', '%s'", ret, group)
+		}
+		ret = group
+	}
+	return ret, nil
+}
+
+// table returns the joined table with the given alias or name.
+func (mt *multiTable) table(name string) *joinedTable {
+	for _, it := range mt.tables {
+		if it.name == nil && len(it.source.Alias) < 1 {
+			continue
+		}
+		if strings.EqualFold(it.alias(), name) {
+			return it
+		}
+	}
+	return nil
+}
+
+// owner returns the joined table which owns the column, nil if the column is not qualified.
+func (mt *multiTable) owner(column ast.ColumnNameExpressionAtom) *joinedTable {
+	if len(column) < 2 {
+		return nil
+	}
+	return mt.table(column[len(column)-2])
+}
+
+// colocate checks whether the sharding tables are joined by the sharding keys, and the rows with the same
+// sharding key are always stored in the physical tables with the same indexes of the same database.
+// It returns the sharding keys and the physical tables of the right table keyed by the left ones.
+// The tables must be bound, the statement won't be rewritten for each pair of physical tables otherwise.
+func (mt *multiTable) colocate() (leftKey, rightKey string, pairs map[string]string, ok bool) {
+	if !mt.bound {
+		return
+	}
+	left, right := mt.tables[0], mt.tables[1]
+	if left.vt == nil || right.vt == nil {
+		return
+	}
+
+	for _, cond := range []ast.ExpressionNode{mt.join.On, mt.where} {
+		eachConjunct(cond, func(expr ast.ExpressionNode) bool {
+			l, r, isEqual := equalColumns(expr)
+			if !isEqual {
+				return true
+			}
+			if mt.owner(l) == right && mt.owner(r) == left {
+				l, r = r, l
+			}
+			if mt.owner(l) != left || mt.owner(r) != right {
+				return true
+			}
+			if pairs, ok = colocate(left.vt, right.vt, l.Suffix(), r.Suffix()); ok {
+				leftKey, rightKey = l.Suffix(), r.Suffix()
+				return false
+			}
+			return true
+		})
+		if ok {
+			return
+		}
+	}
+	return
+}
+
+// computeShards computes the shards of left table for co-located tables.
+func (mt *multiTable) computeShards(o *optimize.Optimizer, leftKey, rightKey string) (rule.DatabaseTables, error) {
+	// the shards are computed by the column names, the conditions of right table can be used only if the
+	// sharding keys have the same name, otherwise the other columns of right table may be treated as sharding key.
+	where := mt.where
+	if leftKey != rightKey {
+		where = mt.conjunctsOf(mt.tables[0])
+	}
+	return o.ComputeShards(mt.tables[0].name, where, o.Args)
+}
+
+// conjunctsOf returns the conjuncts of WHERE which only compare the columns of given table with values.
+func (mt *multiTable) conjunctsOf(jt *joinedTable) ast.ExpressionNode {
+	var ret ast.ExpressionNode
+	eachConjunct(mt.where, func(expr ast.ExpressionNode) bool {
+		if column := keyColumn(expr); column == nil || mt.owner(column) != jt {
+			return true
+		}
+		if ret == nil {
+			ret = expr
+		} else {
+			ret = &ast.LogicalExpressionNode{
+				Op:    logical.Land,
+				Left:  ret,
+				Right: expr,
+			}
+		}
+		return true
+	})
+	return ret
+}
+
+// resetJoin returns a copy of the join with the physical tables.
+func (mt *multiTable) resetJoin(left, right string) *ast.JoinNode {
+	ret := *mt.join
+	ret.Left = mt.tables[0].reset(left)
+	ret.Right = mt.tables[1].reset(right)
+	return &ret
+}
+
+// selectTarget creates the plan which selects the primary keys, the sharding keys and the given values of
+// target rows from the joined tables, the rows are locked until the end of transaction.
+func (mt *multiTable) selectTarget(ctx context.Context, o *optimize.Optimizer, target *joinedTable, values []ast.ExpressionNode) (*dml.JoinedWritePlan, error) {
+	primaryKeys, err := getPrimaryKeys(ctx, target.vt)
+	if err != nil {
+		return nil, err
+	}
+
+	shardKeys := optimize.ShardKeys(target.vt)
+
+	// SELECT `t`.`pk`,...,`t`.`shard_key`,...,values... FROM `t` JOIN ... WHERE ... FOR UPDATE
+	sel := &ast.SelectStatement{
+		From:  ast.FromNode{ast.NewJoinTableSourceNode(mt.join)},
+		Where: mt.where,
+	}
+	for _, columns := range [][]string{primaryKeys, shardKeys} {
+		for _, column := range columns {
+			sel.Select = append(sel.Select, ast.NewSelectElementColumn([]string{target.alias(), column}, ""))
+		}
+	}
+	for _, value := range values {
+		sel.Select = append(sel.Select, newSelectElement(value))
+	}
+	sel.EnableForUpdate()
+
+	sub := &optimize.Optimizer{
+		Rule:  o.Rule,
+		Hints: o.Hints,
+		Stmt:  sel,
+		Args:  append([]proto.Value(nil), o.Args...),
+	}
+	selectPlan, err := sub.Optimize(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	route, err := newRowRouter(o, target.name, shardKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dml.JoinedWritePlan{
+		Select:      selectPlan,
+		PrimaryKeys: primaryKeys,
+		ShardKeys:   len(shardKeys),
+		Route:       route,
+	}, nil
+}
+
+// newSelectElement creates the select element of the value expression.
+func newSelectElement(value ast.ExpressionNode) ast.SelectElement {
+	if pe, ok := value.(*ast.PredicateExpressionNode); ok {
+		if ap, ok := pe.P.(*ast.AtomPredicateNode); ok {
+			if column, ok := ap.A.(ast.ColumnNameExpressionAtom); ok {
+				return ast.NewSelectElementColumn(column, "")
+			}
+		}
+	}
+	return ast.NewSelectElementExpr(value, "")
+}
+
+// optimizeMultiTableUpdate creates the plan of multi-table UPDATE.
+func optimizeMultiTableUpdate(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	var (
+		stmt = o.Stmt.(*ast.UpdateStatement)
+		mt   = newMultiTable(o.Rule, stmt.Join, stmt.Where)
+	)
+
+	// non-sharding update
+	if !mt.isSharding() {
+		group, err := mt.singleGroup(o.Rule)
+		if err != nil {
+			return nil, err
+		}
+		ret := plan.Transparent(stmt, o.Args)
+		ret.SetDB(group)
+		return ret, nil
+	}
+
+	// check update sharding key
+	for _, element := range stmt.Updated {
+		owner := mt.owner(element.Column)
+		for _, it := range mt.tables {
+			if it.vt == nil || (owner != nil && owner != it) {
+				continue
+			}
+			if _, _, ok := it.vt.GetShardMetadata(element.Column.Suffix()); ok {
+				return nil, errors.New("do not support update sharding key of multiple tables")
+			}
+			if isGlobalIndexColumn(it.vt, element.Column.Suffix()) {
+				return nil, errors.New("do not support update global index column of multiple tables")
+			}
+		}
+	}
+
+	if leftKey, rightKey, pairs, ok := mt.colocate(); ok {
+		shards, err := mt.computeShards(o, leftKey, rightKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to update")
+		}
+		if shards.IsEmpty() {
+			return plan.AlwaysEmptyExecPlan{}, nil
+		}
+		ret := &dml.ColocatedWritePlan{
+			Shards: shards,
+			Write: func(table string) ast.Statement {
+				ret := new(ast.UpdateStatement)
+				*ret = *stmt
+				ret.Join = mt.resetJoin(table, pairs[table])
+				return ret
+			},
+		}
+		ret.BindArgs(o.Args)
+		return ret, nil
+	}
+
+	// the updated columns should belong to the same sharding table
+	var (
+		target  *joinedTable
+		columns = make([]string, 0, len(stmt.Updated))
+		values  = make([]ast.ExpressionNode, 0, len(stmt.Updated))
+	)
+	for _, element := range stmt.Updated {
+		owner := mt.owner(element.Column)
+		if owner == nil {
+			return nil, errors.Errorf("column '%s' should be qualified by table in multi-table update", element.Column.Suffix())
+		}
+		if target != nil && target != owner {
+			return nil, errors.New("do not support update multiple tables which are not co-located")
+		}
+		target = owner
+		columns = append(columns, element.Column.Suffix())
+		values = append(values, element.Value)
+	}
+	if target.vt == nil {
+		return nil, errors.Errorf("do not support update non-sharding table '%s' joined with sharding table", target.name.Suffix())
+	}
+	if target.vt.IsBroadcast() {
+		return nil, errors.Errorf("do not support update broadcast table '%s' joined with other tables", target.name.Suffix())
+	}
+
+	ret, err := mt.selectTarget(ctx, o, target, values)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update")
+	}
+	ret.Updated = columns
+	ret.BindArgs(o.Args)
+
+	return ret, nil
+}
+
+// optimizeMultiTableDelete creates the plan of multi-table DELETE.
+func optimizeMultiTableDelete(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	var (
+		stmt = o.Stmt.(*ast.DeleteStatement)
+		mt   = newMultiTable(o.Rule, stmt.Join, stmt.Where)
+	)
+
+	if !mt.isSharding() {
+		group, err := mt.singleGroup(o.Rule)
+		if err != nil {
+			return nil, err
+		}
+		ret := plan.Transparent(stmt, o.Args)
+		ret.SetDB(group)
+		return ret, nil
+	}
+
+	for _, it := range stmt.Tables {
+		target := mt.table(it.Suffix())
+		if target == nil {
+			return nil, errors.Errorf("unknown table '%s' in multi-table delete", it.Suffix())
+		}
+		if target.vt != nil && len(target.vt.GlobalIndexes()) > 0 {
+			return nil, errors.Errorf("do not support multi-table delete from table '%s' with global index", it.Suffix())
+		}
+	}
+
+	if leftKey, rightKey, pairs, ok := mt.colocate(); ok {
+		shards, err := mt.computeShards(o, leftKey, rightKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to optimize DELETE statement")
+		}
+		if shards.IsEmpty() {
+			return plan.AlwaysEmptyExecPlan{}, nil
+		}
+		ret := &dml.ColocatedWritePlan{
+			Shards: shards,
+			Write: func(table string) ast.Statement {
+				ret := new(ast.DeleteStatement)
+				*ret = *stmt
+				ret.Join = mt.resetJoin(table, pairs[table])
+				return ret
+			},
+		}
+		ret.BindArgs(o.Args)
+		return ret, nil
+	}
+
+	if len(stmt.Tables) != 1 {
+		return nil, errors.New("do not support delete multiple tables which are not co-located")
+	}
+	target := mt.table(stmt.Tables[0].Suffix())
+	if target.vt == nil {
+		return nil, errors.Errorf("do not support delete non-sharding table '%s' joined with sharding table", target.name.Suffix())
+	}
+	if target.vt.IsBroadcast() {
+		return nil, errors.Errorf("do not support delete broadcast table '%s' joined with other tables", target.name.Suffix())
+	}
+
+	ret, err := mt.selectTarget(ctx, o, target, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to optimize DELETE statement")
+	}
+	ret.BindArgs(o.Args)
+
+	return ret, nil
+}
+
+// colocate checks whether the physical tables of two binding tables with the same indexes are stored in the same
+// database, returns the physical tables of right table keyed by the left ones. The co-location is never inferred from
+// the shard metadata, since the sharding scripts cannot be compared, the callers must make sure the tables are bound.
+func colocate(left, right *rule.VTable, leftKey, rightKey string) (map[string]string, bool) {
+	if _, _, ok := left.GetShardMetadata(leftKey); !ok {
+		return nil, false
+	}
+	if _, _, ok := right.GetShardMetadata(rightKey); !ok {
+		return nil, false
+	}
+
+	type index struct{ db, tb int }
+	rightIndexes := make(map[index]struct{})
+	right.Topology().Each(func(dbIdx, tbIdx int) bool {
+		rightIndexes[index{dbIdx, tbIdx}] = struct{}{}
+		return true
+	})
+
+	pairs := make(map[string]string, len(rightIndexes))
+	ok := left.Topology().Each(func(dbIdx, tbIdx int) bool {
+		if _, exist := rightIndexes[index{dbIdx, tbIdx}]; !exist {
+			return false
+		}
+		ld, lt, ok1 := left.Topology().Render(dbIdx, tbIdx)
+		rd, rt, ok2 := right.Topology().Render(dbIdx, tbIdx)
+		if !ok1 || !ok2 || ld != rd {
+			return false
+		}
+		pairs[lt] = rt
+		return true
+	})
+	if !ok || len(pairs) != len(rightIndexes) {
+		return nil, false
+	}
+
+	return pairs, true
+}
+
+// eachConjunct iterates the expressions joined by AND, stops if onEach returns false.
+func eachConjunct(expr ast.ExpressionNode, onEach func(ast.ExpressionNode) bool) bool {
+	switch it := expr.(type) {
+	case nil:
+		return true
+	case *ast.LogicalExpressionNode:
+		if it.Op == logical.Land {
+			return eachConjunct(it.Left, onEach) && eachConjunct(it.Right, onEach)
+		}
+	}
+	return onEach(expr)
+}
+
+// equalColumns returns the columns of expression like `a`.`x` = `b`.`y`.
+func equalColumns(expr ast.ExpressionNode) (left, right ast.ColumnNameExpressionAtom, ok bool) {
+	pe, ok := expr.(*ast.PredicateExpressionNode)
+	if !ok {
+		return
+	}
+	bc, ok := pe.P.(*ast.BinaryComparisonPredicateNode)
+	if !ok || bc.Op != cmp.Ceq {
+		return nil, nil, false
+	}
+	left, right = columnOf(bc.Left), columnOf(bc.Right)
+	ok = left != nil && right != nil
+	return
+}
+
+// keyColumn returns the column of expression like `a`.`x` = ?, `a`.`x` IN (...) or `a`.`x` BETWEEN ? AND ?,
+// returns nil if the column is compared with another column.
+func keyColumn(expr ast.ExpressionNode) ast.ColumnNameExpressionAtom {
+	pe, ok := expr.(*ast.PredicateExpressionNode)
+	if !ok {
+		return nil
+	}
+	switch p := pe.P.(type) {
+	case *ast.BinaryComparisonPredicateNode:
+		left, right := columnOf(p.Left), columnOf(p.Right)
+		switch {
+		case left != nil && right == nil:
+			return left
+		case left == nil && right != nil:
+			return right
+		}
+	case *ast.InPredicateNode:
+		return columnOf(p.P)
+	case *ast.BetweenPredicateNode:
+		return columnOf(p.Key)
+	}
+	return nil
+}
+
+func columnOf(node ast.PredicateNode) ast.ColumnNameExpressionAtom {
+	if atom, ok := node.(*ast.AtomPredicateNode); ok {
+		if column, ok := atom.A.(ast.ColumnNameExpressionAtom); ok {
+			return column
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeMultiTable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	loader := testdata.NewMockSchemaLoader(ctrl)
+
+	fakeStudentMetadata := map[string]*proto.TableMetadata{
+		"student_0000": {
+			Name: "student",
+			Columns: map[string]*proto.ColumnMetadata{
+				"id":       {Name: "id", DataType: "bigint", PrimaryKey: true},
+				"uid":      {Name: "uid", DataType: "bigint"},
+				"clazz_id": {Name: "clazz_id", DataType: "bigint"},
+				"level":    {Name: "level", DataType: "int"},
+			},
+			ColumnNames: []string{"id", "uid", "clazz_id", "level"},
+		},
+	}
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeStudentMetadata, nil).AnyTimes()
+
+	oldLoader := proto.LoadSchemaLoader()
+	proto.RegisterSchemaLoader(loader)
+	defer proto.RegisterSchemaLoader(oldLoader)
+
+	// student and score are bound by uid, exam is sharded by uid too but not bound, clazz is sharded by id into 2 tables
+	var ru rule.Rule
+	for _, it := range []struct {
+		table, key string
+		mod        int
+	}{
+		{"student", "uid", 4},
+		{"score", "uid", 4},
+		{"exam", "uid", 4},
+		{"clazz", "id", 2},
+	} {
+		var (
+			tab   rule.VTable
+			topo  rule.Topology
+			table = it.table
+			mod   = it.mod
+		)
+		topo.SetRender(func(_ int) string {
+			return "fake_db"
+		}, func(i int) string {
+			return fmt.Sprintf("%s_%04d", table, i)
+		})
+		tables := make([]int, 0, mod)
+		for i := 0; i < mod; i++ {
+			tables = append(tables, i)
+		}
+		topo.SetTopology(0, tables...)
+		tab.SetTopology(&topo)
+		tab.SetName(table)
+		tab.SetAllowFullScan(true)
+		tab.SetShardMetadata(it.key, nil, &rule.ShardMetadata{
+			Steps: mod,
+			Computer: rule.DirectShardComputer(func(value interface{}) (int, error) {
+				n, err := strconv.Atoi(fmt.Sprintf("%v", value))
+				if err != nil {
+					return 0, err
+				}
+				return n % mod, nil
+			}),
+		})
+		ru.SetVTable(table, &tab)
+	}
+	ru.SetBindingTables([]string{"student", "score"})
+
+	var selected [][]proto.Value
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			var fields []proto.Field
+			for i := range selected[0] {
+				fields = append(fields, mysql.NewField(fmt.Sprintf("c%d", i), consts.FieldTypeLongLong))
+			}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			for _, row := range selected {
+				ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, row))
+			}
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+
+	var execs []string
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			execs = append(execs, sql)
+			return resultx.New(resultx.WithRowsAffected(1)), nil
+		}).
+		AnyTimes()
+
+	ctx := context.Background()
+
+	for _, it := range []struct {
+		sql      string
+		args     []proto.Value
+		selected [][]proto.Value
+		expect   []string
+	}{
+		{
+			"update student s join score c on s.uid = c.uid set s.level = c.score where s.uid = ?",
+			[]proto.Value{proto.NewValueInt64(5)},
+			nil,
+			[]string{
+				"UPDATE `student_0001` AS `s` INNER JOIN `score_0001` AS `c` ON `s`.`uid` = `c`.`uid` SET `s`.`level` = `c`.`score` WHERE `s`.`uid` = ?",
+			},
+		},
+		{
+			"update student s join exam e on s.uid = e.uid set s.level = e.score where s.uid = ?",
+			[]proto.Value{proto.NewValueInt64(5)},
+			[][]proto.Value{
+				{proto.NewValueInt64(1), proto.NewValueInt64(5), proto.NewValueInt64(9)},
+			},
+			[]string{
+				"UPDATE `student_0001` SET `level` = ? WHERE `id` IN (?)",
+			},
+		},
+		{
+			"delete student from student join score on student.uid = score.uid where score.score < ?",
+			[]proto.Value{proto.NewValueInt64(60)},
+			nil,
+			[]string{
+				"DELETE `student` FROM `student_0000` AS `student` INNER JOIN `score_0000` AS `score` ON `student`.`uid` = `score`.`uid` WHERE `score`.`score` < ?",
+				"DELETE `student` FROM `student_0001` AS `student` INNER JOIN `score_0001` AS `score` ON `student`.`uid` = `score`.`uid` WHERE `score`.`score` < ?",
+				"DELETE `student` FROM `student_0002` AS `student` INNER JOIN `score_0002` AS `score` ON `student`.`uid` = `score`.`uid` WHERE `score`.`score` < ?",
+				"DELETE `student` FROM `student_0003` AS `student` INNER JOIN `score_0003` AS `score` ON `student`.`uid` = `score`.`uid` WHERE `score`.`score` < ?",
+			},
+		},
+		{
+			"update student s join clazz z on s.clazz_id = z.id set s.level = z.level + ? where z.id = ?",
+			[]proto.Value{proto.NewValueInt64(1), proto.NewValueInt64(3)},
+			[][]proto.Value{
+				{proto.NewValueInt64(1), proto.NewValueInt64(1), proto.NewValueInt64(9)},
+				{proto.NewValueInt64(1), proto.NewValueInt64(1), proto.NewValueInt64(9)}, // joined twice
+				{proto.NewValueInt64(2), proto.NewValueInt64(6), proto.NewValueInt64(9)},
+			},
+			[]string{
+				"UPDATE `student_0001` SET `level` = ? WHERE `id` IN (?)",
+				"UPDATE `student_0002` SET `level` = ? WHERE `id` IN (?)",
+			},
+		},
+		{
+			"delete s from student s join clazz z on s.clazz_id = z.id where z.level > ?",
+			[]proto.Value{proto.NewValueInt64(3)},
+			[][]proto.Value{
+				{proto.NewValueInt64(1), proto.NewValueInt64(1)},
+				{proto.NewValueInt64(2), proto.NewValueInt64(5)},
+				{proto.NewValueInt64(3), proto.NewValueInt64(6)},
+			},
+			[]string{
+				"DELETE FROM `student_0001` WHERE `id` IN (?,?)",
+				"DELETE FROM `student_0002` WHERE `id` IN (?)",
+			},
+		},
+	} {
+		t.Run(it.sql, func(t *testing.T) {
+			execs, selected = nil, it.selected
+
+			stmt, _ := parser.New().ParseOneStmt(it.sql, "", "")
+			opt, err := NewOptimizer(&ru, nil, stmt, it.args)
+			assert.NoError(t, err)
+
+			plan, err := opt.Optimize(ctx)
+			assert.NoError(t, err)
+
+			_, err = plan.ExecIn(ctx, fakeTx{conn: conn})
+			assert.NoError(t, err)
+
+			sort.Strings(execs)
+			assert.Equal(t, it.expect, execs)
+		})
+	}
+
+	t.Run("update tables which are not co-located", func(t *testing.T) {
+		stmt, _ := parser.New().ParseOneStmt("update student s join clazz z on s.clazz_id = z.id set s.level = 1, z.level = 1", "", "")
+		opt, err := NewOptimizer(&ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		_, err = opt.Optimize(ctx)
+		assert.Error(t, err)
+	})
+}
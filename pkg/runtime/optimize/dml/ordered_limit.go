@@ -0,0 +1,118 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml
+
+import (
+	"context"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan/dml"
+)
+
+// orderedLimit represents the parts of UPDATE/DELETE statement with ORDER BY and LIMIT.
+type orderedLimit struct {
+	table   ast.TableName
+	where   ast.ExpressionNode
+	orderBy ast.OrderByNode
+	limit   *ast.LimitNode
+	// write creates the statement for the physical table, without WHERE, ORDER BY and LIMIT.
+	write func(table string) ast.Statement
+}
+
+// optimizeOrderedLimit creates the plan for UPDATE/DELETE with ORDER BY and LIMIT on multiple shards,
+// the global top-n rows will be selected by the distributed select planner first.
+func optimizeOrderedLimit(ctx context.Context, o *optimize.Optimizer, vt *rule.VTable, ol *orderedLimit) (proto.Plan, error) {
+	primaryKeys, err := getPrimaryKeys(ctx, vt)
+	if err != nil {
+		return nil, err
+	}
+
+	shardKeys := optimize.ShardKeys(vt)
+
+	// SELECT `pk`,...,`shard_key`,... FROM `table` WHERE ... ORDER BY ... LIMIT ... FOR UPDATE
+	sel := &ast.SelectStatement{
+		From:    ast.FromNode{ast.NewTableSourceNode(ol.table, "")},
+		Where:   ol.where,
+		OrderBy: ol.orderBy,
+	}
+	for _, columns := range [][]string{primaryKeys, shardKeys} {
+		for _, column := range columns {
+			sel.Select = append(sel.Select, ast.NewSelectElementColumn([]string{column}, ""))
+		}
+	}
+	// the limit may be overwritten by the select planner
+	limit := *ol.limit
+	sel.Limit = &limit
+	sel.EnableForUpdate()
+
+	sub := &optimize.Optimizer{
+		Rule:  o.Rule,
+		Hints: o.Hints,
+		Stmt:  sel,
+		Args:  append([]proto.Value(nil), o.Args...),
+	}
+	selectPlan, err := sub.Optimize(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	route, err := newRowRouter(o, ol.table, shardKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &dml.OrderedLimitPlan{
+		Select:      selectPlan,
+		PrimaryKeys: primaryKeys,
+		Route:       route,
+		Write:       ol.write,
+		Where:       ol.where,
+	}
+	ret.BindArgs(o.Args)
+
+	return ret, nil
+}
+
+// getPrimaryKeys returns the primary key columns of sharding table.
+func getPrimaryKeys(ctx context.Context, vt *rule.VTable) ([]string, error) {
+	metadata, err := getMetadata(ctx, vt)
+	if err != nil {
+		return nil, err
+	}
+
+	var primaryKeys []string
+	for _, name := range metadata.ColumnNames {
+		if column, ok := metadata.Columns[name]; ok && column.PrimaryKey {
+			primaryKeys = append(primaryKeys, name)
+		}
+	}
+	if len(primaryKeys) < 1 {
+		return nil, errors.Errorf("cannot locate rows of table `%s` without primary key", vt.Name())
+	}
+
+	return primaryKeys, nil
+}
@@ -0,0 +1,237 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeOrderedLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	loader := testdata.NewMockSchemaLoader(ctrl)
+
+	fakeStudentMetadata := map[string]*proto.TableMetadata{
+		"student_0000": {
+			Name: "student",
+			Columns: map[string]*proto.ColumnMetadata{
+				"id":  {Name: "id", DataType: "bigint", PrimaryKey: true},
+				"uid": {Name: "uid", DataType: "bigint"},
+				"age": {Name: "age", DataType: "int"},
+			},
+			ColumnNames: []string{"id", "uid", "age"},
+		},
+	}
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeStudentMetadata, nil).AnyTimes()
+
+	oldLoader := proto.LoadSchemaLoader()
+	proto.RegisterSchemaLoader(loader)
+	defer proto.RegisterSchemaLoader(oldLoader)
+
+	// student_0001: age=30, student_0002: age=10, student_0003: age=20
+	ages := map[string]int64{"student_0001": 30, "student_0002": 10, "student_0003": 20}
+
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			fields := []proto.Field{
+				mysql.NewField("id", consts.FieldTypeLongLong),
+				mysql.NewField("uid", consts.FieldTypeLongLong),
+				mysql.NewField("age", consts.FieldTypeLong),
+			}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			// ordered by age desc
+			for _, i := range []int64{1, 3, 2} {
+				table := fmt.Sprintf("student_%04d", i)
+				if strings.Contains(sql, "`"+table+"`") {
+					ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{
+						proto.NewValueInt64(i),
+						proto.NewValueInt64(i),
+						proto.NewValueInt64(ages[table]),
+					}))
+				}
+			}
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+
+	var execs []string
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			execs = append(execs, sql)
+			return resultx.New(resultx.WithRowsAffected(1)), nil
+		}).
+		AnyTimes()
+
+	var (
+		ctx = context.Background()
+		ru  = makeFakeRule(ctrl, 8)
+	)
+
+	for _, it := range []struct {
+		sql    string
+		expect []string
+	}{
+		{
+			"delete from student where uid in (?,?,?) order by age desc limit ?",
+			[]string{
+				"DELETE FROM `student_0001` WHERE (`uid` IN (?,?,?)) AND `id` IN (?)",
+				"DELETE FROM `student_0003` WHERE (`uid` IN (?,?,?)) AND `id` IN (?)",
+			},
+		},
+		{
+			"update student set age = age + 1 where uid in (?,?,?) order by age desc limit ?",
+			[]string{
+				"UPDATE `student_0001` SET `age` = `age`+1 WHERE (`uid` IN (?,?,?)) AND `id` IN (?)",
+				"UPDATE `student_0003` SET `age` = `age`+1 WHERE (`uid` IN (?,?,?)) AND `id` IN (?)",
+			},
+		},
+	} {
+		t.Run(it.sql, func(t *testing.T) {
+			execs = nil
+
+			stmt, _ := parser.New().ParseOneStmt(it.sql, "", "")
+			opt, err := NewOptimizer(ru, nil, stmt, []proto.Value{
+				proto.NewValueInt64(1),
+				proto.NewValueInt64(2),
+				proto.NewValueInt64(3),
+				proto.NewValueInt64(2),
+			})
+			assert.NoError(t, err)
+
+			plan, err := opt.Optimize(ctx)
+			assert.NoError(t, err)
+
+			res, err := plan.ExecIn(ctx, fakeTx{conn: conn})
+			assert.NoError(t, err)
+
+			affected, _ := res.RowsAffected()
+			assert.Equal(t, uint64(2), affected)
+			sort.Strings(execs)
+			assert.Equal(t, it.expect, execs)
+		})
+	}
+}
+
+func TestOptimizer_OptimizeOrderedLimitCompositeShardKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	loader := testdata.NewMockSchemaLoader(ctrl)
+
+	fakeOrdersMetadata := map[string]*proto.TableMetadata{
+		"orders_0000": {
+			Name: "orders",
+			Columns: map[string]*proto.ColumnMetadata{
+				"id":        {Name: "id", DataType: "bigint", PrimaryKey: true},
+				"tenant_id": {Name: "tenant_id", DataType: "bigint"},
+				"order_id":  {Name: "order_id", DataType: "bigint"},
+			},
+			ColumnNames: []string{"id", "tenant_id", "order_id"},
+		},
+	}
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeOrdersMetadata, nil).AnyTimes()
+
+	oldLoader := proto.LoadSchemaLoader()
+	proto.RegisterSchemaLoader(loader)
+	defer proto.RegisterSchemaLoader(oldLoader)
+
+	// the sharding keys are selected in order: order_id, tenant_id
+	stored := map[string][]int64{
+		"db_0001.orders_0002": {1, 6, 1},
+		"db_0000.orders_0003": {2, 3, 2},
+	}
+
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			assert.True(t, strings.HasPrefix(sql, "(SELECT `id`,`order_id`,`tenant_id` FROM"))
+			fields := []proto.Field{
+				mysql.NewField("id", consts.FieldTypeLongLong),
+				mysql.NewField("order_id", consts.FieldTypeLongLong),
+				mysql.NewField("tenant_id", consts.FieldTypeLongLong),
+			}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			for i := 0; i < 4; i++ {
+				table := fmt.Sprintf("orders_%04d", i)
+				if row, ok := stored[db+"."+table]; ok && strings.Contains(sql, "`"+table+"`") {
+					ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{
+						proto.NewValueInt64(row[0]),
+						proto.NewValueInt64(row[1]),
+						proto.NewValueInt64(row[2]),
+					}))
+				}
+			}
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+
+	var execs []string
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			execs = append(execs, db+": "+sql)
+			return resultx.New(resultx.WithRowsAffected(1)), nil
+		}).
+		AnyTimes()
+
+	ctx := context.Background()
+
+	stmt, _ := parser.New().ParseOneStmt("delete from orders order by id limit ?", "", "")
+	opt, err := NewOptimizer(makeCompositeRule(), nil, stmt, []proto.Value{proto.NewValueInt64(2)})
+	assert.NoError(t, err)
+
+	plan, err := opt.Optimize(ctx)
+	assert.NoError(t, err)
+
+	_, err = plan.ExecIn(ctx, fakeTx{conn: conn})
+	assert.NoError(t, err)
+
+	sort.Strings(execs)
+	assert.Equal(t, []string{
+		"db_0000: DELETE FROM `orders_0003` WHERE `id` IN (?)",
+		"db_0001: DELETE FROM `orders_0002` WHERE `id` IN (?)",
+	}, execs)
+}
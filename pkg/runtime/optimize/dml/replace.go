@@ -0,0 +1,169 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dml
+
+import (
+	"context"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan/dml"
+)
+
+func init() {
+	optimize.Register(ast.SQLTypeReplace, optimizeReplace)
+}
+
+// optimizeReplace routes the rows of REPLACE by the shard key like INSERT.
+//
+// NOTICE: the conflicting row is only searched in the routed physical table, so the unique keys
+// of sharding table should contain the shard key, otherwise the old row on another shard won't be removed.
+func optimizeReplace(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+	switch stmt := o.Stmt.(type) {
+	case *ast.ReplaceStatement:
+		return optimizeReplaceValues(ctx, o, stmt)
+	case *ast.ReplaceSelectStatement:
+		return optimizeReplaceSelect(ctx, o, stmt)
+	default:
+		return nil, errors.Errorf("optimize: unsupported replace statement %T", stmt)
+	}
+}
+
+func optimizeReplaceValues(ctx context.Context, o *optimize.Optimizer, stmt *ast.ReplaceStatement) (proto.Plan, error) {
+	ret := dml.NewSimpleInsertPlan()
+	ret.BindArgs(o.Args)
+
+	vt, ok := o.Rule.VTable(stmt.Table.Suffix())
+	if !ok { // replace into non-sharding table
+		group, _ := o.Rule.SingleTable(stmt.Table.Suffix())
+		ret.Put(group, stmt)
+		return ret, nil
+	}
+
+	if vt.IsBroadcast() {
+		ret, err := newBroadcastInsert(ctx, o, vt, stmt, &stmt.Columns, stmt.Values)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to replace")
+		}
+		return ret, nil
+	}
+
+	// generate the distributed primary key before routing, it may be the sharding key
+	lastInsertID, err := generateInsertIDs(ctx, vt, &stmt.Columns, stmt.Values)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to replace")
+	}
+	ret.SetLastInsertID(lastInsertID)
+
+	bingo := findShardKeys(vt, stmt.Columns)
+	if len(bingo) < 1 {
+		return nil, errors.Wrap(optimize.ErrNoShardKeyFound, "failed to replace")
+	}
+
+	slots, err := shardValues(o, stmt.Table, stmt.Columns, bingo, stmt.Values)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to replace")
+	}
+
+	for db, slot := range slots {
+		for table, indexes := range slot {
+			// clone replace stmt without values
+			newborn := ast.NewReplaceStatement(ast.TableName{table}, stmt.Columns)
+			newborn.SetFlag(stmt.Flag())
+
+			// collect values with same table
+			values := make([][]ast.ExpressionNode, 0, len(indexes))
+			for _, i := range indexes {
+				values = append(values, stmt.Values[i])
+			}
+			newborn.Values = values
+
+			ret.Put(db, newborn)
+		}
+	}
+
+	if len(vt.GlobalIndexes()) > 0 {
+		return newGlobalIndexInsert(o, vt, ret, stmt.Columns, stmt.Values, slots)
+	}
+
+	return ret, nil
+}
+
+func optimizeReplaceSelect(ctx context.Context, o *optimize.Optimizer, stmt *ast.ReplaceSelectStatement) (proto.Plan, error) {
+	vt, ok := o.Rule.VTable(stmt.Table.Suffix())
+	if !ok { // replace into non-sharding table
+		ret := dml.NewSimpleInsertPlan()
+		ret.BindArgs(o.Args)
+		group, _ := o.Rule.SingleTable(stmt.Table.Suffix())
+		ret.Put(group, stmt)
+		return ret, nil
+	}
+
+	if vt.IsBroadcast() {
+		return nil, errors.New("not support replace-select into broadcast table")
+	}
+
+	if len(vt.GlobalIndexes()) > 0 {
+		return nil, errors.New("not support replace-select into table with global index")
+	}
+
+	ret, columns, err := newShardedInsertSelectPlan(ctx, o, vt, stmt.Table, stmt.Columns, stmt.Select)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to replace")
+	}
+
+	flag := stmt.Flag()
+	ret.Write = func(table string, values [][]ast.ExpressionNode) ast.Statement {
+		ret := ast.NewReplaceStatement(ast.TableName{table}, columns)
+		ret.SetFlag(flag)
+		ret.Values = values
+		return ret
+	}
+
+	return ret, nil
+}
+
+// shardedInsertColumns returns the columns to be written, all columns of table will be used if no columns specified.
+func shardedInsertColumns(ctx context.Context, vt *rule.VTable, columns []string) ([]string, error) {
+	if len(columns) > 0 {
+		return columns, nil
+	}
+	metadata, err := getMetadata(ctx, vt)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.ColumnNames, nil
+}
+
+// optimizeSubSelect optimizes the select part of INSERT/REPLACE ... SELECT.
+func optimizeSubSelect(ctx context.Context, o *optimize.Optimizer, sel *ast.SelectStatement) (proto.Plan, error) {
+	sub := &optimize.Optimizer{
+		Rule:  o.Rule,
+		Hints: o.Hints,
+		Stmt:  sel,
+		Args:  o.Args,
+	}
+	return sub.Optimize(ctx)
+}
@@ -0,0 +1,181 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeReplace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	loader := testdata.NewMockSchemaLoader(ctrl)
+
+	fakeStudentMetadata := map[string]*proto.TableMetadata{
+		"student_0000": {
+			Name: "student",
+			Columns: map[string]*proto.ColumnMetadata{
+				"name": {Name: "name", DataType: "varchar"},
+				"uid":  {Name: "uid", DataType: "bigint"},
+			},
+			ColumnNames: []string{"name", "uid"},
+		},
+	}
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeStudentMetadata, nil).AnyTimes()
+
+	oldLoader := proto.LoadSchemaLoader()
+	proto.RegisterSchemaLoader(loader)
+	defer proto.RegisterSchemaLoader(oldLoader)
+
+	var writes map[string]int // table -> rows
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			assert.True(t, strings.HasPrefix(sql, "REPLACE INTO "))
+			for i := 0; i < 8; i++ {
+				table := fmt.Sprintf("student_%04d", i)
+				if strings.Contains(sql, "`"+table+"`") {
+					writes[table] += strings.Count(sql, "(") - 1
+				}
+			}
+			// replaced rows are counted twice
+			return resultx.New(resultx.WithRowsAffected(2)), nil
+		}).
+		AnyTimes()
+
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			fields := []proto.Field{
+				mysql.NewField("name", consts.FieldTypeVarChar),
+				mysql.NewField("uid", consts.FieldTypeLongLong),
+			}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			for _, uid := range []int64{8, 9, 16} {
+				ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{
+					proto.NewValueString(fmt.Sprintf("name_%d", uid)),
+					proto.NewValueInt64(uid),
+				}))
+			}
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+
+	var (
+		ctx = context.Background()
+		ru  = makeFakeRule(ctrl, 8)
+	)
+
+	for _, sql := range []string{
+		"replace into student(name,uid) values('foo',?),('bar',?),('qux',?)",
+		"replace into student(name,uid) select name,uid from student_tmp where uid > ?",
+		"replace into student select name,uid from student_tmp where uid > ?",
+	} {
+		t.Run(sql, func(t *testing.T) {
+			writes = make(map[string]int)
+
+			stmt, _ := parser.New().ParseOneStmt(sql, "", "")
+			opt, err := NewOptimizer(ru, nil, stmt, []proto.Value{
+				proto.NewValueInt64(8),
+				proto.NewValueInt64(9),
+				proto.NewValueInt64(16),
+			})
+			assert.NoError(t, err)
+
+			plan, err := opt.Optimize(ctx) // 8,16 -> student_0000, 9 -> student_0001
+			assert.NoError(t, err)
+
+			res, err := plan.ExecIn(ctx, conn)
+			assert.NoError(t, err)
+
+			affected, _ := res.RowsAffected()
+			assert.Equal(t, uint64(4), affected)
+			assert.Equal(t, map[string]int{"student_0000": 2, "student_0001": 1}, writes)
+		})
+	}
+}
+
+func makeFakeRule(c *gomock.Controller, mod int) *rule.Rule {
+	var (
+		ru   rule.Rule
+		tab  rule.VTable
+		topo rule.Topology
+	)
+
+	topo.SetRender(func(_ int) string {
+		return "fake_db"
+	}, func(i int) string {
+		return fmt.Sprintf("student_%04d", i)
+	})
+
+	tables := make([]int, 0, mod)
+	for i := 0; i < mod; i++ {
+		tables = append(tables, i)
+	}
+	topo.SetTopology(0, tables...)
+
+	tab.SetTopology(&topo)
+	tab.SetName("student")
+
+	computer := testdata.NewMockShardComputer(c)
+
+	computer.EXPECT().
+		Compute(gomock.Any()).
+		DoAndReturn(func(value interface{}) (int, error) {
+			n, err := strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return 0, err
+			}
+			return n % mod, nil
+		}).
+		MinTimes(1)
+
+	var sm rule.ShardMetadata
+	sm.Steps = 8
+	sm.Computer = computer
+
+	tab.SetShardMetadata("uid", nil, &sm)
+	ru.SetVTable("student", &tab)
+	return &ru
+}
@@ -19,6 +19,7 @@ package dml
 
 import (
 	"context"
+	"sort"
 	"strings"
 )
 
@@ -52,11 +53,25 @@ func init() {
 func optimizeSelect(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
 	stmt := o.Stmt.(*ast.SelectStatement)
 
+	// read the broadcast table from any single database
+	if len(stmt.From) == 1 {
+		if vt := broadcastTable(o.Rule, stmt.From[0]); vt != nil {
+			return optimizeBroadcastSelect(ctx, o, stmt, vt)
+		}
+	}
+
+	// look up the global index if no sharding key found
+	if len(stmt.From) == 1 && !stmt.HasJoin() {
+		if ret, err := optimizeGlobalIndexLookup(o, proto.PlanTypeQuery, stmt.From[0].TableName(), stmt.Where, optimizeSelect); ret != nil || err != nil {
+			return ret, err
+		}
+	}
+
 	// overwrite stmt limit x offset y. eg `select * from student offset 100 limit 5` will be
 	// `select * from student offset 0 limit 100+5`
 	originOffset, newLimit := overwriteLimit(stmt, &o.Args)
 	if stmt.HasJoin() {
-		return optimizeJoin(o, stmt)
+		return optimizeJoin(ctx, o, stmt, originOffset, newLimit)
 	}
 	flag := getSelectFlag(o.Rule, stmt)
 	if flag&_supported == 0 {
@@ -64,20 +79,26 @@ func optimizeSelect(ctx context.Context, o *optimize.Optimizer) (proto.Plan, err
 	}
 
 	if flag&_bypass != 0 {
+		var group string
 		if len(stmt.From) > 0 {
 			err := rewriteSelectStatement(ctx, stmt, stmt.From[0].TableName().Suffix())
 			if err != nil {
 				return nil, err
 			}
+			group, _ = o.Rule.SingleTable(stmt.From[0].TableName().Suffix())
 		}
-		ret := &dml.SimpleQueryPlan{Stmt: stmt}
-		ret.BindArgs(o.Args)
-
 		normalizedFields := make([]string, 0, len(stmt.Select))
 		for i := range stmt.Select {
 			normalizedFields = append(normalizedFields, stmt.Select[i].DisplayName())
 		}
 
+		if len(stmt.From) < 1 {
+			rewriteLastInsertID(ctx, stmt)
+		}
+
+		ret := &dml.SimpleQueryPlan{Database: group, Stmt: stmt}
+		ret.BindArgs(o.Args)
+
 		return &dml.RenamePlan{
 			Plan:       ret,
 			RenameList: normalizedFields,
@@ -191,10 +212,18 @@ func optimizeSelect(ctx context.Context, o *optimize.Optimizer) (proto.Plan, err
 		plans = append(plans, next)
 	}
 
-	var tmpPlan proto.Plan
-	tmpPlan = &dml.CompositePlan{
-		Plans: plans,
-	}
+	return mergeShardPlans(stmt, plans, &analysis, originOffset, newLimit)
+}
+
+// mergeShardPlans merges the results of the plans on multiple shards, the ORDER BY, GROUP BY, aggregate functions
+// and LIMIT will be computed again after merging.
+func mergeShardPlans(stmt *ast.SelectStatement, plans []proto.Plan, analysis *selectResult, originOffset, newLimit int64) (proto.Plan, error) {
+	var (
+		tmpPlan proto.Plan = &dml.CompositePlan{
+			Plans: plans,
+		}
+		err error
+	)
 
 	// check if order-by exists
 	if len(analysis.orders) > 0 {
@@ -354,10 +383,18 @@ func handleGroupBy(parentPlan proto.Plan, stmt *ast.SelectStatement) (proto.Plan
 	return groupPlan, nil
 }
 
-// optimizeJoin ony support  a join b in one db
-func optimizeJoin(o *optimize.Optimizer, stmt *ast.SelectStatement) (proto.Plan, error) {
+// optimizeJoin ony support  a join b in one db, or the co-located tables joined by sharding keys
+func optimizeJoin(ctx context.Context, o *optimize.Optimizer, stmt *ast.SelectStatement, originOffset, newLimit int64) (proto.Plan, error) {
 	join := stmt.From[0].Source().(*ast.JoinNode)
 
+	if ret, ok, err := optimizeBroadcastJoin(ctx, o, stmt, join); ok {
+		return ret, err
+	}
+
+	if ret, ok, err := optimizeColocatedJoin(o, stmt, join, originOffset, newLimit); ok {
+		return ret, err
+	}
+
 	compute := func(tableSource *ast.TableSourceNode) (database, alias string, shardList []string, err error) {
 		table := tableSource.TableName()
 		if table == nil {
@@ -365,13 +402,12 @@ func optimizeJoin(o *optimize.Optimizer, stmt *ast.SelectStatement) (proto.Plan,
 			return
 		}
 		alias = tableSource.Alias
-		database = table.Prefix()
 
 		shards, err := o.ComputeShards(table, nil, o.Args)
 		if err != nil {
 			return
 		}
-		// table no shard
+		// table no shard, it's stored in the default database
 		if shards == nil {
 			shardList = append(shardList, table.Suffix())
 			return
@@ -407,7 +443,13 @@ func optimizeJoin(o *optimize.Optimizer, stmt *ast.SelectStatement) (proto.Plan,
 		return nil, errors.New("not support more than one db")
 	}
 
+	database := dbLeft
+	if database == "" {
+		database = dbRight
+	}
+
 	joinPan := &dml.SimpleJoinPlan{
+		Database: database,
 		Left: &dml.JoinTable{
 			Tables: shardLeft,
 			Alias:  aliasLeft,
@@ -424,6 +466,69 @@ func optimizeJoin(o *optimize.Optimizer, stmt *ast.SelectStatement) (proto.Plan,
 	return joinPan, nil
 }
 
+// optimizeColocatedJoin creates the plan of the co-located tables joined by sharding keys, eg: the binding tables.
+// The join will be executed in each pair of physical tables, then the results will be merged.
+func optimizeColocatedJoin(o *optimize.Optimizer, stmt *ast.SelectStatement, join *ast.JoinNode, originOffset, newLimit int64) (proto.Plan, bool, error) {
+	mt := newMultiTable(o.Rule, join, stmt.Where)
+	// only the binding tables can be joined in each pair of physical tables
+	if !mt.bound {
+		return nil, false, nil
+	}
+	leftKey, rightKey, pairs, ok := mt.colocate()
+	if !ok {
+		return nil, false, nil
+	}
+
+	shards, err := mt.computeShards(o, leftKey, rightKey)
+	if err != nil {
+		return nil, true, errors.WithStack(err)
+	}
+
+	// Go through first shard if no shards matched.
+	if shards.IsEmpty() {
+		db0, tbl0, ok := mt.tables[0].vt.Topology().Render(0, 0)
+		if !ok {
+			return nil, true, errors.Errorf("cannot compute minimal topology from '%s'", mt.tables[0].name.Suffix())
+		}
+		shards = rule.DatabaseTables{db0: []string{tbl0}}
+	}
+
+	var (
+		analysis selectResult
+		scanner  = newSelectScanner(stmt, o.Args)
+	)
+	if err = scanner.scan(&analysis); err != nil {
+		return nil, true, errors.WithStack(err)
+	}
+
+	dbs := make([]string, 0, len(shards))
+	for db := range shards {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	plans := make([]proto.Plan, 0, len(shards))
+	for _, db := range dbs {
+		tables := shards[db]
+		sort.Strings(tables)
+		for _, table := range tables {
+			next := &dml.SimpleQueryPlan{
+				Database: db,
+				Stmt:     stmt,
+				Join:     mt.resetJoin(table, pairs[table]),
+			}
+			next.BindArgs(o.Args)
+			plans = append(plans, next)
+		}
+	}
+
+	ret, err := mergeShardPlans(stmt, plans, &analysis, originOffset, newLimit)
+	if err != nil {
+		return nil, true, errors.WithStack(err)
+	}
+	return ret, true, nil
+}
+
 func getSelectFlag(ru *rule.Rule, stmt *ast.SelectStatement) (flag uint32) {
 	switch len(stmt.From) {
 	case 1:
@@ -543,3 +648,23 @@ func rewriteSelectStatement(ctx context.Context, stmt *ast.SelectStatement, tb s
 
 	return nil
 }
+
+// rewriteLastInsertID replaces LAST_INSERT_ID() with the last insert id of current connection,
+// because the backend connection doesn't know the id generated by arana.
+func rewriteLastInsertID(ctx context.Context, stmt *ast.SelectStatement) {
+	for i, sel := range stmt.Select {
+		f, ok := sel.(*ast.SelectElementFunction)
+		if !ok {
+			continue
+		}
+		fn, ok := f.Function().(*ast.Function)
+		if !ok || len(fn.Args()) > 0 || !strings.EqualFold(fn.Name(), "LAST_INSERT_ID") {
+			continue
+		}
+		stmt.Select[i] = ast.NewSelectElementExpr(&ast.PredicateExpressionNode{
+			P: &ast.AtomPredicateNode{
+				A: &ast.ConstantExpressionAtom{Inner: rcontext.LastInsertID(ctx)},
+			},
+		}, f.Alias())
+	}
+}
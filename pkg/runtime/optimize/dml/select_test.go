@@ -0,0 +1,214 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/pkg/runtime/plan/dml"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeBindingJoin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+
+	// orders and order_item are both sharded by order id into 4 tables, but the shard computers
+	// are different, so they are co-located only if they are bound.
+	var ru rule.Rule
+	for _, it := range []struct {
+		table, key string
+		computer   rule.ShardComputer
+	}{
+		{"orders", "id", rule.DirectShardComputer(func(value interface{}) (int, error) {
+			n, err := strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return 0, err
+			}
+			return n % 4, nil
+		})},
+		{"order_item", "order_id", rule.DirectShardComputer(func(value interface{}) (int, error) {
+			n, err := strconv.Atoi(fmt.Sprintf("%v", value))
+			if err != nil {
+				return 0, err
+			}
+			if n < 1 {
+				return 0, errors.Errorf("invalid order id %d", n)
+			}
+			return n % 4, nil
+		})},
+	} {
+		var (
+			tab   rule.VTable
+			topo  rule.Topology
+			table = it.table
+		)
+		topo.SetRender(func(_ int) string {
+			return "fake_db"
+		}, func(i int) string {
+			return fmt.Sprintf("%s_%04d", table, i)
+		})
+		topo.SetTopology(0, 0, 1, 2, 3)
+		tab.SetTopology(&topo)
+		tab.SetName(table)
+		tab.SetAllowFullScan(true)
+		tab.SetShardMetadata(it.key, nil, &rule.ShardMetadata{
+			Steps:    4,
+			Computer: it.computer,
+		})
+		ru.SetVTable(table, &tab)
+	}
+	ru.SetBindingTables([]string{"orders", "order_item"})
+
+	var calls []string
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			calls = append(calls, sql)
+			fields := []proto.Field{
+				mysql.NewField("id", consts.FieldTypeLongLong),
+				mysql.NewField("sku", consts.FieldTypeVarChar),
+			}
+			n := len(calls)
+			ds := &dataset.VirtualDataset{Columns: fields}
+			ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{
+				proto.NewValueInt64(int64(n)),
+				proto.NewValueString(fmt.Sprintf("sku_%d", n)),
+			}))
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+
+	ctx := context.Background()
+
+	for _, it := range []struct {
+		sql    string
+		args   []proto.Value
+		expect []string
+		rows   int
+	}{
+		{
+			"select o.id, i.sku from orders o join order_item i on o.id = i.order_id where o.id = ?",
+			[]proto.Value{proto.NewValueInt64(7)},
+			[]string{
+				"SELECT `o`.`id`,`i`.`sku` FROM `orders_0003` AS `o` INNER JOIN `order_item_0003` AS `i` ON `o`.`id` = `i`.`order_id` WHERE `o`.`id` = ?",
+			},
+			1,
+		},
+		{
+			"select o.id, i.sku from orders o join order_item i on o.id = i.order_id order by o.id desc limit 2",
+			nil,
+			[]string{
+				"SELECT `o`.`id`,`i`.`sku` FROM `orders_0000` AS `o` INNER JOIN `order_item_0000` AS `i` ON `o`.`id` = `i`.`order_id` ORDER BY `o`.`id` DESC LIMIT 2",
+				"SELECT `o`.`id`,`i`.`sku` FROM `orders_0001` AS `o` INNER JOIN `order_item_0001` AS `i` ON `o`.`id` = `i`.`order_id` ORDER BY `o`.`id` DESC LIMIT 2",
+				"SELECT `o`.`id`,`i`.`sku` FROM `orders_0002` AS `o` INNER JOIN `order_item_0002` AS `i` ON `o`.`id` = `i`.`order_id` ORDER BY `o`.`id` DESC LIMIT 2",
+				"SELECT `o`.`id`,`i`.`sku` FROM `orders_0003` AS `o` INNER JOIN `order_item_0003` AS `i` ON `o`.`id` = `i`.`order_id` ORDER BY `o`.`id` DESC LIMIT 2",
+			},
+			2,
+		},
+	} {
+		t.Run(it.sql, func(t *testing.T) {
+			calls = nil
+
+			stmt, _ := parser.New().ParseOneStmt(it.sql, "", "")
+			opt, err := NewOptimizer(&ru, nil, stmt, it.args)
+			assert.NoError(t, err)
+
+			plan, err := opt.Optimize(ctx)
+			assert.NoError(t, err)
+
+			res, err := plan.ExecIn(ctx, fakeTx{conn: conn})
+			assert.NoError(t, err)
+
+			ds, err := res.Dataset()
+			assert.NoError(t, err)
+
+			var cnt int
+			for {
+				if _, err = ds.Next(); err != nil {
+					break
+				}
+				cnt++
+			}
+			assert.Equal(t, io.EOF, err)
+			assert.Equal(t, it.rows, cnt)
+
+			sort.Strings(calls)
+			assert.Equal(t, it.expect, calls)
+		})
+	}
+
+	t.Run("join tables which are not bound", func(t *testing.T) {
+		var unbound rule.Rule
+		for k, v := range ru.VTables() {
+			unbound.SetVTable(k, v)
+		}
+
+		stmt, _ := parser.New().ParseOneStmt("select o.id, i.sku from orders o join order_item i on o.id = i.order_id where o.id = 7", "", "")
+		opt, err := NewOptimizer(&unbound, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+		_, ok := plan.(*dml.SimpleJoinPlan)
+		assert.True(t, ok)
+	})
+
+	t.Run("join tables with the same shard metadata which are not bound", func(t *testing.T) {
+		var unbound rule.Rule
+		orders := ru.MustVTable("orders")
+		unbound.SetVTable("orders", orders)
+		unbound.SetVTable("orders_copy", orders)
+
+		stmt, _ := parser.New().ParseOneStmt("select o.id, c.id from orders o join orders_copy c on o.id = c.id where o.id = 7", "", "")
+		opt, err := NewOptimizer(&unbound, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+		_, ok := plan.(*dml.SimpleJoinPlan)
+		assert.True(t, ok)
+	})
+}
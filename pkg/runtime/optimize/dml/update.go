@@ -38,7 +38,7 @@ func init() {
 	optimize.Register(ast.SQLTypeUpdate, optimizeUpdate)
 }
 
-func optimizeUpdate(_ context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+func optimizeUpdate(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
 	var (
 		stmt  = o.Stmt.(*ast.UpdateStatement)
 		table = stmt.Table
@@ -46,17 +46,46 @@ func optimizeUpdate(_ context.Context, o *optimize.Optimizer) (proto.Plan, error
 		ok    bool
 	)
 
+	if stmt.IsMultiTable() {
+		return optimizeMultiTableUpdate(ctx, o)
+	}
+
 	// non-sharding update
 	if vt, ok = o.Rule.VTable(table.Suffix()); !ok {
 		ret := dml.NewUpdatePlan(stmt)
 		ret.BindArgs(o.Args)
+		if group, ok := o.Rule.SingleTable(table.Suffix()); ok {
+			ret.SetShards(rule.DatabaseTables{group: []string{table.Suffix()}})
+		}
 		return ret, nil
 	}
 
+	if vt.IsBroadcast() {
+		return newBroadcastWrite(o, vt, stmt), nil
+	}
+
+	if ret, err := optimizeGlobalIndexLookup(o, proto.PlanTypeExec, table, stmt.Where, optimizeUpdate); ret != nil || err != nil {
+		return ret, err
+	}
+
 	// check update sharding key
+	var (
+		shardKey string
+		indexed  bool // whether the column of global index is updated
+	)
 	for _, element := range stmt.Updated {
 		if _, _, ok := vt.GetShardMetadata(element.Column.Suffix()); ok {
-			return nil, errors.New("do not support update sharding key")
+			if !vt.AllowUpdateShardKey() {
+				return nil, errors.New("do not support update sharding key")
+			}
+			if len(vt.GlobalIndexes()) > 0 {
+				return nil, errors.New("do not support update sharding key of table with global index")
+			}
+			shardKey = element.Column.Suffix()
+			break
+		}
+		if isGlobalIndexColumn(vt, element.Column.Suffix()) {
+			indexed = true
 		}
 	}
 
@@ -72,6 +101,7 @@ func optimizeUpdate(_ context.Context, o *optimize.Optimizer) (proto.Plan, error
 			if shards, err = optimize.Hints(table, o.Hints, o.Rule); err != nil {
 				return nil, errors.Wrap(err, "calculate hints failed")
 			}
+			fullScan = shards == nil
 		}
 
 		if shards == nil {
@@ -98,9 +128,77 @@ func optimizeUpdate(_ context.Context, o *optimize.Optimizer) (proto.Plan, error
 		shards = vt.Topology().Enumerate()
 	}
 
-	ret := dml.NewUpdatePlan(stmt)
-	ret.BindArgs(o.Args)
-	ret.SetShards(shards)
+	// the ORDER BY and LIMIT should be applied to all shards
+	multiple := stmt.Limit != nil && shards.Len() > 1
+
+	if len(shardKey) > 0 {
+		if multiple {
+			return nil, errors.New("do not support update sharding key with LIMIT on multiple shards")
+		}
+		mover, err := newShardKeyMover(ctx, o, vt, table)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to update")
+		}
+		for _, element := range stmt.Updated {
+			for _, pk := range mover.PrimaryKeys {
+				if element.Column.Suffix() == pk {
+					return nil, errors.New("do not support update primary key and sharding key together")
+				}
+			}
+		}
+		ret := dml.NewShardKeyUpdatePlan(stmt, shards, mover)
+		ret.BindArgs(o.Args)
+		return ret, nil
+	}
+
+	var ret proto.Plan
+
+	if multiple {
+		ret, err = optimizeOrderedLimit(ctx, o, vt, &orderedLimit{
+			table:   table,
+			where:   stmt.Where,
+			orderBy: stmt.OrderBy,
+			limit:   stmt.Limit,
+			write: func(physical string) ast.Statement {
+				ret := stmt.ResetTable(physical)
+				ret.Where, ret.OrderBy, ret.Limit = nil, nil, nil
+				return ret
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		simple := dml.NewUpdatePlan(stmt)
+		simple.BindArgs(o.Args)
+		simple.SetShards(shards)
+		ret = simple
+	}
+
+	// move the entries of updated rows in the global indexes
+	if indexed {
+		return newGlobalIndexWrite(o, vt, ret, stmt.Where, shards)
+	}
 
 	return ret, nil
 }
+
+// newShardKeyMover creates the mover which moves the rows between shards after the sharding key changed.
+func newShardKeyMover(ctx context.Context, o *optimize.Optimizer, vt *rule.VTable, table ast.TableName) (*dml.ShardKeyMover, error) {
+	primaryKeys, err := getPrimaryKeys(ctx, vt)
+	if err != nil {
+		return nil, err
+	}
+
+	shardKeys := optimize.ShardKeys(vt)
+	route, err := newRowRouter(o, table, shardKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dml.ShardKeyMover{
+		PrimaryKeys: primaryKeys,
+		ShardKeys:   shardKeys,
+		Route:       route,
+	}, nil
+}
@@ -0,0 +1,390 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeUpdateShardKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	loader := testdata.NewMockSchemaLoader(ctrl)
+
+	fakeStudentMetadata := map[string]*proto.TableMetadata{
+		"student_0000": {
+			Name: "student",
+			Columns: map[string]*proto.ColumnMetadata{
+				"id":   {Name: "id", DataType: "bigint", PrimaryKey: true},
+				"name": {Name: "name", DataType: "varchar"},
+				"uid":  {Name: "uid", DataType: "bigint"},
+			},
+			ColumnNames: []string{"id", "name", "uid"},
+		},
+	}
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeStudentMetadata, nil).AnyTimes()
+
+	oldLoader := proto.LoadSchemaLoader()
+	proto.RegisterSchemaLoader(loader)
+	defer proto.RegisterSchemaLoader(oldLoader)
+
+	var (
+		ctx = context.Background()
+		ru  = makeFakeRule(ctrl, 8)
+	)
+
+	t.Run("disabled", func(t *testing.T) {
+		stmt, _ := parser.New().ParseOneStmt("update student set uid = ? where uid = ?", "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, []proto.Value{proto.NewValueInt64(9), proto.NewValueInt64(8)})
+		assert.NoError(t, err)
+		_, err = opt.Optimize(ctx)
+		assert.Error(t, err)
+	})
+
+	ru.MustVTable("student").SetAllowUpdateShardKey(true)
+
+	var execs []string
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			execs = append(execs, sql)
+			return resultx.New(resultx.WithRowsAffected(1)), nil
+		}).
+		AnyTimes()
+
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			var (
+				fields []proto.Field
+				values []proto.Value
+			)
+			if strings.Contains(sql, "student_tmp") { // the rows to be inserted
+				fields = []proto.Field{
+					mysql.NewField("id", consts.FieldTypeLongLong),
+					mysql.NewField("name", consts.FieldTypeVarChar),
+					mysql.NewField("uid", consts.FieldTypeLongLong),
+				}
+				values = []proto.Value{proto.NewValueInt64(1), proto.NewValueString("foo"), proto.NewValueInt64(8)}
+			} else if strings.HasPrefix(sql, "SELECT *") { // the updated row
+				fields = []proto.Field{
+					mysql.NewField("id", consts.FieldTypeLongLong),
+					mysql.NewField("name", consts.FieldTypeVarChar),
+					mysql.NewField("uid", consts.FieldTypeLongLong),
+				}
+				values = []proto.Value{proto.NewValueInt64(1), proto.NewValueString("foo"), proto.NewValueInt64(9)}
+			} else { // the primary keys of matched rows
+				fields = []proto.Field{mysql.NewField("id", consts.FieldTypeLongLong)}
+				values = []proto.Value{proto.NewValueInt64(1)}
+			}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, values))
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+
+	t.Run("update", func(t *testing.T) {
+		execs = nil
+
+		stmt, _ := parser.New().ParseOneStmt("update student set uid = ? where uid = ?", "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, []proto.Value{proto.NewValueInt64(9), proto.NewValueInt64(8)})
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+
+		_, err = plan.ExecIn(ctx, conn)
+		assert.Error(t, err, "should fail without transaction")
+
+		res, err := plan.ExecIn(ctx, fakeTx{conn: conn})
+		assert.NoError(t, err)
+
+		affected, _ := res.RowsAffected()
+		assert.Equal(t, uint64(1), affected)
+		assert.Equal(t, []string{
+			"UPDATE `student_0000` SET `uid` = ? WHERE (`uid` = ?) AND `id` IN (?)",
+			"DELETE FROM `student_0000` WHERE `id` IN (?)",
+			"INSERT INTO `student_0001`(`id`, `name`, `uid`) VALUES (?, ?, ?)",
+		}, execs)
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		execs = nil
+
+		stmt, _ := parser.New().ParseOneStmt("insert into student(id,name,uid) values(1,'foo',8) on duplicate key update uid = 9", "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+
+		_, err = plan.ExecIn(ctx, fakeTx{conn: conn})
+		assert.NoError(t, err)
+		assert.Len(t, execs, 3)
+		assert.Equal(t, "DELETE FROM `student_0000` WHERE `id` IN (?)", execs[1])
+		assert.Equal(t, "INSERT INTO `student_0001`(`id`, `name`, `uid`) VALUES (?, ?, ?)", execs[2])
+	})
+
+	t.Run("upsert by insert select", func(t *testing.T) {
+		execs = nil
+
+		sql := "insert into student(id,name,uid) select id,name,uid from student_tmp on duplicate key update uid = 9"
+		stmt, _ := parser.New().ParseOneStmt(sql, "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+
+		_, err = plan.ExecIn(ctx, conn)
+		assert.Error(t, err, "should fail without transaction")
+
+		_, err = plan.ExecIn(ctx, fakeTx{conn: conn})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"INSERT INTO `student_0000`(`id`, `name`, `uid`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `uid` = 9",
+			"DELETE FROM `student_0000` WHERE `id` IN (?)",
+			"INSERT INTO `student_0001`(`id`, `name`, `uid`) VALUES (?, ?, ?)",
+		}, execs)
+	})
+
+	t.Run("upsert by insert select without primary key", func(t *testing.T) {
+		sql := "insert into student(name,uid) select name,uid from student_tmp on duplicate key update uid = 9"
+		stmt, _ := parser.New().ParseOneStmt(sql, "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		_, err = opt.Optimize(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("upsert without primary key", func(t *testing.T) {
+		stmt, _ := parser.New().ParseOneStmt("insert into student(name,uid) values('foo',8) on duplicate key update uid = 9", "", "")
+		opt, err := NewOptimizer(ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		_, err = opt.Optimize(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestOptimizer_OptimizeUpdateShardKeyMoveForward(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+	loader := testdata.NewMockSchemaLoader(ctrl)
+
+	fakeStudentMetadata := map[string]*proto.TableMetadata{
+		"student_0000": {
+			Name: "student",
+			Columns: map[string]*proto.ColumnMetadata{
+				"id":   {Name: "id", DataType: "bigint", PrimaryKey: true},
+				"name": {Name: "name", DataType: "varchar"},
+				"uid":  {Name: "uid", DataType: "bigint"},
+			},
+			ColumnNames: []string{"id", "name", "uid"},
+		},
+	}
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeStudentMetadata, nil).AnyTimes()
+
+	oldLoader := proto.LoadSchemaLoader()
+	proto.RegisterSchemaLoader(loader)
+	defer proto.RegisterSchemaLoader(oldLoader)
+
+	type student struct {
+		id, uid int64
+		name    string
+	}
+
+	// student_000N holds the row whose uid is N, 'uid = uid + 1' moves every row into the next table,
+	// so some rows are always moved into a table visited later whatever the order of tables is.
+	var (
+		stored  = make(map[string][]*student)
+		updates = make(map[int64]int) // id -> times of updated
+		tableOf = regexp.MustCompile("`(student_\\d+)`")
+	)
+	for i := int64(0); i < 8; i++ {
+		stored[fmt.Sprintf("student_%04d", i)] = []*student{{id: i + 1, uid: i, name: "foo"}}
+	}
+
+	toInt := func(v interface{}) int64 {
+		n, _ := strconv.ParseInt(fmt.Sprintf("%v", v), 10, 64)
+		return n
+	}
+	// matches checks the row by 'name = ?' and the optional 'id IN (?,...)'
+	matches := func(it *student, args []interface{}) bool {
+		if it.name != fmt.Sprintf("%v", args[0]) {
+			return false
+		}
+		if len(args) == 1 {
+			return true
+		}
+		for _, id := range args[1:] {
+			if toInt(id) == it.id {
+				return true
+			}
+		}
+		return false
+	}
+	// inKeys checks the row by 'id IN (?,...)'
+	inKeys := func(it *student, args []interface{}) bool {
+		for _, id := range args {
+			if toInt(id) == it.id {
+				return true
+			}
+		}
+		return false
+	}
+
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			table := tableOf.FindStringSubmatch(sql)[1]
+			var affected uint64
+			switch {
+			case strings.HasPrefix(sql, "UPDATE"):
+				for _, it := range stored[table] {
+					if matches(it, args) {
+						it.uid++
+						updates[it.id]++
+						affected++
+					}
+				}
+			case strings.HasPrefix(sql, "DELETE"):
+				var rest []*student
+				for _, it := range stored[table] {
+					if inKeys(it, args) {
+						affected++
+						continue
+					}
+					rest = append(rest, it)
+				}
+				stored[table] = rest
+			case strings.HasPrefix(sql, "INSERT"):
+				for i := 0; i+2 < len(args); i += 3 {
+					stored[table] = append(stored[table], &student{
+						id:   toInt(args[i]),
+						name: fmt.Sprintf("%v", args[i+1]),
+						uid:  toInt(args[i+2]),
+					})
+					affected++
+				}
+			}
+			return resultx.New(resultx.WithRowsAffected(affected)), nil
+		}).
+		AnyTimes()
+
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			table := tableOf.FindStringSubmatch(sql)[1]
+			fields := []proto.Field{
+				mysql.NewField("id", consts.FieldTypeLongLong),
+				mysql.NewField("name", consts.FieldTypeVarChar),
+				mysql.NewField("uid", consts.FieldTypeLongLong),
+			}
+			all := strings.HasPrefix(sql, "SELECT *")
+			if !all { // the primary keys of matched rows
+				fields = fields[:1]
+			}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			for _, it := range stored[table] {
+				switch {
+				case all && inKeys(it, args):
+					ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{
+						proto.NewValueInt64(it.id),
+						proto.NewValueString(it.name),
+						proto.NewValueInt64(it.uid),
+					}))
+				case !all && matches(it, args):
+					ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{proto.NewValueInt64(it.id)}))
+				}
+			}
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+
+	var (
+		ctx = context.Background()
+		ru  = makeFakeRule(ctrl, 8)
+	)
+	ru.MustVTable("student").SetAllowUpdateShardKey(true)
+	ru.MustVTable("student").SetAllowFullScan(true)
+
+	stmt, _ := parser.New().ParseOneStmt("update student set uid = uid + 1 where name = ?", "", "")
+	opt, err := NewOptimizer(ru, nil, stmt, []proto.Value{proto.NewValueString("foo")})
+	assert.NoError(t, err)
+
+	plan, err := opt.Optimize(ctx)
+	assert.NoError(t, err)
+
+	res, err := plan.ExecIn(ctx, fakeTx{conn: conn})
+	assert.NoError(t, err)
+
+	affected, _ := res.RowsAffected()
+	assert.Equal(t, uint64(8), affected)
+
+	for i := int64(0); i < 8; i++ {
+		assert.Equal(t, 1, updates[i+1], "row %d should be updated only once", i+1)
+
+		next := stored[fmt.Sprintf("student_%04d", (i+1)%8)]
+		if assert.Len(t, next, 1) {
+			assert.Equal(t, student{id: i + 1, uid: i + 1, name: "foo"}, *next[0])
+		}
+	}
+}
+
+type fakeTx struct {
+	proto.Tx
+	conn proto.VConn
+}
+
+func (tx fakeTx) Query(ctx context.Context, db string, query string, args ...proto.Value) (proto.Result, error) {
+	return tx.conn.Query(ctx, db, query, args...)
+}
+
+func (tx fakeTx) Exec(ctx context.Context, db string, query string, args ...proto.Value) (proto.Result, error) {
+	return tx.conn.Exec(ctx, db, query, args...)
+}
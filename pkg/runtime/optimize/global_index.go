@@ -0,0 +1,50 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package optimize
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+)
+
+// RouteGlobalIndex returns the function which computes the physical index table by the value of indexed column.
+func RouteGlobalIndex(ru *rule.Rule, index *rule.GlobalIndex) (func(values []proto.Value) (string, string, error), error) {
+	if !ru.Has(index.Table) {
+		return nil, errors.Errorf("no sharding table '%s' found for global index '%s'", index.Table, index.Name)
+	}
+
+	table := ast.TableName{index.Table}
+	return func(values []proto.Value) (string, string, error) {
+		shards, err := ShardByValues(ru, table, []string{index.Column}, values)
+		if err != nil {
+			return "", "", errors.WithStack(err)
+		}
+		if shards.Len() != 1 {
+			return "", "", errors.Wrapf(ErrNoShardKeyFound, "cannot route global index '%s' with %s=%v", index.Name, index.Column, values[0])
+		}
+		for db, tables := range shards {
+			return db, tables[0], nil
+		}
+		return "", "", errors.WithStack(ErrNoShardKeyFound)
+	}, nil
+}
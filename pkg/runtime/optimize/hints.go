@@ -18,6 +18,7 @@
 package optimize
 
 import (
+	"strconv"
 	"strings"
 )
 
@@ -34,6 +35,7 @@ import (
 func init() {
 	RegisterHint(hint.TypeDirect, &Direct{})
 	RegisterHint(hint.TypeRoute, &CustomRoute{})
+	RegisterHint(hint.TypeShardValue, &ShardValue{})
 }
 
 type HintExecutor interface {
@@ -52,7 +54,7 @@ func validate(hints []*hint.Hint) error {
 	var shardingType, nodeType hint.Type
 
 	for _, v := range hints {
-		if v.Type == hint.TypeFullScan || v.Type == hint.TypeDirect || v.Type == hint.TypeRoute {
+		if v.Type == hint.TypeFullScan || v.Type == hint.TypeDirect || v.Type == hint.TypeRoute || v.Type == hint.TypeShardValue {
 			if shardingType > 0 {
 				return errors.Errorf("hint type conflict:%s,%s", shardingType.String(), v.Type.String())
 			}
@@ -73,6 +75,18 @@ func validate(hints []*hint.Hint) error {
 				}
 			}
 		}
+		// validate TypeShardValue
+		if v.Type == hint.TypeShardValue {
+			if len(v.Inputs) < 1 {
+				return errors.Errorf("shard_value hint requires at least one value")
+			}
+			for _, i := range v.Inputs {
+				tb := strings.Split(i.K, ".")
+				if len(tb) != 2 || len(tb[0]) < 1 || len(tb[1]) < 1 || len(i.V) < 1 {
+					return errors.Errorf("shard_value hint format error")
+				}
+			}
+		}
 
 	}
 	return nil
@@ -128,3 +142,75 @@ func (c *CustomRoute) exec(tableName ast.TableName, r *rule.Rule, hints []*hint.
 	}
 	return
 }
+
+// ShardValue computes the shards from the given values of sharding keys, such as SHARD_VALUE(student.uid=7).
+type ShardValue struct{}
+
+func (s *ShardValue) exec(tableName ast.TableName, r *rule.Rule, hints []*hint.Hint) (hintTables rule.DatabaseTables, err error) {
+	values := make(map[string]interface{})
+	for _, h := range hints {
+		if h.Type != hint.TypeShardValue {
+			continue
+		}
+		for _, i := range h.Inputs {
+			tb := strings.SplitN(i.K, ".", 2)
+			if !strings.EqualFold(tb[0], tableName.Suffix()) {
+				continue
+			}
+			values[tb[1]] = parseShardValue(i.V)
+		}
+	}
+
+	// no values for current table, use the default sharding instead
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	vt, ok := r.VTable(tableName.Suffix())
+	if !ok {
+		if group, ok := r.SingleTable(tableName.Suffix()); ok {
+			return rule.DatabaseTables{group: []string{tableName.Suffix()}}, nil
+		}
+		return nil, nil
+	}
+
+	shards, err := vt.ShardBy(values)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot compute shards of table '%s'", tableName.Suffix())
+	}
+	if shards == nil {
+		return nil, errors.Errorf("no sharding key of table '%s' in shard_value hint", tableName.Suffix())
+	}
+
+	hintTables = make(rule.DatabaseTables)
+	shards.Each(func(db, tb uint32) bool {
+		dbs, tbs, ok := vt.Topology().Render(int(db), int(tb))
+		if !ok {
+			err = errors.Errorf("cannot render table '%s'", vt.Name())
+			return false
+		}
+		hintTables[dbs] = append(hintTables[dbs], tbs)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hintTables, nil
+}
+
+// parseShardValue parses the literal value in hint, a quoted value is a string, otherwise it will be
+// parsed as an integer or a float if possible.
+func parseShardValue(s string) interface{} {
+	if len(s) > 1 {
+		if (s[0] == '\'' && s[len(s)-1] == '\'') || (s[0] == '"' && s[len(s)-1] == '"') {
+			return s[1 : len(s)-1]
+		}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
@@ -0,0 +1,124 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package optimize_test
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/hint"
+	"github.com/arana-db/arana/pkg/resultx"
+	. "github.com/arana-db/arana/pkg/runtime/optimize"
+	_ "github.com/arana-db/arana/pkg/runtime/optimize/dml"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestOptimizer_OptimizeShardValueHint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+
+	var sqls []string
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			sqls = append(sqls, sql)
+			ds := testdata.NewMockDataset(ctrl)
+			ds.EXPECT().Fields().Return([]proto.Field{}, nil).AnyTimes()
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			sqls = append(sqls, sql)
+			return resultx.New(resultx.WithRowsAffected(1)), nil
+		}).
+		AnyTimes()
+
+	var (
+		ctx = context.Background()
+		ru  = makeFakeRule(ctrl, 8)
+	)
+
+	for _, it := range []struct {
+		sql    string
+		hint   string
+		expect string
+	}{
+		{
+			"select id, uid from student where age > ?",
+			"shard_value(student.uid=7)",
+			"SELECT `id`,`uid` FROM `student_0007` WHERE `age` > ?",
+		},
+		{
+			"delete from student where age > ?",
+			"shard_value(student.uid='15')",
+			"DELETE FROM `student_0007` WHERE `age` > ?",
+		},
+	} {
+		t.Run(it.sql, func(t *testing.T) {
+			sqls = nil
+
+			h, err := hint.Parse(it.hint)
+			assert.NoError(t, err)
+
+			stmt, _ := parser.New().ParseOneStmt(it.sql, "", "")
+			opt, err := NewOptimizer(ru, []*hint.Hint{h}, stmt, []proto.Value{proto.NewValueInt64(18)})
+			assert.NoError(t, err)
+
+			plan, err := opt.Optimize(ctx)
+			assert.NoError(t, err)
+
+			_, err = plan.ExecIn(ctx, conn)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{it.expect}, sqls)
+		})
+	}
+
+	t.Run("no sharding key", func(t *testing.T) {
+		h, _ := hint.Parse("shard_value(student.age=18)")
+		stmt, _ := parser.New().ParseOneStmt("select id, uid from student where age > ?", "", "")
+		opt, err := NewOptimizer(ru, []*hint.Hint{h}, stmt, []proto.Value{proto.NewValueInt64(18)})
+		assert.NoError(t, err)
+		_, err = opt.Optimize(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		h1, _ := hint.Parse("shard_value(student.uid=7)")
+		h2, _ := hint.Parse("fullscan")
+		stmt, _ := parser.New().ParseOneStmt("select id, uid from student where age > ?", "", "")
+		opt, err := NewOptimizer(ru, []*hint.Hint{h1, h2}, stmt, []proto.Value{proto.NewValueInt64(18)})
+		assert.NoError(t, err)
+		_, err = opt.Optimize(ctx)
+		assert.Error(t, err)
+	})
+}
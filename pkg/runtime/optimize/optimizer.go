@@ -121,6 +121,10 @@ func (o *Optimizer) ComputeShards(table rast.TableName, where rast.ExpressionNod
 	ru := o.Rule
 	vt, ok := ru.VTable(table.Suffix())
 	if !ok {
+		// the single table which is placed on the specific group
+		if group, ok := ru.SingleTable(table.Suffix()); ok {
+			return rule.DatabaseTables{group: []string{table.Suffix()}}, nil
+		}
 		return nil, nil
 	}
 	var (
@@ -20,6 +20,7 @@ package optimize_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 )
@@ -33,7 +34,12 @@ import (
 )
 
 import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/mysql/rows"
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/hint"
 	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/resultx"
 	. "github.com/arana-db/arana/pkg/runtime/optimize"
@@ -116,7 +122,7 @@ func TestOptimizer_OptimizeInsert(t *testing.T) {
 		Generated:     false,
 		CaseSensitive: false,
 	}
-	fakeStudentMetadata["student"] = &proto.TableMetadata{
+	fakeStudentMetadata["student_0000"] = &proto.TableMetadata{
 		Name:              "student",
 		Columns:           fakeStuColumnMetadata,
 		Indexes:           nil,
@@ -135,7 +141,7 @@ func TestOptimizer_OptimizeInsert(t *testing.T) {
 			), nil
 		}).
 		AnyTimes()
-	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeStudentMetadata, nil).Times(2)
+	loader.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeStudentMetadata, nil).Times(1)
 
 	oldLoader := proto.LoadSchemaLoader()
 	proto.RegisterSchemaLoader(loader)
@@ -193,6 +199,27 @@ func TestOptimizer_OptimizeInsert(t *testing.T) {
 	})
 }
 
+type fakeDDLJobManager struct {
+	submitted []*proto.DDLJob
+}
+
+func (f *fakeDDLJobManager) Submit(_ context.Context, job *proto.DDLJob) (int64, error) {
+	f.submitted = append(f.submitted, job)
+	return int64(len(f.submitted)), nil
+}
+
+func (f *fakeDDLJobManager) List(_ context.Context, _ string) ([]*proto.DDLJob, error) {
+	return f.submitted, nil
+}
+
+func (f *fakeDDLJobManager) Cancel(_ context.Context, _ string, _ int64) error {
+	return proto.ErrorDDLJobNotFound
+}
+
+func (f *fakeDDLJobManager) Resume(_ context.Context, _ string) error {
+	return nil
+}
+
 func TestOptimizer_OptimizeAlterTable(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -242,6 +269,33 @@ func TestOptimizer_OptimizeAlterTable(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("async", func(t *testing.T) {
+		jobs := &fakeDDLJobManager{}
+		proto.RegisterDDLJobManager(jobs)
+		defer proto.RegisterDDLJobManager(nil)
+
+		stmt, _ := parser.New().ParseOneStmt("alter table student add dept_id int not null default 0", "", "")
+		h, _ := hint.Parse("async")
+
+		opt, err := NewOptimizer(&ru, []*hint.Hint{h}, stmt, nil)
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+
+		// the job is submitted without executing on any physical table
+		res, err := plan.ExecIn(ctx, testdata.NewMockVConn(ctrl))
+		assert.NoError(t, err)
+		id, _ := res.LastInsertId()
+		assert.Equal(t, uint64(1), id)
+
+		assert.Len(t, jobs.submitted, 1)
+		assert.Equal(t, "student", jobs.submitted[0].Table)
+		assert.Len(t, jobs.submitted[0].Tasks, 8)
+		assert.Equal(t, "fake_db", jobs.submitted[0].Tasks[7].DB)
+		assert.Equal(t, "ALTER TABLE `student_0007` ADD COLUMN `dept_id` INT(11) NOT NULL DEFAULT 0", jobs.submitted[0].Tasks[7].SQL)
+	})
+
 	t.Run("non-sharding", func(t *testing.T) {
 		sql := "alter table employees add index idx_name (first_name)"
 
@@ -306,3 +360,132 @@ func TestOptimizer_OptimizeInsertSelect(t *testing.T) {
 		assert.Equal(t, fakeId, lastInsertId)
 	})
 }
+
+func TestOptimizer_OptimizeSingleTable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := testdata.NewMockVConn(ctrl)
+
+	var ru rule.Rule
+	ru.SetSingleTables(map[string]string{
+		"config":  "db_0001",
+		"setting": "db_0001",
+		"region":  "db_0002",
+	})
+
+	tables := map[string][]string{
+		"":        {"foo", "config"},
+		"db_0001": {"config", "setting", "bar"},
+		"db_0002": {"region"},
+	}
+
+	var calls []string // db: sql
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake query: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			calls = append(calls, db+": "+sql)
+			fields := []proto.Field{mysql.NewField("Tables_in_fake", consts.FieldTypeVarString)}
+			ds := &dataset.VirtualDataset{Columns: fields}
+			if strings.HasPrefix(sql, "SHOW TABLES") {
+				for _, it := range tables[db] {
+					ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{proto.NewValueString(it)}))
+				}
+			}
+			return resultx.New(resultx.WithDataset(ds)), nil
+		}).
+		AnyTimes()
+	conn.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			t.Logf("fake exec: db='%s', sql=\"%s\", args=%v\n", db, sql, args)
+			calls = append(calls, db+": "+sql)
+			return resultx.New(resultx.WithRowsAffected(1)), nil
+		}).
+		AnyTimes()
+
+	ctx := context.Background()
+
+	for _, it := range []struct {
+		sql    string
+		expect string
+	}{
+		{"select id, name from config where id = 1", "db_0001: SELECT `id`,`name` FROM `config` WHERE `id` = 1"},
+		{"insert into config(id,name) values(1,'foo')", "db_0001: INSERT INTO `config`(`id`, `name`) VALUES (1, 'foo')"},
+		{"update config set name = 'bar' where id = 1", "db_0001: UPDATE `config` SET `name` = 'bar' WHERE `id` = 1"},
+		{"delete from config where id = 1", "db_0001: DELETE FROM `config` WHERE `id` = 1"},
+		{"select c.name from config c join setting s on c.id = s.config_id", "db_0001: SELECT `c`.`name` FROM config  AS c INNER JOIN setting  AS s  ON `c`.`id` = `s`.`config_id`"},
+		{"select name from foo", ": SELECT `name` FROM `foo`"},
+	} {
+		t.Run(it.sql, func(t *testing.T) {
+			calls = nil
+
+			stmt, _ := parser.New().ParseOneStmt(it.sql, "", "")
+			opt, err := NewOptimizer(&ru, nil, stmt, nil)
+			assert.NoError(t, err)
+
+			plan, err := opt.Optimize(ctx)
+			assert.NoError(t, err)
+
+			res, err := plan.ExecIn(ctx, fakeTx{conn: conn})
+			assert.NoError(t, err)
+			if plan.Type() == proto.PlanTypeQuery {
+				ds, err := res.Dataset()
+				assert.NoError(t, err)
+				_, err = ds.Next()
+				assert.Equal(t, io.EOF, err)
+			}
+
+			assert.Equal(t, []string{it.expect}, calls)
+		})
+	}
+
+	t.Run("join single tables on different groups", func(t *testing.T) {
+		stmt, _ := parser.New().ParseOneStmt("select c.name from config c join region r on c.region_id = r.id", "", "")
+		opt, err := NewOptimizer(&ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		_, err = opt.Optimize(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("show tables", func(t *testing.T) {
+		stmt, _ := parser.New().ParseOneStmt("show tables", "", "")
+		opt, err := NewOptimizer(&ru, nil, stmt, nil)
+		assert.NoError(t, err)
+
+		plan, err := opt.Optimize(ctx)
+		assert.NoError(t, err)
+
+		res, err := plan.ExecIn(ctx, fakeTx{conn: conn})
+		assert.NoError(t, err)
+
+		ds, err := res.Dataset()
+		assert.NoError(t, err)
+
+		var shown []string
+		for {
+			row, err := ds.Next()
+			if err != nil {
+				assert.Equal(t, io.EOF, err)
+				break
+			}
+			dest := make([]proto.Value, 1)
+			assert.NoError(t, row.Scan(dest))
+			shown = append(shown, dest[0].String())
+		}
+		assert.Equal(t, []string{"foo", "config", "setting", "region"}, shown)
+	})
+}
+
+type fakeTx struct {
+	proto.Tx
+	conn proto.VConn
+}
+
+func (tx fakeTx) Query(ctx context.Context, db string, query string, args ...proto.Value) (proto.Result, error) {
+	return tx.conn.Query(ctx, db, query, args...)
+}
+
+func (tx fakeTx) Exec(ctx context.Context, db string, query string, args ...proto.Value) (proto.Result, error) {
+	return tx.conn.Exec(ctx, db, query, args...)
+}
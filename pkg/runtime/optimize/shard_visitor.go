@@ -18,6 +18,7 @@
 package optimize
 
 import (
+	"sort"
 	"strings"
 )
 
@@ -86,6 +87,42 @@ func (sd *ShardVisitor) SimpleShard(table ast.TableName, where ast.ExpressionNod
 	return shards, nil
 }
 
+// ShardKeys returns the sharding keys of table in order.
+func ShardKeys(vt *rule.VTable) []string {
+	keys := vt.GetShardKeys()
+	sort.Strings(keys)
+	return keys
+}
+
+// ShardByValues computes the shards of table by the values of columns, eg: a = ? AND b = ?,
+// returns nil if the shards cannot be computed.
+func ShardByValues(ru *rule.Rule, table ast.TableName, columns []string, values []proto.Value) (rule.DatabaseTables, error) {
+	var filter ast.ExpressionNode
+	for i, column := range columns {
+		next := &ast.PredicateExpressionNode{
+			P: &ast.BinaryComparisonPredicateNode{
+				Left: &ast.AtomPredicateNode{
+					A: ast.ColumnNameExpressionAtom([]string{column}),
+				},
+				Op: cmp.Ceq,
+				Right: &ast.AtomPredicateNode{
+					A: ast.VariableExpressionAtom(i),
+				},
+			},
+		}
+		if filter == nil {
+			filter = next
+			continue
+		}
+		filter = &ast.LogicalExpressionNode{
+			Op:    logical.Land,
+			Left:  filter,
+			Right: next,
+		}
+	}
+	return NewXSharder(ru, values).SimpleShard(table, filter)
+}
+
 func (sd *ShardVisitor) ForSingleSelect(table ast.TableName, alias string, where ast.ExpressionNode) error {
 	vtab, ok := sd.ru.VTable(table.Suffix())
 	if !ok {
@@ -0,0 +1,76 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dal
+
+import (
+	"context"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/mysql/thead"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+)
+
+var _ proto.Plan = (*CancelDDLJobsPlan)(nil)
+
+// CancelDDLJobsPlan cancels the asynchronous DDL jobs, the result of each job will be returned.
+type CancelDDLJobsPlan struct {
+	plan.BasePlan
+	stmt *ast.CancelDDLJobsStatement
+}
+
+func NewCancelDDLJobsPlan(stmt *ast.CancelDDLJobsStatement) *CancelDDLJobsPlan {
+	return &CancelDDLJobsPlan{stmt: stmt}
+}
+
+func (c *CancelDDLJobsPlan) Type() proto.PlanType {
+	return proto.PlanTypeQuery
+}
+
+func (c *CancelDDLJobsPlan) ExecIn(ctx context.Context, _ proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "CancelDDLJobsPlan.ExecIn")
+	defer span.End()
+
+	var (
+		manager = proto.LoadDDLJobManager()
+		tenant  = rcontext.Tenant(ctx)
+		fields  = thead.CancelDDLJobs.ToFields()
+		ds      = &dataset.VirtualDataset{
+			Columns: fields,
+		}
+	)
+
+	for _, id := range c.stmt.IDs {
+		result := "successful"
+		if err := manager.Cancel(ctx, tenant, id); err != nil {
+			result = err.Error()
+		}
+		ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{
+			proto.NewValueInt64(id),
+			proto.NewValueString(result),
+		}))
+	}
+
+	return resultx.New(resultx.WithDataset(ds)), nil
+}
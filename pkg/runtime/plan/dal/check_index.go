@@ -0,0 +1,261 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dal
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/mysql/thead"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+	"github.com/arana-db/arana/pkg/runtime/plan/dml"
+)
+
+// kinds of global index inconsistency
+const (
+	IndexEntryMissing   = "missing"   // the entry of row is not found in the index table
+	IndexEntryDangling  = "dangling"  // the entry points to no row of base table
+	IndexEntryMisplaced = "misplaced" // the entry is stored in a wrong physical index table
+)
+
+var _ proto.Plan = (*CheckIndexPlan)(nil)
+
+// CheckIndexPlan compares the entries of global secondary index with the rows of base table, each inconsistent
+// entry will be returned as a row. In recovery mode, the missing entries will be added and the dangling or
+// misplaced ones will be removed.
+//
+// NOTICE: the index table is scanned before the base table, so a concurrent insert may only be reported as missing,
+// but it is still recommended to recover the index when the writes are paused.
+type CheckIndexPlan struct {
+	plan.BasePlan
+	Table, Index string
+	Recover      bool
+	Shards       rule.DatabaseTables // the physical base tables
+	IndexShards  rule.DatabaseTables // the physical index tables
+	IndexTable   *dml.GlobalIndexTable
+}
+
+func (c *CheckIndexPlan) Type() proto.PlanType {
+	if c.Recover {
+		return proto.PlanTypeExec
+	}
+	return proto.PlanTypeQuery
+}
+
+type indexEntry struct {
+	db, table, kind string
+	values          []proto.Value
+}
+
+func (c *CheckIndexPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "CheckIndexPlan.ExecIn")
+	defer span.End()
+
+	entries, err := c.check(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Recover {
+		return c.recover(ctx, conn, entries)
+	}
+
+	fields := thead.GlobalIndexCheck.ToFields()
+	ds := &dataset.VirtualDataset{
+		Columns: fields,
+	}
+	for _, it := range entries {
+		ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{
+			proto.NewValueString(c.Table),
+			proto.NewValueString(c.Index),
+			proto.NewValueString(it.db),
+			proto.NewValueString(it.table),
+			proto.NewValueString(it.kind),
+			proto.NewValueString(valueString(it.values[0])),
+			proto.NewValueString(c.shardingKeys(it.values[1:])),
+		}))
+	}
+
+	return resultx.New(resultx.WithDataset(ds)), nil
+}
+
+// check returns the inconsistent entries of global index.
+func (c *CheckIndexPlan) check(ctx context.Context, conn proto.VConn) ([]*indexEntry, error) {
+	var (
+		ret    []*indexEntry
+		placed = make(map[string]struct{})
+		actual []*indexEntry
+	)
+
+	// 1. scan the index tables, the entries stored in wrong index tables are misplaced
+	err := eachShard(c.IndexShards, func(db, table string) error {
+		values, err := c.IndexTable.Scan(ctx, conn, db, table)
+		if err != nil {
+			return err
+		}
+		for _, it := range values {
+			routeDB, routeTable, err := c.IndexTable.Route(it[:1])
+			if err != nil {
+				return err
+			}
+			if routeDB != db || routeTable != table {
+				ret = append(ret, &indexEntry{db: db, table: table, kind: IndexEntryMisplaced, values: it})
+				continue
+			}
+			placed[entryKey(db, table, it)] = struct{}{}
+			actual = append(actual, &indexEntry{db: db, table: table, kind: IndexEntryDangling, values: it})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. scan the base tables, the entries which are not found in the index tables are missing
+	expected := make(map[string]struct{})
+	err = eachShard(c.Shards, func(db, table string) error {
+		values, err := c.IndexTable.Scan(ctx, conn, db, table)
+		if err != nil {
+			return err
+		}
+		for _, it := range values {
+			routeDB, routeTable, err := c.IndexTable.Route(it[:1])
+			if err != nil {
+				return err
+			}
+			key := entryKey(routeDB, routeTable, it)
+			if _, ok := expected[key]; ok {
+				continue
+			}
+			expected[key] = struct{}{}
+			if _, ok := placed[key]; !ok {
+				ret = append(ret, &indexEntry{db: routeDB, table: routeTable, kind: IndexEntryMissing, values: it})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. the entries which point to no rows are dangling
+	for _, it := range actual {
+		if _, ok := expected[entryKey(it.db, it.table, it.values)]; !ok {
+			ret = append(ret, it)
+		}
+	}
+
+	return ret, nil
+}
+
+// recover fixes the inconsistent entries, returns the count of affected entries.
+func (c *CheckIndexPlan) recover(ctx context.Context, conn proto.VConn, entries []*indexEntry) (proto.Result, error) {
+	var (
+		missing  [][]proto.Value
+		removing = make(map[string][][]proto.Value)
+		targets  []string
+	)
+	for _, it := range entries {
+		if it.kind == IndexEntryMissing {
+			missing = append(missing, it.values)
+			continue
+		}
+		key := it.db + "." + it.table
+		if _, ok := removing[key]; !ok {
+			targets = append(targets, key)
+		}
+		removing[key] = append(removing[key], it.values)
+	}
+
+	affects, err := c.IndexTable.Add(ctx, conn, missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		i := strings.IndexByte(target, '.')
+		n, err := c.IndexTable.RemoveFrom(ctx, conn, target[:i], target[i+1:], removing[target])
+		if err != nil {
+			return nil, err
+		}
+		affects += n
+	}
+
+	return resultx.New(resultx.WithRowsAffected(affects)), nil
+}
+
+// shardingKeys formats the values of sharding keys, eg: uid=1, tenant=foo
+func (c *CheckIndexPlan) shardingKeys(values []proto.Value) string {
+	var sb strings.Builder
+	for i, it := range values {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(c.IndexTable.Keys[i])
+		sb.WriteByte('=')
+		sb.WriteString(valueString(it))
+	}
+	return sb.String()
+}
+
+// eachShard iterates the physical tables in order.
+func eachShard(shards rule.DatabaseTables, fn func(db, table string) error) error {
+	dbs := make([]string, 0, len(shards))
+	for db := range shards {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+	for _, db := range dbs {
+		for _, table := range shards[db] {
+			if err := fn(db, table); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func entryKey(db, table string, values []proto.Value) string {
+	var sb strings.Builder
+	sb.WriteString(db)
+	sb.WriteByte(0)
+	sb.WriteString(table)
+	for _, it := range values {
+		sb.WriteByte(0)
+		if it == nil {
+			sb.WriteString("\x01")
+		} else {
+			sb.WriteString(it.String())
+		}
+	}
+	return sb.String()
+}
+
+func valueString(value proto.Value) string {
+	if value == nil {
+		return "NULL"
+	}
+	return value.String()
+}
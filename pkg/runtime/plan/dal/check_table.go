@@ -0,0 +1,81 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dal
+
+import (
+	"context"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/mysql/thead"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+	"github.com/arana-db/arana/pkg/schema/drift"
+)
+
+var _ proto.Plan = (*CheckTablePlan)(nil)
+
+// CheckTablePlan checks the schema drift of sharding tables, each difference of physical tables will be returned as a row.
+type CheckTablePlan struct {
+	plan.BasePlan
+	stmt   *ast.CheckTableStatement
+	Shards []rule.DatabaseTables // the physical tables of each logical table
+}
+
+func NewCheckTablePlan(stmt *ast.CheckTableStatement) *CheckTablePlan {
+	return &CheckTablePlan{stmt: stmt}
+}
+
+func (c *CheckTablePlan) Type() proto.PlanType {
+	return proto.PlanTypeQuery
+}
+
+func (c *CheckTablePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "CheckTablePlan.ExecIn")
+	defer span.End()
+
+	fields := thead.SchemaDrift.ToFields()
+	ds := &dataset.VirtualDataset{
+		Columns: fields,
+	}
+
+	for i, table := range c.stmt.Tables {
+		report, err := drift.Check(ctx, conn, table.Suffix(), c.Shards[i], false)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range report.Differences {
+			ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{
+				proto.NewValueString(report.Table),
+				proto.NewValueString(d.DB),
+				proto.NewValueString(d.Table),
+				proto.NewValueString(d.Kind),
+				proto.NewValueString(d.Name),
+				proto.NewValueString(d.Expected),
+				proto.NewValueString(d.Actual),
+			}))
+		}
+	}
+
+	return resultx.New(resultx.WithDataset(ds)), nil
+}
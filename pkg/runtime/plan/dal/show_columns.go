@@ -36,8 +36,9 @@ var _ proto.Plan = (*ShowColumnsPlan)(nil)
 
 type ShowColumnsPlan struct {
 	plan.BasePlan
-	Stmt  *ast.ShowColumns
-	Table string
+	Stmt     *ast.ShowColumns
+	Database string
+	Table    string
 }
 
 func (s *ShowColumnsPlan) Type() proto.PlanType {
@@ -54,7 +55,7 @@ func (s *ShowColumnsPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.R
 		return nil, errors.Wrap(err, "failed to generate show columns sql")
 	}
 
-	return conn.Query(ctx, "", sb.String(), s.ToArgs(indexes)...)
+	return conn.Query(ctx, s.Database, sb.String(), s.ToArgs(indexes)...)
 }
 
 func (s *ShowColumnsPlan) generate(sb *strings.Builder, args *[]int) error {
@@ -0,0 +1,103 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dal
+
+import (
+	"context"
+	"fmt"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/mysql/thead"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+)
+
+const _ddlJobTimeLayout = "2006-01-02 15:04:05"
+
+var _ proto.Plan = (*ShowDDLJobsPlan)(nil)
+
+// ShowDDLJobsPlan shows the asynchronous DDL jobs of current tenant.
+type ShowDDLJobsPlan struct {
+	plan.BasePlan
+	stmt *ast.ShowDDLJobsStatement
+}
+
+func NewShowDDLJobsPlan(stmt *ast.ShowDDLJobsStatement) *ShowDDLJobsPlan {
+	return &ShowDDLJobsPlan{stmt: stmt}
+}
+
+func (s *ShowDDLJobsPlan) Type() proto.PlanType {
+	return proto.PlanTypeQuery
+}
+
+func (s *ShowDDLJobsPlan) ExecIn(ctx context.Context, _ proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "ShowDDLJobsPlan.ExecIn")
+	defer span.End()
+
+	jobs, err := proto.LoadDDLJobManager().List(ctx, rcontext.Tenant(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if s.stmt.Limit > 0 && int64(len(jobs)) > s.stmt.Limit {
+		jobs = jobs[:s.stmt.Limit]
+	}
+
+	fields := thead.DDLJobs.ToFields()
+	ds := &dataset.VirtualDataset{
+		Columns: fields,
+	}
+
+	for _, job := range jobs {
+		var (
+			done, failed int64
+			cause        string
+		)
+		for _, task := range job.Tasks {
+			switch task.State {
+			case proto.DDLStateDone:
+				done++
+			case proto.DDLStateFailed:
+				failed++
+				if len(cause) < 1 {
+					cause = fmt.Sprintf("%s.%s: %s", task.DB, task.Table, task.Error)
+				}
+			}
+		}
+		ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, []proto.Value{
+			proto.NewValueInt64(job.ID),
+			proto.NewValueString(job.Schema),
+			proto.NewValueString(job.Table),
+			proto.NewValueString(job.State.String()),
+			proto.NewValueInt64(done),
+			proto.NewValueInt64(failed),
+			proto.NewValueInt64(int64(len(job.Tasks))),
+			proto.NewValueString(job.Query),
+			proto.NewValueString(job.CreatedAt.Format(_ddlJobTimeLayout)),
+			proto.NewValueString(job.UpdatedAt.Format(_ddlJobTimeLayout)),
+			proto.NewValueString(cause),
+		}))
+	}
+
+	return resultx.New(resultx.WithDataset(ds)), nil
+}
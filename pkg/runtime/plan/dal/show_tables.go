@@ -20,11 +20,14 @@ package dal
 import (
 	"context"
 	"database/sql"
+	"sort"
 	"strings"
 )
 
 import (
 	"github.com/pkg/errors"
+
+	"golang.org/x/exp/slices"
 )
 
 import (
@@ -46,6 +49,7 @@ type ShowTablesPlan struct {
 	Database       string
 	Stmt           *ast.ShowTables
 	invertedShards map[string]string // phy table name -> logical table name
+	singleTables   map[string]string // single table name -> group
 }
 
 // NewShowTablesPlan create ShowTables Plan
@@ -63,7 +67,6 @@ func (st *ShowTablesPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.R
 	var (
 		sb      strings.Builder
 		indexes []int
-		res     proto.Result
 		err     error
 	)
 	ctx, span := plan.Tracer.Start(ctx, "ShowTablesPlan.ExecIn")
@@ -78,11 +81,30 @@ func (st *ShowTablesPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.R
 		args  = st.ToArgs(indexes)
 	)
 
-	if res, err = conn.Query(ctx, st.Database, query, args...); err != nil {
-		return nil, errors.WithStack(err)
+	// the single tables placed on other groups should be shown too
+	groups := make([]string, 0, len(st.singleTables))
+	for _, group := range st.singleTables {
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+
+	generators := make([]dataset.GenerateFunc, 0, len(groups))
+	for _, group := range groups {
+		group := group
+		generators = append(generators, func() (proto.Dataset, error) {
+			ds, err := st.query(ctx, conn, group, query, args)
+			if err != nil {
+				return nil, err
+			}
+			return st.filterSingleTables(ds, group)
+		})
 	}
 
-	ds, err := res.Dataset()
+	ds, err := dataset.Fuse(func() (proto.Dataset, error) {
+		return st.query(ctx, conn, st.Database, query, args)
+	}, generators...)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -138,6 +160,45 @@ func (st *ShowTablesPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.R
 	return resultx.New(resultx.WithDataset(ds)), nil
 }
 
+func (st *ShowTablesPlan) query(ctx context.Context, conn proto.VConn, db, query string, args []proto.Value) (proto.Dataset, error) {
+	res, err := conn.Query(ctx, db, query, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ds, err := res.Dataset()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ds, nil
+}
+
+// filterSingleTables keeps the single tables which are placed on the group only.
+func (st *ShowTablesPlan) filterSingleTables(ds proto.Dataset, group string) (proto.Dataset, error) {
+	fields, err := ds.Fields()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return dataset.Pipe(ds,
+		dataset.Map(nil, func(next proto.Row) (proto.Row, error) {
+			dest := make([]proto.Value, len(fields))
+			if err := next.Scan(dest); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if next.IsBinary() {
+				return rows.NewBinaryVirtualRow(fields, dest), nil
+			}
+			return rows.NewTextVirtualRow(fields, dest), nil
+		}),
+		dataset.Filter(func(next proto.Row) bool {
+			vr, ok := next.(rows.VirtualRow)
+			if !ok || len(vr.Values()) < 1 || vr.Values()[0] == nil {
+				return false
+			}
+			return st.singleTables[vr.Values()[0].String()] == group
+		}),
+	), nil
+}
+
 func (st *ShowTablesPlan) SetDatabase(db string) {
 	st.Database = db
 }
@@ -145,3 +206,8 @@ func (st *ShowTablesPlan) SetDatabase(db string) {
 func (st *ShowTablesPlan) SetInvertedShards(m map[string]string) {
 	st.invertedShards = m
 }
+
+// SetSingleTables sets the locations of single tables, single table name -> group.
+func (st *ShowTablesPlan) SetSingleTables(m map[string]string) {
+	st.singleTables = m
+}
@@ -19,6 +19,7 @@ package ddl
 
 import (
 	"context"
+	"sort"
 	"strings"
 )
 
@@ -35,6 +36,7 @@ import (
 	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/resultx"
 	"github.com/arana-db/arana/pkg/runtime/ast"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
 	"github.com/arana-db/arana/pkg/runtime/plan"
 	"github.com/arana-db/arana/pkg/util/log"
 )
@@ -45,6 +47,8 @@ type AlterTablePlan struct {
 	plan.BasePlan
 	stmt   *ast.AlterTableStatement
 	Shards rule.DatabaseTables
+	// Async submits the sharding alter table as an asynchronous DDL job instead of waiting for all shards.
+	Async bool
 }
 
 func NewAlterTablePlan(stmt *ast.AlterTableStatement) *AlterTablePlan {
@@ -65,6 +69,11 @@ func (at *AlterTablePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.R
 		}
 		return conn.Exec(ctx, "", sb.String(), at.Args...)
 	}
+
+	if at.Async {
+		return at.submit(ctx)
+	}
+
 	var (
 		affects = uatomic.NewUint64(0)
 		cnt     = uatomic.NewUint32(0)
@@ -122,3 +131,45 @@ func (at *AlterTablePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.R
 
 	return resultx.New(resultx.WithRowsAffected(affects.Load())), nil
 }
+
+// submit submits the DDL job of all physical tables, the job id will be returned as the last insert id.
+func (at *AlterTablePlan) submit(ctx context.Context) (proto.Result, error) {
+	var sb strings.Builder
+	if err := at.stmt.Restore(ast.RestoreDefault, &sb, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	job := &proto.DDLJob{
+		Tenant: rcontext.Tenant(ctx),
+		Schema: rcontext.Schema(ctx),
+		Table:  at.stmt.Table.Suffix(),
+		Query:  sb.String(),
+	}
+
+	dbs := make([]string, 0, len(at.Shards))
+	for db := range at.Shards {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	for _, db := range dbs {
+		for _, table := range at.Shards[db] {
+			sb.Reset()
+			if err := at.stmt.ResetTable(table).Restore(ast.RestoreDefault, &sb, nil); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			job.Tasks = append(job.Tasks, &proto.DDLTask{
+				DB:    db,
+				Table: table,
+				SQL:   sb.String(),
+			})
+		}
+	}
+
+	id, err := proto.LoadDDLJobManager().Submit(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to submit ddl job")
+	}
+
+	return resultx.New(resultx.WithLastInsertID(uint64(id))), nil
+}
@@ -0,0 +1,129 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/pkg/errors"
+
+	"golang.org/x/sync/errgroup"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+var _ proto.Plan = (*CreateTablePlan)(nil)
+
+// CreateTablePlan creates the table, it will be created for each physical table if it's a sharding table.
+// The count of created physical tables is responded as the affected rows, the failed ones are responded in error.
+type CreateTablePlan struct {
+	plan.BasePlan
+	stmt   *ast.CreateTableStatement
+	Shards rule.DatabaseTables
+}
+
+func NewCreateTablePlan(stmt *ast.CreateTableStatement) *CreateTablePlan {
+	return &CreateTablePlan{stmt: stmt}
+}
+
+func (c *CreateTablePlan) Type() proto.PlanType {
+	return proto.PlanTypeExec
+}
+
+func (c *CreateTablePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "CreateTablePlan.ExecIn")
+	defer span.End()
+
+	if c.Shards == nil {
+		// non-sharding create table
+		var sb strings.Builder
+		if err := c.stmt.Restore(ast.RestoreDefault, &sb, nil); err != nil {
+			return nil, err
+		}
+		return conn.Exec(ctx, "", sb.String(), c.Args...)
+	}
+
+	var (
+		mu       sync.Mutex
+		created  int
+		failures []string
+		g        errgroup.Group
+	)
+
+	// the other physical tables will still be created if some of them failed, so that it can be retried
+	// with IF NOT EXISTS until all the physical tables are created.
+	for k, v := range c.Shards {
+		// do copy for goroutine-safe
+		var (
+			db     = k
+			tables = v
+		)
+		// execute concurrent for each phy database
+		g.Go(func() error {
+			var sb strings.Builder
+			for _, table := range tables {
+				sb.Reset()
+				if err := c.stmt.ResetTable(table).Restore(ast.RestoreDefault, &sb, nil); err != nil {
+					return errors.WithStack(err)
+				}
+
+				res, err := conn.Exec(ctx, db, sb.String())
+				if err == nil {
+					resultx.Drain(res)
+				}
+
+				mu.Lock()
+				if err != nil {
+					failures = append(failures, fmt.Sprintf("%s.%s: %v", db, table, err))
+				} else {
+					created++
+				}
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	total := c.Shards.Len()
+	log.Infof("create sharding table %s: total=%d, succeeded=%d, failed=%d", c.stmt.Table, total, created, len(failures))
+
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return nil, errors.Errorf("failed to create %d of %d physical tables of %s: %s",
+			len(failures), total, c.stmt.Table, strings.Join(failures, "; "))
+	}
+
+	return resultx.New(resultx.WithRowsAffected(uint64(created))), nil
+}
@@ -0,0 +1,136 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/pkg/errors"
+
+	"golang.org/x/sync/errgroup"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+)
+
+var _ proto.Plan = (*DatabasePlan)(nil)
+
+// DatabasePlan executes CREATE/DROP DATABASE, it will be executed in each group if it's a logical schema.
+type DatabasePlan struct {
+	plan.BasePlan
+	stmt ast.Restorer
+	// Groups is the groups of the logical schema, the physical database name of each group is resolved from its node.
+	Groups []string
+	// write creates the statement for the physical database.
+	write func(name string) ast.Restorer
+}
+
+func NewCreateDatabasePlan(stmt *ast.CreateDatabaseStatement) *DatabasePlan {
+	return &DatabasePlan{
+		stmt: stmt,
+		write: func(name string) ast.Restorer {
+			return stmt.ResetName(name)
+		},
+	}
+}
+
+func NewDropDatabasePlan(stmt *ast.DropDatabaseStatement) *DatabasePlan {
+	return &DatabasePlan{
+		stmt: stmt,
+		write: func(name string) ast.Restorer {
+			return stmt.ResetName(name)
+		},
+	}
+}
+
+func (d *DatabasePlan) Type() proto.PlanType {
+	return proto.PlanTypeExec
+}
+
+func (d *DatabasePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "DatabasePlan.ExecIn")
+	defer span.End()
+
+	if d.Groups == nil {
+		// not a logical schema
+		var sb strings.Builder
+		if err := d.stmt.Restore(ast.RestoreDefault, &sb, nil); err != nil {
+			return nil, err
+		}
+		return conn.Exec(ctx, "", sb.String(), d.Args...)
+	}
+
+	// the physical database may be the default schema of pooled connections, so execute it without default schema
+	ds, ok := conn.(proto.DatabaseSupport)
+	if !ok {
+		return nil, errors.New("cannot create or drop the database of logical schema in transaction")
+	}
+
+	var (
+		mu       sync.Mutex
+		failures []string
+		g        errgroup.Group
+	)
+
+	for i := range d.Groups {
+		group := d.Groups[i]
+		g.Go(func() error {
+			name, err := ds.PhysicalDatabase(ctx, group)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			var sb strings.Builder
+			if err = d.write(name).Restore(ast.RestoreDefault, &sb, nil); err != nil {
+				return errors.WithStack(err)
+			}
+
+			res, err := ds.ExecWithoutSchema(ctx, group, sb.String())
+			if err != nil {
+				mu.Lock()
+				failures = append(failures, fmt.Sprintf("%s: %v", group, err))
+				mu.Unlock()
+				return nil
+			}
+			resultx.Drain(res)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return nil, errors.Errorf("failed to execute in %d of %d groups: %s",
+			len(failures), len(d.Groups), strings.Join(failures, "; "))
+	}
+
+	return resultx.New(), nil
+}
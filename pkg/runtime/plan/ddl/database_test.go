@@ -0,0 +1,113 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddl
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+)
+
+type fakeDatabaseConn struct {
+	proto.VConn
+	mu        sync.Mutex
+	databases map[string]string
+	execs     []string
+}
+
+func (f *fakeDatabaseConn) PhysicalDatabase(_ context.Context, group string) (string, error) {
+	return f.databases[group], nil
+}
+
+func (f *fakeDatabaseConn) ExecWithoutSchema(_ context.Context, group string, query string) (proto.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs = append(f.execs, group+": "+query)
+	return resultx.New(), nil
+}
+
+func TestDatabasePlan(t *testing.T) {
+	conn := &fakeDatabaseConn{
+		databases: map[string]string{
+			"employees_0000": "employees_db_0",
+			"employees_0001": "employees_db_1",
+		},
+	}
+
+	for _, it := range []struct {
+		sql    string
+		expect []string
+	}{
+		{
+			"create database if not exists employees",
+			[]string{
+				"employees_0000: CREATE DATABASE IF NOT EXISTS `employees_db_0`",
+				"employees_0001: CREATE DATABASE IF NOT EXISTS `employees_db_1`",
+			},
+		},
+		{
+			"drop database employees",
+			[]string{
+				"employees_0000: DROP DATABASE `employees_db_0`",
+				"employees_0001: DROP DATABASE `employees_db_1`",
+			},
+		},
+	} {
+		t.Run(it.sql, func(t *testing.T) {
+			conn.execs = nil
+
+			_, stmt, err := ast.Parse(it.sql)
+			assert.NoError(t, err)
+
+			var p *DatabasePlan
+			switch s := stmt.(type) {
+			case *ast.CreateDatabaseStatement:
+				p = NewCreateDatabasePlan(s)
+			case *ast.DropDatabaseStatement:
+				p = NewDropDatabasePlan(s)
+			}
+			p.Groups = []string{"employees_0000", "employees_0001"}
+
+			_, err = p.ExecIn(context.Background(), conn)
+			assert.NoError(t, err)
+
+			sort.Strings(conn.execs)
+			assert.Equal(t, it.expect, conn.execs)
+		})
+	}
+
+	t.Run("in transaction", func(t *testing.T) {
+		_, stmt, _ := ast.Parse("drop database employees")
+		p := NewDropDatabasePlan(stmt.(*ast.DropDatabaseStatement))
+		p.Groups = []string{"employees_0000"}
+
+		_, err := p.ExecIn(context.Background(), struct{ proto.VConn }{conn})
+		assert.Error(t, err)
+	})
+}
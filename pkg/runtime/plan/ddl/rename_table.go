@@ -0,0 +1,176 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+var _ proto.Plan = (*RenameTablePlan)(nil)
+
+// RenameJournal persists the pending renaming of physical tables, so that the renaming can be recovered
+// if the proxy is interrupted before all the physical tables are renamed and the sharding rule is updated.
+type RenameJournal interface {
+	// Save persists the pending renaming before any physical table is renamed.
+	Save(ctx context.Context, renames map[string][]*ast.TableToTable) error
+	// Clear removes the pending renaming after it's finished or rolled back.
+	Clear(ctx context.Context) error
+}
+
+// RenameTablePlan renames the tables, all the physical tables will be renamed if it's a sharding table,
+// or it will be renamed on the placed group if it's a single table, and then the sharding rule will be updated.
+type RenameTablePlan struct {
+	plan.BasePlan
+	stmt *ast.RenameTableStatement
+	// Renames is the physical renaming pairs of each database.
+	Renames map[string][]*ast.TableToTable
+	// UpdateRule updates the sharding rule after all the physical tables are renamed.
+	UpdateRule func(ctx context.Context) error
+	// Journal persists the pending renaming, it's optional.
+	Journal RenameJournal
+}
+
+func NewRenameTablePlan(stmt *ast.RenameTableStatement) *RenameTablePlan {
+	return &RenameTablePlan{stmt: stmt}
+}
+
+func (r *RenameTablePlan) Type() proto.PlanType {
+	return proto.PlanTypeExec
+}
+
+func (r *RenameTablePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "RenameTablePlan.ExecIn")
+	defer span.End()
+
+	if r.Renames == nil {
+		// non-sharding rename table
+		var sb strings.Builder
+		if err := r.stmt.Restore(ast.RestoreDefault, &sb, nil); err != nil {
+			return nil, err
+		}
+		return conn.Exec(ctx, "", sb.String(), r.Args...)
+	}
+
+	if r.Journal != nil {
+		if err := r.Journal.Save(ctx, r.Renames); err != nil {
+			return nil, errors.Wrap(err, "failed to persist pending renaming")
+		}
+	}
+
+	dbs := make([]string, 0, len(r.Renames))
+	for db := range r.Renames {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	// the renaming in one database is atomic, rename back the renamed databases if any of them failed
+	var renamed []string
+	for _, db := range dbs {
+		if err := r.rename(ctx, conn, db, r.Renames[db]); err != nil {
+			return nil, r.rollback(ctx, conn, renamed, errors.Wrapf(err, "failed to rename physical tables in %s", db))
+		}
+		renamed = append(renamed, db)
+	}
+
+	if r.UpdateRule != nil {
+		if err := r.UpdateRule(ctx); err != nil {
+			return nil, r.rollback(ctx, conn, renamed, errors.Wrap(err, "failed to update sharding rule"))
+		}
+	}
+
+	r.clearJournal(ctx)
+
+	return resultx.New(), nil
+}
+
+func (r *RenameTablePlan) rename(ctx context.Context, conn proto.VConn, db string, pairs []*ast.TableToTable) error {
+	var sb strings.Builder
+	if err := (&ast.RenameTableStatement{Tables: pairs}).Restore(ast.RestoreDefault, &sb, nil); err != nil {
+		return errors.WithStack(err)
+	}
+	res, err := conn.Exec(ctx, db, sb.String())
+	if err != nil {
+		return err
+	}
+	resultx.Drain(res)
+	return nil
+}
+
+// rollback renames back the renamed databases, returns the cause with the failures of rollback.
+// The journal will be kept if the rollback failed, so that it can be recovered later.
+func (r *RenameTablePlan) rollback(ctx context.Context, conn proto.VConn, dbs []string, cause error) error {
+	var failures []string
+	for _, db := range dbs {
+		if err := r.rename(ctx, conn, db, reverseRenames(r.Renames[db])); err != nil {
+			log.Errorf("failed to rename back physical tables in %s: %v", db, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", db, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Wrapf(cause, "failed to rename back physical tables (%s)", strings.Join(failures, "; "))
+	}
+	r.clearJournal(ctx)
+	return cause
+}
+
+func (r *RenameTablePlan) clearJournal(ctx context.Context) {
+	if r.Journal == nil {
+		return
+	}
+	if err := r.Journal.Clear(ctx); err != nil {
+		log.Errorf("failed to clear pending renaming: %v", err)
+	}
+}
+
+// RecoverRenames recovers the interrupted renaming of physical tables, the tables will be renamed forward
+// if the sharding rule has been updated, otherwise they will be renamed back. The renaming in each database
+// is atomic, so it fails without side effect in the databases which have been recovered already.
+func RecoverRenames(ctx context.Context, conn proto.VConn, renames map[string][]*ast.TableToTable, forward bool) {
+	r := &RenameTablePlan{}
+	for db, pairs := range renames {
+		if !forward {
+			pairs = reverseRenames(pairs)
+		}
+		if err := r.rename(ctx, conn, db, pairs); err != nil {
+			log.Warnf("skip recovering the renaming of physical tables in %s: %v", db, err)
+		}
+	}
+}
+
+func reverseRenames(pairs []*ast.TableToTable) []*ast.TableToTable {
+	ret := make([]*ast.TableToTable, 0, len(pairs))
+	for _, it := range pairs {
+		ret = append(ret, &ast.TableToTable{Old: it.New, New: it.Old})
+	}
+	return ret
+}
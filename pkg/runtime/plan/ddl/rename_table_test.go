@@ -0,0 +1,128 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ddl
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+)
+
+type fakeRenameConn struct {
+	proto.VConn
+	mu    sync.Mutex
+	fails map[string]bool
+	execs []string
+}
+
+func (f *fakeRenameConn) Exec(_ context.Context, db string, query string, _ ...proto.Value) (proto.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs = append(f.execs, db+": "+query)
+	if f.fails[db+": "+query] {
+		return nil, errors.Errorf("cannot execute %s", query)
+	}
+	return resultx.New(), nil
+}
+
+type fakeRenameJournal struct {
+	saved   map[string][]*ast.TableToTable
+	cleared bool
+}
+
+func (f *fakeRenameJournal) Save(_ context.Context, renames map[string][]*ast.TableToTable) error {
+	f.saved = renames
+	return nil
+}
+
+func (f *fakeRenameJournal) Clear(_ context.Context) error {
+	f.cleared = true
+	return nil
+}
+
+func TestRenameTablePlan(t *testing.T) {
+	const (
+		forward0  = "employees_0000: RENAME TABLE `student_0000` TO `pupil_0000`"
+		forward1  = "employees_0001: RENAME TABLE `student_0001` TO `pupil_0001`"
+		rollback0 = "employees_0000: RENAME TABLE `pupil_0000` TO `student_0000`"
+	)
+
+	newPlan := func() (*RenameTablePlan, *fakeRenameJournal) {
+		_, stmt, err := ast.Parse("rename table student to pupil")
+		assert.NoError(t, err)
+
+		renames := make(map[string][]*ast.TableToTable)
+		for _, it := range []string{"0000", "0001"} {
+			renames["employees_"+it] = []*ast.TableToTable{{
+				Old: ast.TableName{"student_" + it},
+				New: ast.TableName{"pupil_" + it},
+			}}
+		}
+
+		journal := &fakeRenameJournal{}
+		p := NewRenameTablePlan(stmt.(*ast.RenameTableStatement))
+		p.Renames = renames
+		p.Journal = journal
+		return p, journal
+	}
+
+	t.Run("success", func(t *testing.T) {
+		p, journal := newPlan()
+		conn := &fakeRenameConn{}
+		_, err := p.ExecIn(context.Background(), conn)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{forward0, forward1}, conn.execs)
+		assert.Len(t, journal.saved, 2)
+		assert.True(t, journal.cleared)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		p, journal := newPlan()
+		conn := &fakeRenameConn{fails: map[string]bool{forward1: true}}
+		_, err := p.ExecIn(context.Background(), conn)
+		assert.Error(t, err)
+		assert.Equal(t, []string{forward0, forward1, rollback0}, conn.execs)
+		assert.True(t, journal.cleared)
+	})
+
+	t.Run("rollback failed", func(t *testing.T) {
+		p, journal := newPlan()
+		conn := &fakeRenameConn{fails: map[string]bool{forward1: true, rollback0: true}}
+		_, err := p.ExecIn(context.Background(), conn)
+		assert.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "failed to rename back physical tables"))
+		assert.False(t, journal.cleared)
+
+		conn.fails, conn.execs = nil, nil
+		RecoverRenames(context.Background(), conn, journal.saved, false)
+		assert.Contains(t, conn.execs, rollback0)
+	})
+}
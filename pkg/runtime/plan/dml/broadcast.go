@@ -0,0 +1,106 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dml
+
+import (
+	"context"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+)
+
+var _ proto.Plan = (*BroadcastPlan)(nil)
+
+// BroadcastPlan represents a plan which writes a broadcast table, the statement will be executed
+// in all the databases inside a transaction, so that the replicas are always identical.
+type BroadcastPlan struct {
+	plan.BasePlan
+	stmt      ast.Statement
+	databases []string
+
+	lastInsertID uint64 // the first generated distributed primary key
+}
+
+// NewBroadcastPlan creates a plan which executes the statement in every database.
+func NewBroadcastPlan(stmt ast.Statement, databases []string) *BroadcastPlan {
+	return &BroadcastPlan{
+		stmt:      stmt,
+		databases: databases,
+	}
+}
+
+// SetLastInsertID sets the generated id, it will overwrite the last insert id returned by backend.
+func (bp *BroadcastPlan) SetLastInsertID(id uint64) {
+	bp.lastInsertID = id
+}
+
+func (bp *BroadcastPlan) Type() proto.PlanType {
+	return proto.PlanTypeExec
+}
+
+func (bp *BroadcastPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "BroadcastPlan.ExecIn")
+	defer span.End()
+
+	var (
+		sb      strings.Builder
+		indexes []int
+	)
+	if err := bp.stmt.Restore(ast.RestoreDefault, &sb, &indexes); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var (
+		query = sb.String()
+		args  = bp.ToArgs(indexes)
+	)
+
+	return execInTx(ctx, conn, func(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+		var affects, lastInsertID uint64
+		for i, db := range bp.databases {
+			res, err := conn.Exec(ctx, db, query, args...)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to write broadcast table in '%s'", db)
+			}
+			// every replica is identical, use the result of the first one
+			if i == 0 {
+				if affects, err = res.RowsAffected(); err != nil {
+					return nil, errors.WithStack(err)
+				}
+				if lastInsertID, err = res.LastInsertId(); err != nil {
+					return nil, errors.WithStack(err)
+				}
+			}
+			resultx.Drain(res)
+		}
+
+		if bp.lastInsertID > 0 {
+			lastInsertID = bp.lastInsertID
+		}
+
+		return resultx.New(resultx.WithLastInsertID(lastInsertID), resultx.WithRowsAffected(affects)), nil
+	})
+}
@@ -0,0 +1,82 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dml
+
+import (
+	"context"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/hint"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+)
+
+var _ proto.Plan = (*CallPlan)(nil)
+
+// CallPlan represents the plan of CALL statement, it will be executed on the master node of a group.
+// The result may contain multiple results, see proto.MultiResult.
+type CallPlan struct {
+	plan.BasePlan
+	stmt *ast.CallStatement
+	db   string
+}
+
+// NewCallPlan creates a CALL plan.
+func NewCallPlan(stmt *ast.CallStatement) *CallPlan {
+	return &CallPlan{stmt: stmt}
+}
+
+// SetDatabase sets the target group, empty means the default group.
+func (c *CallPlan) SetDatabase(db string) {
+	c.db = db
+}
+
+func (c *CallPlan) Type() proto.PlanType {
+	return proto.PlanTypeQuery
+}
+
+func (c *CallPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "CallPlan.ExecIn")
+	defer span.End()
+
+	var (
+		sb   strings.Builder
+		args []int
+	)
+
+	if err := c.stmt.Restore(ast.RestoreDefault, &sb, &args); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// A stored procedure may write data, so always route it to the master node.
+	// Use Query instead of Exec to keep all the results.
+	ctx = rcontext.WithHints(ctx, append(rcontext.Hints(ctx), &hint.Hint{Type: hint.TypeMaster}))
+
+	res, err := conn.Query(ctx, c.db, sb.String(), c.ToArgs(args)...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
@@ -0,0 +1,485 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml
+
+import (
+	"context"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+var (
+	_ proto.Plan = (*GlobalIndexWritePlan)(nil)
+	_ proto.Plan = (*GlobalIndexLookupPlan)(nil)
+)
+
+// KeysRouter computes the shards of base table by the values of sharding keys.
+type KeysRouter func(values []proto.Value) (rule.DatabaseTables, error)
+
+// GlobalIndexTable represents the index table of a global secondary index. Each entry of the index table is the
+// value of indexed column followed by the values of sharding keys, the columns of index table have the same names.
+//
+// NOTICE: the index table should have a unique key of all the columns, so the existing entries can be ignored.
+type GlobalIndexTable struct {
+	Column string    // the indexed column
+	Keys   []string  // the sharding keys of base table
+	Route  RowRouter // computes the physical index table by the value of indexed column
+}
+
+// Columns returns the columns of index table.
+func (gt *GlobalIndexTable) Columns() []string {
+	return append([]string{gt.Column}, gt.Keys...)
+}
+
+// Scan returns the distinct entries of the physical table, which can be either the base table or the index table.
+func (gt *GlobalIndexTable) Scan(ctx context.Context, conn proto.VConn, db, table string) ([][]proto.Value, error) {
+	var sb strings.Builder
+	writeSelectDistinct(&sb, gt.Columns(), table)
+	sb.WriteString(" WHERE ")
+	ast.WriteID(&sb, gt.Column)
+	sb.WriteString(" IS NOT NULL")
+
+	_, rows, err := queryRows(ctx, conn, db, sb.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Add writes the entries into the physical index tables, the existing entries will be ignored.
+func (gt *GlobalIndexTable) Add(ctx context.Context, conn proto.VConn, entries [][]proto.Value) (uint64, error) {
+	targets, err := gt.group(entries)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		affects uint64
+		columns = gt.Columns()
+		sb      strings.Builder
+	)
+	for _, it := range targets {
+		for i := 0; i < len(it.rows); i += DefaultInsertSelectBatchSize {
+			end := i + DefaultInsertSelectBatchSize
+			if end > len(it.rows) {
+				end = len(it.rows)
+			}
+
+			sb.Reset()
+			sb.WriteString("INSERT IGNORE INTO ")
+			ast.WriteID(&sb, it.table)
+			sb.WriteByte('(')
+			for j, column := range columns {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				ast.WriteID(&sb, column)
+			}
+			sb.WriteString(") VALUES ")
+
+			args := make([]proto.Value, 0, (end-i)*len(columns))
+			for j, row := range it.rows[i:end] {
+				if j > 0 {
+					sb.WriteByte(',')
+				}
+				sb.WriteByte('(')
+				for k := range row {
+					if k > 0 {
+						sb.WriteString(", ")
+					}
+					sb.WriteByte('?')
+				}
+				sb.WriteByte(')')
+				args = append(args, row...)
+			}
+
+			n, err := execAffected(ctx, conn, it.db, sb.String(), args)
+			if err != nil {
+				return 0, err
+			}
+			affects += n
+		}
+	}
+
+	return affects, nil
+}
+
+// Remove deletes the entries from the physical index tables.
+func (gt *GlobalIndexTable) Remove(ctx context.Context, conn proto.VConn, entries [][]proto.Value) (uint64, error) {
+	targets, err := gt.group(entries)
+	if err != nil {
+		return 0, err
+	}
+
+	var affects uint64
+	for _, it := range targets {
+		n, err := gt.RemoveFrom(ctx, conn, it.db, it.table, it.rows)
+		if err != nil {
+			return 0, err
+		}
+		affects += n
+	}
+	return affects, nil
+}
+
+// RemoveFrom deletes the entries from the given physical index table.
+func (gt *GlobalIndexTable) RemoveFrom(ctx context.Context, conn proto.VConn, db, table string, entries [][]proto.Value) (uint64, error) {
+	var (
+		affects uint64
+		columns = gt.Columns()
+		sb      strings.Builder
+	)
+	for i := 0; i < len(entries); i += DefaultInsertSelectBatchSize {
+		end := i + DefaultInsertSelectBatchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		sb.Reset()
+		sb.WriteString("DELETE FROM ")
+		ast.WriteID(&sb, table)
+		sb.WriteString(" WHERE ")
+		args := make([]proto.Value, 0, (end-i)*len(columns))
+		writeKeysIn(&sb, columns, end-i, func(row, col int) {
+			sb.WriteByte('?')
+			args = append(args, entries[i+row][col])
+		})
+
+		n, err := execAffected(ctx, conn, db, sb.String(), args)
+		if err != nil {
+			return 0, err
+		}
+		affects += n
+	}
+	return affects, nil
+}
+
+// group groups the entries by the physical index tables.
+func (gt *GlobalIndexTable) group(entries [][]proto.Value) ([]*physicalRows, error) {
+	var (
+		targets []*physicalRows
+		indexes = make(map[string]*physicalRows)
+	)
+	for _, entry := range entries {
+		db, table, err := gt.Route(entry[:1])
+		if err != nil {
+			return nil, err
+		}
+		key := db + "." + table
+		exist, ok := indexes[key]
+		if !ok {
+			exist = &physicalRows{db: db, table: table}
+			indexes[key] = exist
+			targets = append(targets, exist)
+		}
+		exist.rows = append(exist.rows, entry)
+	}
+	return targets, nil
+}
+
+// GlobalIndexScope is a physical table of base table which will be written.
+type GlobalIndexScope struct {
+	DB, Table string
+	Keys      [][]proto.Value // the values of sharding keys of the inserted rows, nil if the rows are updated or deleted
+}
+
+// GlobalIndexWritePlan executes the plan which writes the base table, then synchronizes the entries of global
+// secondary indexes in the same transaction. The entries of written rows are read before and after writing,
+// the disappeared entries will be removed and the appeared entries will be added.
+type GlobalIndexWritePlan struct {
+	plan.BasePlan
+	Plan    proto.Plan          // the plan which writes the base table
+	Where   ast.ExpressionNode  // the condition of the updated or deleted rows, nil means all rows
+	Scopes  []*GlobalIndexScope // the physical tables to be written
+	Indexes []*GlobalIndexTable
+}
+
+func (gp *GlobalIndexWritePlan) Type() proto.PlanType {
+	return proto.PlanTypeExec
+}
+
+func (gp *GlobalIndexWritePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "GlobalIndexWritePlan.ExecIn")
+	defer span.End()
+
+	return execInTx(ctx, conn, gp.execIn)
+}
+
+func (gp *GlobalIndexWritePlan) execIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	var (
+		where     strings.Builder
+		whereArgs []int
+	)
+	if gp.Where != nil {
+		if err := gp.Where.Restore(ast.RestoreDefault, &where, &whereArgs); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	// 1. lock the rows to be written and remember their entries
+	before := make([][][]proto.Value, len(gp.Scopes))
+	for i, it := range gp.Scopes {
+		var (
+			rows [][]proto.Value
+			err  error
+		)
+		if it.Keys != nil { // the existing rows which have the same sharding keys as the inserted rows
+			cond, args := gp.keysIn(distinctRows(it.Keys, 0))
+			rows, err = gp.selectEntries(ctx, conn, it.DB, it.Table, cond, args, true)
+		} else {
+			rows, err = gp.selectEntries(ctx, conn, it.DB, it.Table, where.String(), gp.ToArgs(whereArgs), true)
+		}
+		if err != nil {
+			return nil, err
+		}
+		before[i] = rows
+	}
+
+	// 2. write the base table
+	res, err := gp.Plan.ExecIn(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. synchronize the entries of the rows which have the same sharding keys
+	for i, it := range gp.Scopes {
+		if err = gp.sync(ctx, conn, it, before[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+func (gp *GlobalIndexWritePlan) sync(ctx context.Context, conn proto.VConn, scope *GlobalIndexScope, before [][]proto.Value) error {
+	offset := len(gp.Indexes)
+	keys := make([][]proto.Value, 0, len(scope.Keys)+len(before))
+	keys = append(keys, scope.Keys...)
+	keys = distinctRows(append(keys, distinctRows(before, offset)...), 0)
+	if len(keys) < 1 {
+		return nil
+	}
+
+	cond, args := gp.keysIn(keys)
+	after, err := gp.selectEntries(ctx, conn, scope.DB, scope.Table, cond, args, false)
+	if err != nil {
+		return err
+	}
+
+	for i, index := range gp.Indexes {
+		var (
+			prev = entriesOf(before, i, offset)
+			next = entriesOf(after, i, offset)
+		)
+		removed, err := index.Remove(ctx, conn, subtractRows(prev, next))
+		if err != nil {
+			return err
+		}
+		added, err := index.Add(ctx, conn, subtractRows(next, prev))
+		if err != nil {
+			return err
+		}
+		log.Debugf("sync global index of %s.%s: column=%s, added=%d, removed=%d", scope.DB, scope.Table, index.Column, added, removed)
+	}
+
+	return nil
+}
+
+// keysIn returns the condition of sharding keys, eg: `uid` IN (?,?)
+func (gp *GlobalIndexWritePlan) keysIn(keys [][]proto.Value) (string, []proto.Value) {
+	var (
+		sb   strings.Builder
+		args = make([]proto.Value, 0, len(keys)*len(gp.keys()))
+	)
+	writeKeysIn(&sb, gp.keys(), len(keys), func(row, col int) {
+		sb.WriteByte('?')
+		args = append(args, keys[row][col])
+	})
+	return sb.String(), args
+}
+
+// selectEntries selects the indexed columns followed by the sharding keys from the physical table.
+func (gp *GlobalIndexWritePlan) selectEntries(ctx context.Context, conn proto.VConn, db, table, cond string, args []proto.Value, lock bool) ([][]proto.Value, error) {
+	columns := make([]string, 0, len(gp.Indexes)+len(gp.keys()))
+	for _, it := range gp.Indexes {
+		columns = append(columns, it.Column)
+	}
+	columns = append(columns, gp.keys()...)
+
+	var sb strings.Builder
+	writeSelectDistinct(&sb, columns, table)
+	if len(cond) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(cond)
+	}
+	if lock {
+		sb.WriteString(" FOR UPDATE")
+	}
+
+	_, rows, err := queryRows(ctx, conn, db, sb.String(), args)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (gp *GlobalIndexWritePlan) keys() []string {
+	return gp.Indexes[0].Keys
+}
+
+// GlobalIndexLookupPlan looks up the sharding keys of rows in the global secondary index first, then executes the
+// plan which is built with the shards computed from the sharding keys, so that it won't be a full-scan.
+type GlobalIndexLookupPlan struct {
+	plan.BasePlan
+	PlanType proto.PlanType
+	Shards   rule.DatabaseTables // the physical index tables to be looked up
+	Filter   ast.ExpressionNode  // the condition of indexed column, eg: order_no = ?
+	Keys     []string            // the sharding keys of base table
+	Route    KeysRouter
+	Build    func(ctx context.Context, shards rule.DatabaseTables) (proto.Plan, error)
+}
+
+func (gp *GlobalIndexLookupPlan) Type() proto.PlanType {
+	return gp.PlanType
+}
+
+func (gp *GlobalIndexLookupPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "GlobalIndexLookupPlan.ExecIn")
+	defer span.End()
+
+	var (
+		cond strings.Builder
+		args []int
+	)
+	if err := gp.Filter.Restore(ast.RestoreDefault, &cond, &args); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	shards := make(rule.DatabaseTables)
+	for db, tables := range gp.Shards {
+		for _, table := range tables {
+			var sb strings.Builder
+			writeSelectDistinct(&sb, gp.Keys, table)
+			sb.WriteString(" WHERE ")
+			sb.WriteString(cond.String())
+
+			_, rows, err := queryRows(ctx, conn, db, sb.String(), gp.ToArgs(args))
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				next, err := gp.Route(row)
+				if err != nil {
+					return nil, err
+				}
+				shards = shards.Or(next)
+			}
+		}
+	}
+
+	log.Debugf("lookup global index: filter=%s, shards=%s", cond.String(), shards)
+
+	p, err := gp.Build(ctx, shards)
+	if err != nil {
+		return nil, err
+	}
+	return p.ExecIn(ctx, conn)
+}
+
+func writeSelectDistinct(sb *strings.Builder, columns []string, table string) {
+	sb.WriteString("SELECT DISTINCT ")
+	for i, column := range columns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		ast.WriteID(sb, column)
+	}
+	sb.WriteString(" FROM ")
+	ast.WriteID(sb, table)
+}
+
+// entriesOf returns the distinct entries of the i-th index, the sharding keys start from offset of each row.
+func entriesOf(rows [][]proto.Value, i, offset int) [][]proto.Value {
+	ret := make([][]proto.Value, 0, len(rows))
+	for _, row := range rows {
+		if row[i] == nil {
+			continue
+		}
+		entry := make([]proto.Value, 0, 1+len(row)-offset)
+		entry = append(entry, row[i])
+		entry = append(entry, row[offset:]...)
+		ret = append(ret, entry)
+	}
+	return distinctRows(ret, 0)
+}
+
+// distinctRows returns the distinct rows which start from offset.
+func distinctRows(rows [][]proto.Value, offset int) [][]proto.Value {
+	var (
+		ret     = make([][]proto.Value, 0, len(rows))
+		visited = make(map[string]struct{}, len(rows))
+	)
+	for _, row := range rows {
+		key := rowKey(row[offset:])
+		if _, ok := visited[key]; ok {
+			continue
+		}
+		visited[key] = struct{}{}
+		ret = append(ret, row[offset:])
+	}
+	return ret
+}
+
+// subtractRows returns the rows of a which are not in b.
+func subtractRows(a, b [][]proto.Value) [][]proto.Value {
+	exists := make(map[string]struct{}, len(b))
+	for _, row := range b {
+		exists[rowKey(row)] = struct{}{}
+	}
+	var ret [][]proto.Value
+	for _, row := range a {
+		if _, ok := exists[rowKey(row)]; !ok {
+			ret = append(ret, row)
+		}
+	}
+	return ret
+}
+
+func rowKey(row []proto.Value) string {
+	var sb strings.Builder
+	for _, it := range row {
+		if it == nil {
+			sb.WriteString("\x01")
+		} else {
+			sb.WriteString(it.String())
+		}
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
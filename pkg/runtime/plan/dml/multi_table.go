@@ -0,0 +1,237 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml
+
+import (
+	"context"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+var (
+	_ proto.Plan = (*ColocatedWritePlan)(nil)
+	_ proto.Plan = (*JoinedWritePlan)(nil)
+)
+
+// ColocatedWritePlan executes multi-table UPDATE/DELETE whose tables are co-located, the rows to be joined are
+// always stored in the same shard, so the statement can be executed on each shard directly.
+type ColocatedWritePlan struct {
+	plan.BasePlan
+	Shards rule.DatabaseTables // the physical tables of the left table
+	// Write creates the statement for the physical table of the left table.
+	Write func(table string) ast.Statement
+}
+
+func (cp *ColocatedWritePlan) Type() proto.PlanType {
+	return proto.PlanTypeExec
+}
+
+func (cp *ColocatedWritePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "ColocatedWritePlan.ExecIn")
+	defer span.End()
+
+	// the statements of all shards are committed or rolled back together
+	return execInTx(ctx, conn, cp.execIn)
+}
+
+func (cp *ColocatedWritePlan) execIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	var (
+		sb      strings.Builder
+		indexes []int
+		affects uint64
+	)
+
+	for db, tables := range cp.Shards {
+		for _, table := range tables {
+			if err := cp.Write(table).Restore(ast.RestoreDefault, &sb, &indexes); err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			n, err := execAffected(ctx, conn, db, sb.String(), cp.ToArgs(indexes))
+			if err != nil {
+				return nil, err
+			}
+			affects += n
+
+			// cleanup
+			if len(indexes) > 0 {
+				indexes = indexes[:0]
+			}
+			sb.Reset()
+		}
+	}
+
+	return resultx.New(resultx.WithRowsAffected(affects)), nil
+}
+
+// JoinedWritePlan executes multi-table UPDATE/DELETE whose tables are not co-located. The target rows are selected
+// by the join first, then they will be updated or deleted by the primary keys in the physical tables which own them.
+type JoinedWritePlan struct {
+	plan.BasePlan
+	// Select selects the primary keys, the sharding keys and the updated values of the target rows in order.
+	Select      proto.Plan
+	PrimaryKeys []string
+	ShardKeys   int // the count of sharding keys
+	Route       RowRouter
+	// Updated is the updated columns, the rows will be deleted if it's empty.
+	Updated []string
+}
+
+func (jp *JoinedWritePlan) Type() proto.PlanType {
+	return proto.PlanTypeExec
+}
+
+func (jp *JoinedWritePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "JoinedWritePlan.ExecIn")
+	defer span.End()
+
+	// the selected rows are locked until the statements are executed
+	return execInTx(ctx, conn, jp.execIn)
+}
+
+func (jp *JoinedWritePlan) execIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	res, err := jp.Select.ExecIn(ctx, conn)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	_, rows, err := readRows(res)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		batches []*physicalRows
+		indexes = make(map[string]*physicalRows)
+		visited = make(map[string]struct{})
+		width   = len(jp.PrimaryKeys)
+		keys    = width + jp.ShardKeys
+		sb      strings.Builder
+	)
+	for _, row := range rows {
+		if len(row) != keys+len(jp.Updated) {
+			return nil, errors.Errorf("column count doesn't match: expect %d, actual %d", keys+len(jp.Updated), len(row))
+		}
+
+		// a row may be joined many times, but it should be written only once like MySQL
+		sb.Reset()
+		for _, it := range row[:keys] {
+			if it != nil {
+				sb.WriteString(it.String())
+			}
+			sb.WriteByte(0)
+		}
+		if _, ok := visited[sb.String()]; ok {
+			continue
+		}
+		visited[sb.String()] = struct{}{}
+
+		db, table, err := jp.Route(row[width:keys])
+		if err != nil {
+			return nil, err
+		}
+
+		key := db + "." + table
+		exist, ok := indexes[key]
+		if !ok {
+			exist = &physicalRows{db: db, table: table}
+			indexes[key] = exist
+			batches = append(batches, exist)
+		}
+		exist.rows = append(exist.rows, row)
+	}
+
+	var affects uint64
+	for _, it := range batches {
+		var n uint64
+		if len(jp.Updated) > 0 {
+			n, err = jp.update(ctx, conn, it)
+		} else {
+			n, err = jp.delete(ctx, conn, it)
+		}
+		if err != nil {
+			return nil, err
+		}
+		affects += n
+	}
+
+	log.Debugf("joined write success: rows=%d, batch=%d, affects=%d", len(rows), len(batches), affects)
+
+	return resultx.New(resultx.WithRowsAffected(affects)), nil
+}
+
+// update updates the rows one by one, since the updated values may be different for each row.
+func (jp *JoinedWritePlan) update(ctx context.Context, conn proto.VConn, pr *physicalRows) (uint64, error) {
+	var (
+		sb      strings.Builder
+		affects uint64
+		keys    = len(jp.PrimaryKeys) + jp.ShardKeys
+	)
+	for _, row := range pr.rows {
+		sb.Reset()
+		sb.WriteString("UPDATE ")
+		ast.WriteID(&sb, pr.table)
+		sb.WriteString(" SET ")
+		args := make([]proto.Value, 0, len(row))
+		for i, column := range jp.Updated {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			ast.WriteID(&sb, column)
+			sb.WriteString(" = ?")
+			args = append(args, row[keys+i])
+		}
+		sb.WriteString(" WHERE ")
+		writeKeysIn(&sb, jp.PrimaryKeys, 1, func(_, j int) {
+			sb.WriteByte('?')
+			args = append(args, row[j])
+		})
+
+		n, err := execAffected(ctx, conn, pr.db, sb.String(), args)
+		if err != nil {
+			return 0, err
+		}
+		affects += n
+	}
+	return affects, nil
+}
+
+func (jp *JoinedWritePlan) delete(ctx context.Context, conn proto.VConn, pr *physicalRows) (uint64, error) {
+	var sb strings.Builder
+	sb.WriteString("DELETE FROM ")
+	ast.WriteID(&sb, pr.table)
+	sb.WriteString(" WHERE ")
+	args := make([]proto.Value, 0, len(pr.rows)*len(jp.PrimaryKeys))
+	writeKeysIn(&sb, jp.PrimaryKeys, len(pr.rows), func(i, j int) {
+		sb.WriteByte('?')
+		args = append(args, pr.rows[i][j])
+	})
+	return execAffected(ctx, conn, pr.db, sb.String(), args)
+}
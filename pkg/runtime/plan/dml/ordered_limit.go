@@ -0,0 +1,140 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml
+
+import (
+	"context"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+var _ proto.Plan = (*OrderedLimitPlan)(nil)
+
+// OrderedLimitPlan executes UPDATE/DELETE with ORDER BY and LIMIT on multiple shards, just like single-node MySQL.
+// The primary keys of the global top-n rows are selected by the merge-ordered select plan first, then the statement
+// will be executed on the physical tables which own the rows, the ORDER BY and LIMIT are replaced by the primary keys.
+type OrderedLimitPlan struct {
+	plan.BasePlan
+	// Select selects the primary keys and the sharding keys of the global top-n rows, the sharding keys follow the primary keys.
+	Select      proto.Plan
+	PrimaryKeys []string
+	Route       RowRouter
+	// Write creates the statement for the physical table, without WHERE, ORDER BY and LIMIT.
+	Write func(table string) ast.Statement
+	Where ast.ExpressionNode
+}
+
+func (op *OrderedLimitPlan) Type() proto.PlanType {
+	return proto.PlanTypeExec
+}
+
+func (op *OrderedLimitPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "OrderedLimitPlan.ExecIn")
+	defer span.End()
+
+	// the selected rows are locked until the statements are executed
+	return execInTx(ctx, conn, op.execIn)
+}
+
+func (op *OrderedLimitPlan) execIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	res, err := op.Select.ExecIn(ctx, conn)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	_, rows, err := readRows(res)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		batches []*physicalRows
+		indexes = make(map[string]*physicalRows)
+		width   = len(op.PrimaryKeys)
+	)
+	for _, row := range rows {
+		if len(row) <= width {
+			return nil, errors.Errorf("column count doesn't match: cannot find sharding keys from #%d", width+1)
+		}
+		db, table, err := op.Route(row[width:])
+		if err != nil {
+			return nil, err
+		}
+
+		key := db + "." + table
+		exist, ok := indexes[key]
+		if !ok {
+			exist = &physicalRows{db: db, table: table}
+			indexes[key] = exist
+			batches = append(batches, exist)
+		}
+		exist.rows = append(exist.rows, row[:width])
+	}
+
+	var affects uint64
+	for _, it := range batches {
+		n, err := op.execOne(ctx, conn, it.db, it.table, it.rows)
+		if err != nil {
+			return nil, err
+		}
+		affects += n
+	}
+
+	log.Debugf("ordered-limit statement success: rows=%d, batch=%d, affects=%d", len(rows), len(batches), affects)
+
+	return resultx.New(resultx.WithRowsAffected(affects)), nil
+}
+
+func (op *OrderedLimitPlan) execOne(ctx context.Context, conn proto.VConn, db, table string, keys [][]proto.Value) (uint64, error) {
+	var (
+		sb      strings.Builder
+		indexes []int
+	)
+
+	if err := op.Write(table).Restore(ast.RestoreDefault, &sb, &indexes); err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	sb.WriteString(" WHERE ")
+	if op.Where != nil {
+		sb.WriteByte('(')
+		if err := op.Where.Restore(ast.RestoreDefault, &sb, &indexes); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		sb.WriteString(") AND ")
+	}
+
+	args := op.ToArgs(indexes)
+	writeKeysIn(&sb, op.PrimaryKeys, len(keys), func(i, j int) {
+		sb.WriteByte('?')
+		args = append(args, keys[i][j])
+	})
+
+	return execAffected(ctx, conn, db, sb.String(), args)
+}
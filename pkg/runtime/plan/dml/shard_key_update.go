@@ -0,0 +1,419 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+var _ proto.Plan = (*ShardKeyUpdatePlan)(nil)
+
+// ShardKeyMover moves the rows whose sharding key has been changed into the right physical tables.
+type ShardKeyMover struct {
+	PrimaryKeys []string  // primary key columns which identify a row
+	ShardKeys   []string  // the sharding key columns
+	Route       RowRouter // computes the right physical table by the values of sharding keys
+}
+
+// Move checks the rows matched by the condition in the physical table, the misplaced rows will be
+// inserted into the right physical tables and deleted from the current one.
+// It should be called in a transaction.
+func (m *ShardKeyMover) Move(ctx context.Context, conn proto.VConn, db, table, cond string, args []proto.Value) error {
+	var sb strings.Builder
+	sb.WriteString("SELECT * FROM ")
+	ast.WriteID(&sb, table)
+	sb.WriteString(" WHERE ")
+	sb.WriteString(cond)
+	sb.WriteString(" FOR UPDATE")
+
+	columns, rows, err := queryRows(ctx, conn, db, sb.String(), args)
+	if err != nil {
+		return err
+	}
+
+	var (
+		shardKeys   = columnIndexes(columns, m.ShardKeys)
+		primaryKeys = columnIndexes(columns, m.PrimaryKeys)
+	)
+	if len(shardKeys) != len(m.ShardKeys) || len(primaryKeys) != len(m.PrimaryKeys) {
+		return errors.Errorf("cannot move rows of %s.%s: missing sharding key or primary key", db, table)
+	}
+
+	var (
+		targets  []*physicalRows
+		indexes  = make(map[string]*physicalRows)
+		misplace [][]proto.Value
+	)
+	for _, row := range rows {
+		keys := make([]proto.Value, 0, len(shardKeys))
+		for _, i := range shardKeys {
+			keys = append(keys, row[i])
+		}
+		nextDB, nextTable, err := m.Route(keys)
+		if err != nil {
+			return err
+		}
+		if nextDB == db && nextTable == table {
+			continue
+		}
+
+		key := nextDB + "." + nextTable
+		exist, ok := indexes[key]
+		if !ok {
+			exist = &physicalRows{db: nextDB, table: nextTable}
+			indexes[key] = exist
+			targets = append(targets, exist)
+		}
+		exist.rows = append(exist.rows, row)
+		misplace = append(misplace, row)
+	}
+
+	if len(misplace) < 1 {
+		return nil
+	}
+
+	// delete first, in case of the unique keys conflict
+	sb.Reset()
+	sb.WriteString("DELETE FROM ")
+	ast.WriteID(&sb, table)
+	sb.WriteString(" WHERE ")
+	deleteArgs := make([]proto.Value, 0, len(misplace)*len(primaryKeys))
+	writeKeysIn(&sb, m.PrimaryKeys, len(misplace), func(i, j int) {
+		sb.WriteByte('?')
+		deleteArgs = append(deleteArgs, misplace[i][primaryKeys[j]])
+	})
+	if _, err = execAffected(ctx, conn, db, sb.String(), deleteArgs); err != nil {
+		return err
+	}
+
+	for _, it := range targets {
+		sb.Reset()
+		sb.WriteString("INSERT INTO ")
+		ast.WriteID(&sb, it.table)
+		sb.WriteByte('(')
+		for i, column := range columns {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			ast.WriteID(&sb, column)
+		}
+		sb.WriteString(") VALUES ")
+
+		insertArgs := make([]proto.Value, 0, len(it.rows)*len(columns))
+		for i, row := range it.rows {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteByte('(')
+			for j := range row {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				sb.WriteByte('?')
+			}
+			sb.WriteByte(')')
+			insertArgs = append(insertArgs, row...)
+		}
+
+		if _, err = execAffected(ctx, conn, it.db, sb.String(), insertArgs); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("move %d rows from %s.%s since the sharding key changed", len(misplace), db, table)
+
+	return nil
+}
+
+// ShardKeyUpdatePlan represents a plan to execute sharding-update which changes the sharding key,
+// the updated rows will be moved into the right physical tables in a transaction.
+type ShardKeyUpdatePlan struct {
+	plan.BasePlan
+	stmt   *ast.UpdateStatement
+	shards rule.DatabaseTables
+	mover  *ShardKeyMover
+}
+
+// NewShardKeyUpdatePlan creates a sharding-update plan which changes the sharding key.
+func NewShardKeyUpdatePlan(stmt *ast.UpdateStatement, shards rule.DatabaseTables, mover *ShardKeyMover) *ShardKeyUpdatePlan {
+	return &ShardKeyUpdatePlan{
+		stmt:   stmt,
+		shards: shards,
+		mover:  mover,
+	}
+}
+
+func (up *ShardKeyUpdatePlan) Type() proto.PlanType {
+	return proto.PlanTypeExec
+}
+
+func (up *ShardKeyUpdatePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "ShardKeyUpdatePlan.ExecIn")
+	defer span.End()
+
+	return execInTx(ctx, conn, up.execIn)
+}
+
+func (up *ShardKeyUpdatePlan) execIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	// 1. lock the matched rows of all shards before any write, otherwise the rows moved into
+	// the physical table which is visited later will be matched and updated again.
+	var locked []*physicalRows
+	for db, tables := range up.shards {
+		for _, table := range tables {
+			keys, err := up.lockKeys(ctx, conn, db, table)
+			if err != nil {
+				return nil, err
+			}
+			if len(keys) > 0 {
+				locked = append(locked, &physicalRows{db: db, table: table, rows: keys})
+			}
+		}
+	}
+
+	// 2. update and move the locked rows only, the transaction holds one backend connection
+	// for each database, so execute them one by one
+	var affects uint64
+	for _, it := range locked {
+		n, err := up.updateOne(ctx, conn, it.db, it.table, it.rows)
+		if err != nil {
+			return nil, err
+		}
+		affects += n
+	}
+
+	log.Debugf("sharding update with sharding key changed success: affects=%d", affects)
+
+	return resultx.New(resultx.WithRowsAffected(affects)), nil
+}
+
+// lockKeys locks the rows matched by the update in the physical table, returns their primary keys.
+func (up *ShardKeyUpdatePlan) lockKeys(ctx context.Context, conn proto.VConn, db, table string) ([][]proto.Value, error) {
+	var (
+		sb   strings.Builder
+		args []int
+	)
+
+	sb.WriteString("SELECT ")
+	for i, pk := range up.mover.PrimaryKeys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		ast.WriteID(&sb, pk)
+	}
+	sb.WriteString(" FROM ")
+	ast.WriteID(&sb, table)
+	if up.stmt.Where != nil {
+		sb.WriteString(" WHERE ")
+		if err := up.stmt.Where.Restore(ast.RestoreDefault, &sb, &args); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if up.stmt.OrderBy != nil {
+		sb.WriteString(" ORDER BY ")
+		if err := up.stmt.OrderBy.Restore(ast.RestoreDefault, &sb, &args); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if up.stmt.Limit != nil {
+		sb.WriteString(" LIMIT ")
+		if err := up.stmt.Limit.Restore(ast.RestoreDefault, &sb, &args); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	sb.WriteString(" FOR UPDATE")
+
+	_, keys, err := queryRows(ctx, conn, db, sb.String(), up.ToArgs(args))
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// updateOne updates the locked rows in place, then moves them into the right physical tables.
+func (up *ShardKeyUpdatePlan) updateOne(ctx context.Context, conn proto.VConn, db, table string, keys [][]proto.Value) (uint64, error) {
+	var (
+		cond    strings.Builder
+		keyArgs = make([]proto.Value, 0, len(keys)*len(up.mover.PrimaryKeys))
+	)
+	writeKeysIn(&cond, up.mover.PrimaryKeys, len(keys), func(i, j int) {
+		cond.WriteByte('?')
+		keyArgs = append(keyArgs, keys[i][j])
+	})
+
+	// eg: UPDATE `student_0001` SET `uid` = ? WHERE (`uid` = ?) AND `id` IN (?,?)
+	var (
+		sb   strings.Builder
+		args []int
+	)
+	stmt := up.stmt.ResetTable(table)
+	stmt.Where, stmt.OrderBy, stmt.Limit = nil, nil, nil
+	if err := stmt.Restore(ast.RestoreDefault, &sb, &args); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	sb.WriteString(" WHERE ")
+	if up.stmt.Where != nil {
+		sb.WriteByte('(')
+		if err := up.stmt.Where.Restore(ast.RestoreDefault, &sb, &args); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		sb.WriteString(") AND ")
+	}
+	sb.WriteString(cond.String())
+
+	// the affected rows are same as MySQL
+	n, err := execAffected(ctx, conn, db, sb.String(), append(up.ToArgs(args), keyArgs...))
+	if err != nil {
+		return 0, err
+	}
+
+	if err = up.mover.Move(ctx, conn, db, table, cond.String(), keyArgs); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// columnIndexes returns the indexes of the given names in columns, the missing names are skipped.
+func columnIndexes(columns, names []string) []int {
+	ret := make([]int, 0, len(names))
+	for _, name := range names {
+		for i, column := range columns {
+			if strings.EqualFold(column, name) {
+				ret = append(ret, i)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// writeKeysIn writes the condition of keys, eg: '`id` IN (?,?)' or '(`a`, `b`) IN ((?, ?),(?, ?))'.
+func writeKeysIn(sb *strings.Builder, keys []string, rows int, writeValue func(row, col int)) {
+	if len(keys) == 1 {
+		ast.WriteID(sb, keys[0])
+		sb.WriteString(" IN (")
+		for i := 0; i < rows; i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			writeValue(i, 0)
+		}
+		sb.WriteByte(')')
+		return
+	}
+
+	sb.WriteByte('(')
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		ast.WriteID(sb, key)
+	}
+	sb.WriteString(") IN (")
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteByte('(')
+		for j := range keys {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			writeValue(i, j)
+		}
+		sb.WriteByte(')')
+	}
+	sb.WriteByte(')')
+}
+
+// queryRows reads all rows of the query, returns the column names and values.
+func queryRows(ctx context.Context, conn proto.VConn, db, query string, args []proto.Value) ([]string, [][]proto.Value, error) {
+	res, err := conn.Query(ctx, db, query, args...)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return readRows(res)
+}
+
+// readRows reads all rows of the result, returns the column names and values.
+func readRows(res proto.Result) ([]string, [][]proto.Value, error) {
+	ds, err := res.Dataset()
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	defer ds.Close()
+
+	fields, err := ds.Fields()
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	columns := make([]string, 0, len(fields))
+	for _, f := range fields {
+		columns = append(columns, f.Name())
+	}
+
+	var rows [][]proto.Value
+	for {
+		row, err := ds.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		values := make([]proto.Value, len(fields))
+		if err = row.Scan(values); err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		rows = append(rows, values)
+	}
+
+	return columns, rows, nil
+}
+
+func execAffected(ctx context.Context, conn proto.VConn, db, query string, args []proto.Value) (uint64, error) {
+	res, err := conn.Exec(ctx, db, query, args...)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	defer resultx.Drain(res)
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,327 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dml
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	errors2 "github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/plan"
+)
+
+const (
+	// DefaultInsertSelectBatchSize is the default count of rows written by one statement.
+	DefaultInsertSelectBatchSize = 256
+	// MaxInsertSelectBufferedRows is the max count of rows buffered when inserting in a transaction.
+	MaxInsertSelectBufferedRows = 100000
+)
+
+var _ proto.Plan = (*ShardedInsertSelectPlan)(nil)
+
+type (
+	// RowRouter computes the physical database and table of a row by the values of shard keys.
+	RowRouter func(values []proto.Value) (db, table string, err error)
+
+	// RowsWriter creates the statement which writes the values into the physical table, eg: INSERT or REPLACE.
+	RowsWriter func(table string, values [][]ast.ExpressionNode) ast.Statement
+)
+
+// ShardedInsertSelectPlan reads the rows of select as a stream, and writes them in batches into the physical tables
+// which are routed by the value of shard key.
+type ShardedInsertSelectPlan struct {
+	plan.BasePlan
+	Select    proto.Plan
+	Columns   []string // the written columns, including the auto-increment column if Sequence is set
+	ShardKeys []int    // indexes of shard keys in the selected columns, after the generated value appended
+	Route     RowRouter
+	Write     RowsWriter
+	BatchSize int
+	// Sequence generates the value of auto-increment column which will be appended to each row, optional.
+	Sequence proto.Sequence
+	// Atomic means all rows should be written in one transaction, a new transaction will be began if not in transaction.
+	Atomic bool
+	// Mover moves the rows whose sharding key changed by ON DUPLICATE KEY UPDATE, optional.
+	// The rows are always written in a transaction if it is set.
+	Mover *ShardKeyMover
+	// PrimaryKeys are the indexes of primary keys in the written columns, which locate the rows to be moved.
+	PrimaryKeys []int
+}
+
+func (sp *ShardedInsertSelectPlan) Type() proto.PlanType {
+	return proto.PlanTypeExec
+}
+
+func (sp *ShardedInsertSelectPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "ShardedInsertSelectPlan.ExecIn")
+	defer span.End()
+
+	if !sp.Atomic && sp.Mover == nil {
+		return sp.execIn(ctx, conn)
+	}
+
+	return execInTx(ctx, conn, sp.execIn)
+}
+
+func (sp *ShardedInsertSelectPlan) execIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	res, err := sp.Select.ExecIn(ctx, conn)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	ds, err := res.Dataset()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var closed bool
+	closeDataset := func() {
+		if !closed {
+			closed = true
+			_ = ds.Close()
+		}
+	}
+	defer closeDataset()
+
+	fields, err := ds.Fields()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	width := len(fields)
+	if sp.Sequence != nil {
+		width++
+	}
+	// all the selected rows have the same width, so check it before any row is written
+	if width != len(sp.Columns) {
+		return nil, errors2.NewSQLError(consts.ERWrongValueCountOnRow, consts.SSWrongValueCountOnRow,
+			"Column count doesn't match value count at row 1")
+	}
+
+	batchSize := sp.BatchSize
+	if batchSize < 1 {
+		batchSize = DefaultInsertSelectBatchSize
+	}
+
+	// In a transaction, the backend connection of each group is shared by reading and writing, so the rows have to
+	// be buffered until the select result is consumed completely. Otherwise, each batch is written once it's full.
+	_, inTx := conn.(proto.Tx)
+
+	var (
+		w = &insertSelectWriter{
+			plan:      sp,
+			conn:      conn,
+			batchSize: batchSize,
+		}
+		targets     []*physicalRows
+		indexes     = make(map[string]*physicalRows)
+		buffered    int
+		generatedID uint64
+	)
+	for {
+		row, err := ds.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		values := make([]proto.Value, width)
+		if err = row.Scan(values[:len(fields)]); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if sp.Sequence != nil {
+			id, err := sp.Sequence.Acquire(ctx)
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot generate auto-increment value")
+			}
+			values[len(fields)] = proto.NewValueInt64(id)
+			if generatedID == 0 {
+				generatedID = uint64(id)
+			}
+		}
+
+		keys := make([]proto.Value, 0, len(sp.ShardKeys))
+		for _, i := range sp.ShardKeys {
+			keys = append(keys, values[i])
+		}
+		db, table, err := sp.Route(keys)
+		if err != nil {
+			return nil, err
+		}
+
+		key := db + "." + table
+		exist, ok := indexes[key]
+		if !ok {
+			exist = &physicalRows{db: db, table: table}
+			indexes[key] = exist
+			targets = append(targets, exist)
+		}
+		exist.rows = append(exist.rows, values)
+
+		if !inTx {
+			if len(exist.rows) >= batchSize {
+				if err = w.flush(ctx, exist); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if buffered++; buffered > MaxInsertSelectBufferedRows {
+			return nil, errors.Errorf("too many rows to insert in a transaction: the limit is %d, please insert them without transaction", MaxInsertSelectBufferedRows)
+		}
+	}
+
+	closeDataset()
+
+	for _, it := range targets {
+		if err = w.flush(ctx, it); err != nil {
+			return nil, err
+		}
+	}
+
+	lastInsertId := w.lastInsertId
+	if generatedID > 0 {
+		lastInsertId = generatedID
+	}
+
+	return resultx.New(resultx.WithLastInsertID(lastInsertId), resultx.WithRowsAffected(w.affects)), nil
+}
+
+type physicalRows struct {
+	db, table string
+	rows      [][]proto.Value
+}
+
+// insertSelectWriter writes the buffered rows in batches.
+type insertSelectWriter struct {
+	plan         *ShardedInsertSelectPlan
+	conn         proto.VConn
+	batchSize    int
+	affects      uint64
+	lastInsertId uint64
+}
+
+// flush writes all the rows of the physical table, the rows will be released.
+func (w *insertSelectWriter) flush(ctx context.Context, target *physicalRows) error {
+	for i := 0; i < len(target.rows); i += w.batchSize {
+		end := i + w.batchSize
+		if end > len(target.rows) {
+			end = len(target.rows)
+		}
+		id, affected, err := w.plan.write(ctx, w.conn, target.db, target.table, target.rows[i:end])
+		if err != nil {
+			return err
+		}
+		w.affects += affected
+		if id > w.lastInsertId {
+			w.lastInsertId = id
+		}
+	}
+	target.rows = nil
+	return nil
+}
+
+func (sp *ShardedInsertSelectPlan) write(ctx context.Context, conn proto.VConn, db, table string, rows [][]proto.Value) (uint64, uint64, error) {
+	// the arguments of statement are kept ahead, eg: the placeholders in 'ON DUPLICATE KEY UPDATE'
+	args := make([]proto.Value, 0, len(sp.Args)+len(rows)*len(rows[0]))
+	args = append(args, sp.Args...)
+
+	values := make([][]ast.ExpressionNode, 0, len(rows))
+	for _, row := range rows {
+		next := make([]ast.ExpressionNode, 0, len(row))
+		for _, v := range row {
+			next = append(next, &ast.PredicateExpressionNode{
+				P: &ast.AtomPredicateNode{
+					A: ast.VariableExpressionAtom(len(args)),
+				},
+			})
+			args = append(args, v)
+		}
+		values = append(values, next)
+	}
+
+	var (
+		sb      strings.Builder
+		indexes []int
+	)
+	if err := sp.Write(table, values).Restore(ast.RestoreDefault, &sb, &indexes); err != nil {
+		return 0, 0, errors.Wrap(err, "cannot restore statement")
+	}
+
+	bound := make([]proto.Value, 0, len(indexes))
+	for _, idx := range indexes {
+		bound = append(bound, args[idx])
+	}
+
+	res, err := conn.Exec(ctx, db, sb.String(), bound...)
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+
+	defer resultx.Drain(res)
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+
+	if sp.Mover != nil {
+		if err = sp.move(ctx, conn, db, table, rows); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return id, affected, nil
+}
+
+// move moves the written rows if their sharding key has been changed by ON DUPLICATE KEY UPDATE,
+// the rows are located by the primary key values.
+func (sp *ShardedInsertSelectPlan) move(ctx context.Context, conn proto.VConn, db, table string, rows [][]proto.Value) error {
+	if len(sp.PrimaryKeys) != len(sp.Mover.PrimaryKeys) {
+		return errors.New("cannot move rows without primary key values")
+	}
+
+	var (
+		sb   strings.Builder
+		args = make([]proto.Value, 0, len(rows)*len(sp.PrimaryKeys))
+	)
+	writeKeysIn(&sb, sp.Mover.PrimaryKeys, len(rows), func(i, j int) {
+		sb.WriteByte('?')
+		args = append(args, rows[i][sp.PrimaryKeys[j]])
+	})
+
+	return sp.Mover.Move(ctx, conn, db, table, sb.String(), args)
+}
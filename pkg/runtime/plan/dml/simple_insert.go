@@ -37,12 +37,15 @@ var _ proto.Plan = (*SimpleInsertPlan)(nil)
 
 type SimpleInsertPlan struct {
 	plan.BasePlan
-	batch map[string][]*ast.InsertStatement // key=db
+	batch map[string][]ast.BaseInsertStatement // key=db, INSERT or REPLACE
+	mover *ShardKeyMover                       // moves the rows whose sharding key changed by ON DUPLICATE KEY UPDATE
+
+	lastInsertID uint64 // the first generated distributed primary key
 }
 
 func NewSimpleInsertPlan() *SimpleInsertPlan {
 	return &SimpleInsertPlan{
-		batch: make(map[string][]*ast.InsertStatement),
+		batch: make(map[string][]ast.BaseInsertStatement),
 	}
 }
 
@@ -50,17 +53,36 @@ func (sp *SimpleInsertPlan) Type() proto.PlanType {
 	return proto.PlanTypeExec
 }
 
-func (sp *SimpleInsertPlan) Put(db string, stmt *ast.InsertStatement) {
+func (sp *SimpleInsertPlan) Put(db string, stmt ast.BaseInsertStatement) {
 	sp.batch[db] = append(sp.batch[db], stmt)
 }
 
+// SetLastInsertID sets the generated id, it will overwrite the last insert id returned by backend.
+func (sp *SimpleInsertPlan) SetLastInsertID(id uint64) {
+	sp.lastInsertID = id
+}
+
+// SetShardKeyMover sets the mover, the rows updated by ON DUPLICATE KEY UPDATE will be moved into the
+// right physical tables in a transaction if the sharding key changed.
+func (sp *SimpleInsertPlan) SetShardKeyMover(mover *ShardKeyMover) {
+	sp.mover = mover
+}
+
 func (sp *SimpleInsertPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx, span := plan.Tracer.Start(ctx, "SimpleInsertPlan.ExecIn")
+	defer span.End()
+
+	if sp.mover != nil {
+		return execInTx(ctx, conn, sp.execIn)
+	}
+	return sp.execIn(ctx, conn)
+}
+
+func (sp *SimpleInsertPlan) execIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
 	var (
 		affects      uint64
 		lastInsertId uint64
 	)
-	ctx, span := plan.Tracer.Start(ctx, "SimpleInsertPlan.ExecIn")
-	defer span.End()
 	// TODO: consider wrap a transaction if insert into multiple databases
 	// TODO: insert in parallel
 	for db, inserts := range sp.batch {
@@ -69,6 +91,11 @@ func (sp *SimpleInsertPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto
 			if err != nil {
 				return nil, err
 			}
+			if sp.mover != nil {
+				if err = sp.move(ctx, conn, db, insert); err != nil {
+					return nil, err
+				}
+			}
 			affects += affected
 			if id > lastInsertId {
 				lastInsertId = id
@@ -76,10 +103,14 @@ func (sp *SimpleInsertPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto
 		}
 	}
 
+	if sp.lastInsertID > 0 {
+		lastInsertId = sp.lastInsertID
+	}
+
 	return resultx.New(resultx.WithLastInsertID(lastInsertId), resultx.WithRowsAffected(affects)), nil
 }
 
-func (sp *SimpleInsertPlan) doInsert(ctx context.Context, conn proto.VConn, db string, stmt *ast.InsertStatement) (uint64, uint64, error) {
+func (sp *SimpleInsertPlan) doInsert(ctx context.Context, conn proto.VConn, db string, stmt ast.BaseInsertStatement) (uint64, uint64, error) {
 	var (
 		sb   strings.Builder
 		args []int
@@ -107,3 +138,41 @@ func (sp *SimpleInsertPlan) doInsert(ctx context.Context, conn proto.VConn, db s
 
 	return id, affected, nil
 }
+
+// move moves the inserted rows if their sharding key has been changed by ON DUPLICATE KEY UPDATE,
+// the rows are located by the primary key values of insert statement.
+func (sp *SimpleInsertPlan) move(ctx context.Context, conn proto.VConn, db string, stmt ast.BaseInsertStatement) error {
+	insert, ok := stmt.(*ast.InsertStatement)
+	if !ok || len(insert.DuplicatedUpdates) < 1 {
+		return nil
+	}
+
+	primaryKeys := make([]int, 0, len(sp.mover.PrimaryKeys))
+	for _, pk := range sp.mover.PrimaryKeys {
+		for i, column := range insert.Columns {
+			if strings.EqualFold(column, pk) {
+				primaryKeys = append(primaryKeys, i)
+				break
+			}
+		}
+	}
+	if len(primaryKeys) != len(sp.mover.PrimaryKeys) {
+		return errors.New("cannot move rows without primary key values")
+	}
+
+	var (
+		sb   strings.Builder
+		args []int
+		err  error
+	)
+	writeKeysIn(&sb, sp.mover.PrimaryKeys, len(insert.Values), func(i, j int) {
+		if err == nil {
+			err = insert.Values[i][primaryKeys[j]].Restore(ast.RestoreDefault, &sb, &args)
+		}
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return sp.mover.Move(ctx, conn, db, insert.Table.Suffix(), sb.String(), sp.ToArgs(args))
+}
@@ -43,6 +43,8 @@ type SimpleQueryPlan struct {
 	Database string
 	Tables   []string
 	Stmt     *ast.SelectStatement
+	// Join is the join of physical tables which replaces the join of logical tables, eg: the co-located join.
+	Join *ast.JoinNode
 }
 
 func (s *SimpleQueryPlan) Type() proto.PlanType {
@@ -153,6 +155,19 @@ func (s *SimpleQueryPlan) resetTable(tgt *ast.SelectStatement, table string) err
 func (s *SimpleQueryPlan) generate(rf ast.RestoreFlag, sb *strings.Builder, args *[]int) error {
 	switch len(s.Tables) {
 	case 0:
+		if s.Join != nil {
+			// reset the joined tables
+			stmt := *s.Stmt
+			from := *s.Stmt.From[0]
+			if !from.ResetJoin(s.Join) {
+				return errors.New("cannot reset join for select statement")
+			}
+			stmt.From = []*ast.TableSourceNode{&from}
+			if err := stmt.Restore(rf, sb, args); err != nil {
+				return errors.WithStack(err)
+			}
+			return nil
+		}
 		// no table reset
 		if err := s.Stmt.Restore(rf, sb, args); err != nil {
 			return errors.WithStack(err)
@@ -0,0 +1,65 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dml
+
+import (
+	"context"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+// execInTx executes the function in a transaction, a new transaction will be began if the conn is not in transaction,
+// and it will be committed if the function succeeds, otherwise rolled back.
+func execInTx(ctx context.Context, conn proto.VConn, fn func(context.Context, proto.VConn) (proto.Result, error)) (proto.Result, error) {
+	if _, ok := conn.(proto.Tx); ok { // already in transaction
+		return fn(ctx, conn)
+	}
+
+	rt, ok := conn.(interface {
+		Begin(ctx context.Context) (proto.Tx, error)
+	})
+	if !ok {
+		return nil, errors.Errorf("cannot begin transaction on %T", conn)
+	}
+
+	tx, err := rt.Begin(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	res, err := fn(ctx, tx)
+	if err != nil {
+		if _, _, rbErr := tx.Rollback(ctx); rbErr != nil {
+			log.Errorf("failed to rollback %v: %v", tx, rbErr)
+		}
+		return nil, err
+	}
+
+	if _, _, err = tx.Commit(ctx); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return res, nil
+}
@@ -20,6 +20,7 @@ package rule
 import (
 	stdErrors "errors"
 	"fmt"
+	"strings"
 )
 
 import (
@@ -197,20 +198,36 @@ func (t *KeyedEvaluator) ToLogical() logical.Logical {
 }
 
 func (t *KeyedEvaluator) Eval(vt *rule.VTable) (*rule.Shards, error) {
-	var actualMetadata *rule.ShardMetadata
-
 	dbMetadata, tbMetadata, ok := vt.GetShardMetadata(t.k)
 	if !ok || (dbMetadata == nil && tbMetadata == nil) {
 		return nil, errors.Wrapf(ErrNoRuleMetadata, "cannot get rule metadata %s.%s", vt.Name(), t.k)
 	}
 
-	if dbMetadata == tbMetadata || tbMetadata != nil {
-		actualMetadata = tbMetadata
-	} else {
-		actualMetadata = dbMetadata
+	c := t.toComparative(rangeMetadata(dbMetadata, tbMetadata))
+
+	// compute the range directly, eg: range-map sharding
+	if c != nil {
+		var (
+			lower, upper interface{}
+			err          error
+		)
+		switch t.op {
+		case cmp.Cgt, cmp.Cgte:
+			lower, err = c.Value()
+		case cmp.Clt, cmp.Clte:
+			upper, err = c.Value()
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "eval failed:")
+		}
+		if lower != nil || upper != nil {
+			if shards, ok, err := matchRange(vt, t.k, lower, upper); err != nil || ok {
+				return shards, err
+			}
+		}
 	}
 
-	mat, err := Route(vt, t.toComparative(actualMetadata))
+	mat, err := Route(vt, c)
 	if err != nil {
 		return nil, err
 	}
@@ -250,6 +267,58 @@ func (t *KeyedEvaluator) Not() Evaluator {
 	return ret
 }
 
+// compositeEvaluator evaluates the equal comparisons of multiple sharding keys together, eg: a = 1 AND b = 2,
+// so that the composite sharding key can be computed when all of its columns are bound.
+type compositeEvaluator []*KeyedEvaluator
+
+func (c compositeEvaluator) Not() Evaluator {
+	return _noopEvaluator
+}
+
+func (c compositeEvaluator) String() string {
+	var sb strings.Builder
+	for i, it := range c {
+		if i > 0 {
+			sb.WriteString(" AND ")
+		}
+		sb.WriteString(it.String())
+	}
+	return sb.String()
+}
+
+func (c compositeEvaluator) Eval(vt *rule.VTable) (*rule.Shards, error) {
+	values := make(map[string]interface{}, len(c))
+	for _, it := range c {
+		dbMetadata, tbMetadata, _ := vt.GetShardMetadata(it.k)
+		c := it.toComparative(rangeMetadata(dbMetadata, tbMetadata))
+		if c == nil {
+			return nil, nil
+		}
+		value, err := c.Value()
+		if err != nil {
+			return nil, errors.Wrap(err, "eval failed:")
+		}
+		values[it.k] = value
+	}
+	return vt.ShardBy(values)
+}
+
+// and merges the equal comparison into current composite evaluator.
+func (c compositeEvaluator) and(next *KeyedEvaluator) Evaluator {
+	for _, it := range c {
+		if it.k != next.k {
+			continue
+		}
+		// a = 1 AND b = 2 AND a = 1 => a = 1 AND b = 2
+		if misc.Compare(it.v, next.v) == 0 {
+			return c
+		}
+		// a = 1 AND b = 2 AND a = 3 => always false
+		return _emptyEvaluator
+	}
+	return append(c[:len(c):len(c)], next)
+}
+
 func EvalWithVTable(l logical.Logical, vtab *rule.VTable) (Evaluator, error) {
 	ret, err := logical.Eval(l, func(a, b interface{}) (interface{}, error) {
 		x := a.(Evaluator)
@@ -352,7 +421,8 @@ func or(vt *rule.VTable, first, second Evaluator) (Evaluator, error) {
 		return nil, err
 	}
 
-	if v1 == nil && v2 == nil {
+	// full-scan if any of them is full-scan
+	if v1 == nil || v2 == nil {
 		return _noopEvaluator, nil
 	}
 
@@ -385,6 +455,31 @@ func processRange(vt *rule.VTable, begin, end *KeyedEvaluator) (Evaluator, error
 		return nil, errors.Errorf("no available rule metadata found: fields=[%s,%s]", begin.k, end.k)
 	}
 
+	// composite sharding key, use the other one or full-scan instead
+	if m1.IsComposite() || m2.IsComposite() {
+		if m1, m2 = rangeMetadata(dbm1, tbm1), rangeMetadata(dbm2, tbm2); m1 == nil || m2 == nil {
+			return _noopEvaluator, nil
+		}
+	}
+
+	// compute the range directly, eg: range-map sharding
+	lower, err := begin.toComparative(m1).Value()
+	if err != nil {
+		return nil, errors.Wrap(err, "eval failed:")
+	}
+	upper, err := end.toComparative(m2).Value()
+	if err != nil {
+		return nil, errors.Wrap(err, "eval failed:")
+	}
+	if shards, ok, err := matchRange(vt, begin.k, lower, upper); err != nil {
+		return nil, err
+	} else if ok {
+		if shards.Len() < 1 {
+			return _emptyEvaluator, nil
+		}
+		return (*staticEvaluator)(shards), nil
+	}
+
 	// string range is not available, use full-scan instead
 	if m1.Stepper.U == rule.Ustr && m2.Stepper.U == rule.Ustr {
 		return _noopEvaluator, nil
@@ -444,6 +539,24 @@ func and(vtab *rule.VTable, first, second Evaluator) (Evaluator, error) {
 	k1, ok1 := first.(*KeyedEvaluator)
 	k2, ok2 := second.(*KeyedEvaluator)
 
+	// merge the equal comparisons of sharding keys, eg: (a = 1 AND b = 2) AND c = 3
+	c1, ok3 := first.(compositeEvaluator)
+	c2, ok4 := second.(compositeEvaluator)
+	switch {
+	case ok3 && ok4:
+		var ret Evaluator = c1
+		for _, it := range c2 {
+			if ret = ret.(compositeEvaluator).and(it); ret == _emptyEvaluator {
+				break
+			}
+		}
+		return ret, nil
+	case ok3 && ok2 && k2.op == cmp.Ceq && vtab.HasColumn(k2.k):
+		return c1.and(k2), nil
+	case ok4 && ok1 && k1.op == cmp.Ceq && vtab.HasColumn(k1.k):
+		return c2.and(k1), nil
+	}
+
 	if ok1 && ok2 {
 		if k1.k == k2.k { // same key, handle comparison.
 			var rangeMode int8 // 0:
@@ -733,7 +846,11 @@ func and(vtab *rule.VTable, first, second Evaluator) (Evaluator, error) {
 				return processRange(vtab, k2, k1)
 			}
 		} else if vtab.HasColumn(k1.k) && vtab.HasColumn(k2.k) {
-			// SKIP: multiple sharding keys, goto slow path
+			// multiple sharding keys, compute them together, eg: the composite sharding key
+			if k1.op == cmp.Ceq && k2.op == cmp.Ceq {
+				return compositeEvaluator{k1, k2}, nil
+			}
+			// SKIP: goto slow path
 		} else if vtab.HasColumn(k1.k) {
 			return k1, nil
 		} else if vtab.HasColumn(k2.k) {
@@ -753,8 +870,13 @@ func and(vtab *rule.VTable, first, second Evaluator) (Evaluator, error) {
 		return nil, err
 	}
 
-	if v1 == nil && v2 == nil {
+	switch {
+	case v1 == nil && v2 == nil:
 		return _noopEvaluator, nil
+	case v1 == nil:
+		return (*staticEvaluator)(v2), nil
+	case v2 == nil:
+		return (*staticEvaluator)(v1), nil
 	}
 
 	merged := rule.IntersectionShards(v1, v2)
@@ -50,17 +50,21 @@ func (bem *baseExpMatcher) innerEval(c *cmp.Comparative) (rule.Range, error) {
 		return nil, errors.Wrap(err, "eval failed:")
 	}
 
-	var md *rule.ShardMetadata
-
-	if tbMetadata != nil {
-		md = tbMetadata
-	} else {
-		md = dbMetadata
-	}
+	md := rangeMetadata(dbMetadata, tbMetadata)
 
 	switch c.Comparison() {
 	case cmp.Ceq:
 		return Single(value), nil
+	case cmp.Cne:
+		return nil, nil
+	}
+
+	// the value range of composite sharding key cannot be enumerated by a single column
+	if md == nil {
+		return nil, nil
+	}
+
+	switch c.Comparison() {
 	case cmp.Cgt:
 		after, err := md.Stepper.After(value)
 		if err != nil {
@@ -77,8 +81,6 @@ func (bem *baseExpMatcher) innerEval(c *cmp.Comparative) (rule.Range, error) {
 		return md.Stepper.Descend(before, md.Steps)
 	case cmp.Clte:
 		return md.Stepper.Descend(value, md.Steps)
-	case cmp.Cne:
-		return nil, nil
 	default:
 		return nil, errors.Errorf("unsupported comparison %s", c.Comparison())
 	}
@@ -119,12 +121,81 @@ func MatchTables(vt *rule.VTable, column string, it rule.Range) (*rule.Shards, e
 
 	ret := rule.NewShards()
 	for _, value := range values {
-		dbIdx, tbIdx, err := vt.Shard(column, value)
+		shards, err := vt.ShardBy(map[string]interface{}{column: value})
 		if err != nil {
 			return nil, err
 		}
-		ret.Add(dbIdx, tbIdx)
+		// neither database nor table can be computed, eg: the column is a part of composite sharding key
+		if shards == nil {
+			return nil, nil
+		}
+		shards.Each(func(db, tb uint32) bool {
+			ret.Add(db, tb)
+			return true
+		})
 	}
 
 	return ret, nil
 }
+
+// rangeMetadata returns the metadata which is used to enumerate the value range, the table metadata is preferred.
+// Returns nil if both of them are composite.
+func rangeMetadata(dbMetadata, tbMetadata *rule.ShardMetadata) *rule.ShardMetadata {
+	if tbMetadata != nil && !tbMetadata.IsComposite() {
+		return tbMetadata
+	}
+	if dbMetadata != nil && !dbMetadata.IsComposite() {
+		return dbMetadata
+	}
+	return nil
+}
+
+// matchRange computes the shards of values in [lower,upper] directly by the RangeShardComputer, nil means unbounded.
+// The returned ok will be false if neither the database nor the table computer is a RangeShardComputer.
+func matchRange(vt *rule.VTable, column string, lower, upper interface{}) (shards *rule.Shards, ok bool, err error) {
+	dbMetadata, tbMetadata, _ := vt.GetShardMetadata(column)
+
+	var indexes [2]map[int]struct{} // [db indexes,table indexes], nil means any
+	for i, md := range [2]*rule.ShardMetadata{dbMetadata, tbMetadata} {
+		if md == nil || md.IsComposite() {
+			continue
+		}
+		rc, isRange := md.Computer.(RangeShardComputer)
+		if !isRange {
+			continue
+		}
+		computed, err := rc.ComputeRange(lower, upper)
+		if errors.Is(err, errNoRangePruning) {
+			continue
+		}
+		if err != nil {
+			return nil, false, errors.WithStack(err)
+		}
+		indexes[i] = make(map[int]struct{}, len(computed))
+		for _, idx := range computed {
+			indexes[i][idx] = struct{}{}
+		}
+		ok = true
+	}
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	contains := func(m map[int]struct{}, idx int) bool {
+		if m == nil {
+			return true
+		}
+		_, exist := m[idx]
+		return exist
+	}
+
+	shards = rule.NewShards()
+	vt.Topology().Each(func(dbIdx, tbIdx int) bool {
+		if contains(indexes[0], dbIdx) && contains(indexes[1], tbIdx) {
+			shards.Add(uint32(dbIdx), uint32(tbIdx))
+		}
+		return true
+	})
+	return shards, true, nil
+}
@@ -44,10 +44,15 @@ const (
 )
 
 var shardMap = map[ShardType]ShardComputerFunc{
-	ModShard:       NewModShard,
-	HashMd5Shard:   NewHashMd5Shard,
-	HashCrc32Shard: NewHashCrc32Shard,
-	HashBKDRShard:  NewHashBKDRShard,
+	ModShard:            NewModShard,
+	HashMd5Shard:        NewHashMd5Shard,
+	HashCrc32Shard:      NewHashCrc32Shard,
+	HashBKDRShard:       NewHashBKDRShard,
+	DayShard:            NewDayShard,
+	WeekShard:           NewWeekShard,
+	MonthShard:          NewMonthShard,
+	YearShard:           NewYearShard,
+	ConsistentHashShard: NewConsistentHashShard,
 }
 
 func ShardFactory(shardType ShardType, shardNum int) (shardStrategy rule.ShardComputer, err error) {
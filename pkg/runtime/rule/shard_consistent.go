@@ -0,0 +1,158 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rule
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto/rule"
+)
+
+const (
+	ConsistentHashShard ShardType = "consistentHashShard"
+
+	// DefaultVirtualNodes is the default amount of virtual nodes of each shard in the hash ring.
+	DefaultVirtualNodes = 160
+
+	// _maxEnumerateValues is the max amount of values to be enumerated when computing a range of hash shards.
+	_maxEnumerateValues = 128
+
+	// _maxSeedValues is the max amount of values to be enumerated when searching the seeds of hash shards.
+	_maxSeedValues = 1 << 20
+)
+
+var (
+	_ RangeShardComputer = (*consistentHashShard)(nil)
+	_ ShardSeeder        = (*consistentHashShard)(nil)
+	_ ShardIndexer       = (*consistentHashShard)(nil)
+)
+
+// consistentHashShard computes the shard by a consistent-hash ring with virtual nodes,
+// so that only a fraction of keys will be moved when a shard is added.
+type consistentHashShard struct {
+	shardNum int
+	ring     []uint32 // sorted hash values of virtual nodes
+	nodes    map[uint32]int
+}
+
+// NewConsistentHashShard creates a ShardComputer with a consistent-hash ring, each shard has DefaultVirtualNodes virtual nodes.
+func NewConsistentHashShard(shardNum int) rule.ShardComputer {
+	return NewConsistentHashShardWithVirtualNodes(shardNum, DefaultVirtualNodes)
+}
+
+// NewConsistentHashShardWithVirtualNodes creates a ShardComputer with a consistent-hash ring.
+func NewConsistentHashShardWithVirtualNodes(shardNum, virtualNodes int) rule.ShardComputer {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	ret := &consistentHashShard{
+		shardNum: shardNum,
+		ring:     make([]uint32, 0, shardNum*virtualNodes),
+		nodes:    make(map[uint32]int, shardNum*virtualNodes),
+	}
+
+	for i := 0; i < shardNum; i++ {
+		for j := 0; j < virtualNodes; j++ {
+			h := crc32.ChecksumIEEE([]byte("SHARD-" + strconv.Itoa(i) + "-VN-" + strconv.Itoa(j)))
+			// skip the collided virtual node, the former one wins
+			if _, ok := ret.nodes[h]; ok {
+				continue
+			}
+			ret.nodes[h] = i
+			ret.ring = append(ret.ring, h)
+		}
+	}
+
+	sort.Slice(ret.ring, func(i, j int) bool {
+		return ret.ring[i] < ret.ring[j]
+	})
+
+	return ret
+}
+
+func (c *consistentHashShard) Compute(value interface{}) (int, error) {
+	h := crc32.ChecksumIEEE([]byte(fmt.Sprintf("%v", value)))
+	i := sort.Search(len(c.ring), func(i int) bool {
+		return c.ring[i] >= h
+	})
+	// wrap around the ring
+	if i == len(c.ring) {
+		i = 0
+	}
+	return c.nodes[c.ring[i]], nil
+}
+
+func (c *consistentHashShard) ComputeRange(lower, upper interface{}) ([]int, error) {
+	begin, ok1 := toInt64(lower)
+	end, ok2 := toInt64(upper)
+
+	// too many values or not enumerable, use all shards
+	if !ok1 || !ok2 || end < begin || end-begin >= _maxEnumerateValues {
+		return c.Indexes(), nil
+	}
+
+	var (
+		ret     []int
+		visited = make(map[int]struct{})
+	)
+	for i := begin; i <= end; i++ {
+		idx, err := c.Compute(i)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := visited[idx]; ok {
+			continue
+		}
+		visited[idx] = struct{}{}
+		ret = append(ret, idx)
+	}
+	sort.Ints(ret)
+	return ret, nil
+}
+
+// Indexes returns the shard indexes of ring, a shard without any virtual node is included too.
+func (c *consistentHashShard) Indexes() []int {
+	ret := make([]int, 0, c.shardNum)
+	for i := 0; i < c.shardNum; i++ {
+		ret = append(ret, i)
+	}
+	return ret
+}
+
+// Seeds returns the first integer which is hashed into each shard.
+func (c *consistentHashShard) Seeds() []interface{} {
+	var (
+		ret     []interface{}
+		visited = make(map[int]struct{}, c.shardNum)
+	)
+	for i := int64(0); i < _maxSeedValues && len(visited) < c.shardNum; i++ {
+		idx, _ := c.Compute(i)
+		if _, ok := visited[idx]; ok {
+			continue
+		}
+		visited[idx] = struct{}{}
+		ret = append(ret, i)
+	}
+	return ret
+}
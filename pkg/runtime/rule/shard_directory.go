@@ -0,0 +1,143 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rule
+
+import (
+	"fmt"
+	"sort"
+)
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto/rule"
+)
+
+const (
+	DirectoryShard ShardType = "directoryShard"
+
+	// DefaultDirectoryCacheSize is the default size of LRU cache of directory sharding.
+	DefaultDirectoryCacheSize = 4096
+)
+
+var (
+	_ rule.ShardComputer  = (*directoryShard)(nil)
+	_ PartialShardIndexer = (*directoryShard)(nil)
+)
+
+// DirectoryLookup looks up the shard index of key from the directory, eg: a mapping table.
+// The returned ok will be false if the key is not mapped.
+type DirectoryLookup func(key string) (idx int, ok bool, err error)
+
+// MapDirectoryLookup returns a DirectoryLookup from a fixed mapping.
+func MapDirectoryLookup(mapping map[string]int) DirectoryLookup {
+	return func(key string) (int, bool, error) {
+		idx, ok := mapping[key]
+		return idx, ok, nil
+	}
+}
+
+// directoryShard resolves the shard by looking the key up in a directory, the resolved shards are cached with LRU.
+type directoryShard struct {
+	lookup   DirectoryLookup
+	fallback rule.ShardComputer
+	cache    *lru.Cache
+	indexes  []int
+	partial  bool
+}
+
+// NewDirectoryShard creates a ShardComputer which resolves the shard from the directory, the unmapped keys
+// will be computed by the fallback computer. The fallback can be nil, then the unmapped keys will be rejected.
+// The indexes are the shards which can be resolved, eg: the range of fallback and the mapped shards, they are
+// used to build the topology, so that the directory won't be looked up before the runtime is ready.
+// The partial should be true if the directory may resolve the shards beyond the indexes, eg: a mapping table.
+func NewDirectoryShard(lookup DirectoryLookup, fallback rule.ShardComputer, cacheSize int, partial bool, indexes ...int) (rule.ShardComputer, error) {
+	if lookup == nil {
+		return nil, errors.New("directory lookup is required")
+	}
+	if cacheSize <= 0 {
+		cacheSize = DefaultDirectoryCacheSize
+	}
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &directoryShard{
+		lookup:   lookup,
+		fallback: fallback,
+		cache:    cache,
+		indexes:  distinctIndexes(indexes),
+		partial:  partial,
+	}, nil
+}
+
+func (d *directoryShard) Compute(value interface{}) (int, error) {
+	key := fmt.Sprintf("%v", value)
+	if idx, ok := d.cache.Get(key); ok {
+		return idx.(int), nil
+	}
+
+	idx, ok, err := d.lookup(key)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to lookup directory of key '%s'", key)
+	}
+
+	if !ok {
+		if d.fallback == nil {
+			return 0, errors.Errorf("no directory found for key '%s'", key)
+		}
+		if idx, err = d.fallback.Compute(value); err != nil {
+			return 0, err
+		}
+	}
+
+	// NOTICE: the unmapped keys are cached too, the cache will be dropped when the rule is refreshed.
+	d.cache.Add(key, idx)
+	return idx, nil
+}
+
+// Indexes returns the shards which can be resolved, it is empty if they are unknown.
+func (d *directoryShard) Indexes() []int {
+	return d.indexes
+}
+
+// Partial returns true if the directory may resolve the shards beyond the indexes.
+func (d *directoryShard) Partial() bool {
+	return d.partial || len(d.indexes) < 1
+}
+
+// distinctIndexes returns the sorted indexes without duplicates.
+func distinctIndexes(indexes []int) []int {
+	if len(indexes) < 1 {
+		return nil
+	}
+	ret := append([]int(nil), indexes...)
+	sort.Ints(ret)
+	n := 1
+	for i := 1; i < len(ret); i++ {
+		if ret[i] != ret[n-1] {
+			ret[n] = ret[i]
+			n++
+		}
+	}
+	return ret[:n]
+}
@@ -0,0 +1,240 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rule
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto/rule"
+)
+
+const RangeShard ShardType = "rangeShard"
+
+var (
+	_ RangeShardComputer = (*rangeShard)(nil)
+	_ ShardSeeder        = (*rangeShard)(nil)
+
+	_rangeSegmentRegexp     *regexp.Regexp
+	_rangeSegmentRegexpOnce sync.Once
+)
+
+// RangeShardComputer represents a ShardComputer which can compute the shards of a value range directly,
+// instead of enumerating the values in the range.
+type RangeShardComputer interface {
+	rule.ShardComputer
+	// ComputeRange computes the shard indexes of values in [lower,upper], nil means unbounded.
+	// The errNoRangePruning will be returned if the range cannot be pruned, all shards will be used.
+	ComputeRange(lower, upper interface{}) ([]int, error)
+}
+
+// ShardSeeder provides the values which cover all the shards, they can be used to enumerate the topology.
+type ShardSeeder interface {
+	// Seeds returns the values which cover all the shards.
+	Seeds() []interface{}
+}
+
+// ShardIndexer provides all the shard indexes, it is used by the computers whose values of each shard cannot be
+// enumerated, eg: consistent-hash. The topology will be the cross product of the indexes of database and table.
+type ShardIndexer interface {
+	// Indexes returns all the shard indexes in ascending order.
+	Indexes() []int
+}
+
+// PartialShardIndexer is a ShardIndexer which may resolve the shards beyond its indexes, eg: the directory sharding
+// backed by a mapping table, then all the shards of topology pattern should be included.
+type PartialShardIndexer interface {
+	ShardIndexer
+	// Partial returns true if the indexes are incomplete.
+	Partial() bool
+}
+
+type rangeSegment struct {
+	begin, end int64 // [begin,end)
+	shard      int
+}
+
+// rangeShard computes the shard by an explicit range-map, eg: [0,1000000)->0,[1000000,5000000)->1
+type rangeShard struct {
+	segments []rangeSegment // sorted by begin
+}
+
+// NewRangeShard creates a ShardComputer from a range-map expression, which consists of segments separated by comma.
+// Each segment is formatted as '[begin,end)->shard', the begin or end can be omitted as unbounded, eg:
+//
+//	[,1000000)->0,[1000000,5000000)->1,[5000000,)->2
+func NewRangeShard(expr string) (rule.ShardComputer, error) {
+	_rangeSegmentRegexpOnce.Do(func() {
+		_rangeSegmentRegexp = regexp.MustCompile(`\[\s*([^,\[\]()]*?)\s*,\s*([^,\[\]()]*?)\s*\)\s*->\s*(\d+)`)
+	})
+
+	var (
+		ret    rangeShard
+		offset int
+	)
+	for _, loc := range _rangeSegmentRegexp.FindAllStringSubmatchIndex(expr, -1) {
+		if gap := strings.Trim(expr[offset:loc[0]], " \t\r\n,"); len(gap) > 0 {
+			return nil, errors.Errorf("invalid range shard expression '%s': unexpected '%s'", expr, gap)
+		}
+		offset = loc[1]
+
+		var (
+			seg rangeSegment
+			err error
+		)
+		if seg.begin, err = parseRangeBound(expr[loc[2]:loc[3]], math.MinInt64); err != nil {
+			return nil, errors.Wrapf(err, "invalid range shard expression '%s'", expr)
+		}
+		if seg.end, err = parseRangeBound(expr[loc[4]:loc[5]], math.MaxInt64); err != nil {
+			return nil, errors.Wrapf(err, "invalid range shard expression '%s'", expr)
+		}
+		if seg.begin >= seg.end {
+			return nil, errors.Errorf("invalid range shard expression '%s': empty range [%d,%d)", expr, seg.begin, seg.end)
+		}
+		seg.shard, _ = strconv.Atoi(expr[loc[6]:loc[7]])
+		ret.segments = append(ret.segments, seg)
+	}
+
+	if gap := strings.Trim(expr[offset:], " \t\r\n,"); len(gap) > 0 {
+		return nil, errors.Errorf("invalid range shard expression '%s': unexpected '%s'", expr, gap)
+	}
+	if len(ret.segments) < 1 {
+		return nil, errors.Errorf("invalid range shard expression '%s': no range found", expr)
+	}
+
+	sort.Slice(ret.segments, func(i, j int) bool {
+		return ret.segments[i].begin < ret.segments[j].begin
+	})
+	for i := 1; i < len(ret.segments); i++ {
+		if prev, next := ret.segments[i-1], ret.segments[i]; prev.end > next.begin {
+			return nil, errors.Errorf("invalid range shard expression '%s': overlapped ranges", expr)
+		}
+	}
+
+	return &ret, nil
+}
+
+func (r *rangeShard) Compute(value interface{}) (int, error) {
+	n, ok := toInt64(value)
+	if !ok {
+		return 0, errors.Errorf("invalid range shard value '%v'", value)
+	}
+	i := sort.Search(len(r.segments), func(i int) bool {
+		return r.segments[i].end > n
+	})
+	if i == len(r.segments) || r.segments[i].begin > n {
+		return 0, errors.Errorf("no range shard matched value '%v'", value)
+	}
+	return r.segments[i].shard, nil
+}
+
+func (r *rangeShard) ComputeRange(lower, upper interface{}) ([]int, error) {
+	var (
+		begin int64 = math.MinInt64
+		end   int64 = math.MaxInt64
+		ok    bool
+	)
+	if lower != nil {
+		if begin, ok = toInt64(lower); !ok {
+			return nil, errors.Errorf("invalid range shard value '%v'", lower)
+		}
+	}
+	if upper != nil {
+		if end, ok = toInt64(upper); !ok {
+			return nil, errors.Errorf("invalid range shard value '%v'", upper)
+		}
+	}
+
+	var (
+		ret     []int
+		visited = make(map[int]struct{})
+	)
+	for _, seg := range r.segments {
+		// no intersection between [begin,end] and [seg.begin,seg.end)
+		if seg.begin > end || seg.end <= begin {
+			continue
+		}
+		if _, exist := visited[seg.shard]; exist {
+			continue
+		}
+		visited[seg.shard] = struct{}{}
+		ret = append(ret, seg.shard)
+	}
+	sort.Ints(ret)
+	return ret, nil
+}
+
+func (r *rangeShard) Seeds() []interface{} {
+	ret := make([]interface{}, 0, len(r.segments))
+	for _, seg := range r.segments {
+		ret = append(ret, seg.begin)
+	}
+	return ret
+}
+
+func parseRangeBound(s string, unbounded int64) (int64, error) {
+	if len(s) == 0 {
+		return unbounded, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	// scientific notation, eg: 1e6
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f != math.Trunc(f) {
+		return 0, errors.Errorf("invalid range bound '%s'", s)
+	}
+	return int64(f), nil
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	n, err := strconv.ParseInt(fmt.Sprintf("%v", value), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
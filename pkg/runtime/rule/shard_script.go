@@ -14,12 +14,14 @@
  * See the License for the specific language governing permissions and
  * limitations under the License.
  */
-
 package rule
 
 import (
+	"fmt"
+	"regexp"
 	"runtime"
 	"strings"
+	"time"
 )
 
 import (
@@ -32,55 +34,189 @@ import (
 	"github.com/arana-db/arana/pkg/proto/rule"
 )
 
-var _ rule.ShardComputer = (*jsShardComputer)(nil)
+var (
+	_ rule.ShardComputer = (*jsShardComputer)(nil)
+	_ RangeShardComputer = (*jsRangeShardComputer)(nil)
+)
 
 const (
-	_jsEntrypoint = "__compute__" // shard method name
-	_jsValueName  = "$value"      // variable name of column in sharding script
+	_jsEntrypoint   = "__compute__" // shard method name
+	_jsValueName    = "$value"      // variable name of column in sharding script
+	_jsRangeName    = "$range"      // variable name of range in sharding script, eg: {lower: 1, upper: 100}
+	_jsColumnsName  = "$columns"    // variable name of named columns in sharding script, eg: {uid: 1, tenant: 'foo'}
+	_jsColumnPrefix = "$"           // prefix of named column variable, eg: $uid
+
+	// DefaultScriptTimeout is the default execution time limit of sharding script.
+	DefaultScriptTimeout = 100 * time.Millisecond
+)
+
+var (
+	errScriptTimeout   = errors.New("execution of sharding script timeout")
+	errNoRangePruning  = errors.New("the range cannot be pruned by sharding script")
+	_jsIdentifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
 )
 
+// JavascriptOption represents the option of javascript shard computer.
+type JavascriptOption func(*jsShardComputer)
+
+// WithScriptColumns binds the columns of a sharding key, each column can be accessed as a variable with '$' prefix.
+// For a composite sharding key, the value to be computed should be a []interface{} in the same order of columns.
+func WithScriptColumns(columns ...string) JavascriptOption {
+	return func(j *jsShardComputer) {
+		j.columns = columns
+	}
+}
+
+// WithScriptTimeout sets the execution time limit of sharding script, zero means no limit.
+func WithScriptTimeout(timeout time.Duration) JavascriptOption {
+	return func(j *jsShardComputer) {
+		j.timeout = timeout
+	}
+}
+
 type jsShardComputer struct {
 	// runtime is not thread-safe, wrap as leaky buffer.
 	// please see https://go.dev/doc/effective_go#leaky_buffer
 	freelist chan *goja.Runtime
 	script   string
+	columns  []string
+	timeout  time.Duration
+}
+
+// jsRangeShardComputer is the javascript shard computer which can prune the range of values,
+// the script will be computed with the variable '$range' and should return a set of indexes.
+type jsRangeShardComputer struct {
+	*jsShardComputer
 }
 
 // NewJavascriptShardComputer returns a shard computer which is based on Javascript.
-func NewJavascriptShardComputer(script string) (rule.ShardComputer, error) {
-	script = wrapScript(script)
+//
+// The script can access the following variables:
+//   - $value: the value to be computed, it is an array for a composite sharding key.
+//   - $columns: the named values of sharding columns, eg: $columns.uid.
+//   - $<column>: the value of named sharding column, eg: $uid.
+//   - $range: the range of values with 'lower' and 'upper', null means unbounded. It is undefined unless the
+//     range is computed, and only the script which references it will be used to prune the range.
+//
+// The script can return either an index or an array of indexes, also the prelude functions such as crc32,
+// murmur3, parseDate and formatDate can be used in script.
+func NewJavascriptShardComputer(script string, options ...JavascriptOption) (rule.ShardComputer, error) {
+	ret := &jsShardComputer{
+		freelist: make(chan *goja.Runtime, runtime.NumCPU()*2),
+		timeout:  DefaultScriptTimeout,
+	}
+	for _, it := range options {
+		it(ret)
+	}
 
-	vm, err := createVM(script)
+	ret.script = wrapScript(script, ret.columns)
+
+	vm, err := createVM(ret.script)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create javascript shard computer")
 	}
-
-	ret := &jsShardComputer{
-		freelist: make(chan *goja.Runtime, runtime.NumCPU()*2),
-		script:   script,
-	}
 	ret.freelist <- vm
 
+	if strings.Contains(script, _jsRangeName) {
+		return &jsRangeShardComputer{ret}, nil
+	}
 	return ret, nil
 }
 
 func (j *jsShardComputer) Compute(value interface{}) (int, error) {
-	vm, err := j.getVM()
+	res, err := j.call(value, nil)
 	if err != nil {
 		return 0, err
 	}
 
-	defer func() {
-		j.putVM(vm)
-	}()
+	switch v := res.(type) {
+	case int64:
+		return int(v), nil
+	case []int:
+		if len(v) == 1 {
+			return v[0], nil
+		}
+		return 0, errors.Errorf("sharding script returns %d indexes for value '%v'", len(v), value)
+	default:
+		return 0, errors.Errorf("sharding script returns no index for value '%v'", value)
+	}
+}
+
+func (j *jsRangeShardComputer) ComputeRange(lower, upper interface{}) ([]int, error) {
+	res, err := j.call(nil, map[string]interface{}{
+		"lower": lower,
+		"upper": upper,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := res.(type) {
+	case int64:
+		return []int{int(v)}, nil
+	case []int:
+		return v, nil
+	default:
+		return nil, errNoRangePruning
+	}
+}
+
+// call calls the script, the result will be an int64, a []int or nil.
+func (j *jsShardComputer) call(value interface{}, rng map[string]interface{}) (interface{}, error) {
+	vm, err := j.getVM()
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]goja.Value, 0, 3+len(j.columns))
+	if rng != nil {
+		args = append(args, goja.Undefined(), vm.NewObject(), toJSValue(vm, rng))
+		for range j.columns {
+			args = append(args, goja.Undefined())
+		}
+	} else {
+		values := make([]interface{}, len(j.columns))
+		if len(j.columns) > 1 {
+			if composite, ok := value.([]interface{}); ok {
+				copy(values, composite)
+			}
+		} else if len(j.columns) == 1 {
+			values[0] = value
+		}
+		columns := make(map[string]interface{}, len(j.columns))
+		for i, column := range j.columns {
+			columns[column] = values[i]
+		}
+		args = append(args, toJSValue(vm, value), toJSValue(vm, columns), goja.Undefined())
+		for i := range values {
+			args = append(args, toJSValue(vm, values[i]))
+		}
+	}
+
+	var timer *time.Timer
+	if j.timeout > 0 {
+		timer = time.AfterFunc(j.timeout, func() {
+			vm.Interrupt(errScriptTimeout)
+		})
+	}
 
 	fn, _ := goja.AssertFunction(vm.Get(_jsEntrypoint))
-	res, err := fn(goja.Undefined(), vm.ToValue(value))
+	res, err := fn(goja.Undefined(), args...)
+
+	// the interrupt may happen after the script returns, discard the runtime
+	if timer == nil || timer.Stop() {
+		j.putVM(vm)
+	}
+
 	if err != nil {
-		return 0, errors.WithStack(err)
+		var interrupted *goja.InterruptedError
+		if errors.As(err, &interrupted) {
+			return nil, errors.Wrapf(errScriptTimeout, "exceed %s", j.timeout)
+		}
+		return nil, errors.WithStack(err)
 	}
 
-	return int(res.ToInteger()), nil
+	return fromJSValue(vm, res)
 }
 
 func (j *jsShardComputer) getVM() (*goja.Runtime, error) {
@@ -99,15 +235,29 @@ func (j *jsShardComputer) putVM(vm *goja.Runtime) {
 	}
 }
 
-func wrapScript(script string) string {
+func wrapScript(script string, columns []string) string {
 	var sb strings.Builder
 
-	sb.Grow(32 + len(_jsEntrypoint) + len(_jsValueName) + len(script))
+	sb.Grow(64 + len(_jsEntrypoint) + len(_jsValueName) + len(script))
 
 	sb.WriteString("function ")
 	sb.WriteString(_jsEntrypoint)
 	sb.WriteString("(")
 	sb.WriteString(_jsValueName)
+	sb.WriteString(", ")
+	sb.WriteString(_jsColumnsName)
+	sb.WriteString(", ")
+	sb.WriteString(_jsRangeName)
+	for i, column := range columns {
+		sb.WriteString(", ")
+		// the column cannot be used as an identifier, access it with $columns instead
+		if !_jsIdentifierRegex.MatchString(column) {
+			_, _ = fmt.Fprintf(&sb, "$__unnamed_%d", i)
+			continue
+		}
+		sb.WriteString(_jsColumnPrefix)
+		sb.WriteString(column)
+	}
 	sb.WriteString(") {\n")
 
 	if strings.Contains(script, "return ") {
@@ -124,11 +274,53 @@ func wrapScript(script string) string {
 
 func createVM(script string) (*goja.Runtime, error) {
 	vm := goja.New()
+	if err := installPrelude(vm); err != nil {
+		return nil, errors.WithStack(err)
+	}
 	if _, err := vm.RunString(script); err != nil {
 		return nil, errors.WithStack(err)
 	}
+	return vm, nil
+}
 
-	// TODO: add prelude functions, includes some hash/utils
+// toJSValue converts the value to javascript value, the time will be converted to Date.
+func toJSValue(vm *goja.Runtime, value interface{}) goja.Value {
+	switch v := value.(type) {
+	case nil:
+		return goja.Null()
+	case []byte:
+		return vm.ToValue(string(v))
+	case time.Time:
+		d, _ := vm.New(vm.Get("Date"), vm.ToValue(v.UnixMilli()))
+		return d
+	case []interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, it := range v {
+			values = append(values, toJSValue(vm, it))
+		}
+		return vm.NewArray(values...)
+	case map[string]interface{}:
+		obj := vm.NewObject()
+		for k, it := range v {
+			_ = obj.Set(k, toJSValue(vm, it))
+		}
+		return obj
+	default:
+		return vm.ToValue(value)
+	}
+}
 
-	return vm, nil
+// fromJSValue converts the result of script, returns nil if no index returned.
+func fromJSValue(vm *goja.Runtime, value goja.Value) (interface{}, error) {
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return nil, nil
+	}
+	if obj, ok := value.(*goja.Object); ok && obj.ClassName() == "Array" {
+		var indexes []int
+		if err := vm.ExportTo(value, &indexes); err != nil {
+			return nil, errors.Wrap(err, "invalid indexes returned by sharding script")
+		}
+		return indexes, nil
+	}
+	return value.ToInteger(), nil
 }
@@ -0,0 +1,172 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package rule
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"hash/crc32"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/dop251/goja"
+
+	"github.com/pkg/errors"
+)
+
+// _jsPrelude wraps the native helpers, the time will be exchanged as milliseconds since epoch.
+const _jsPrelude = `
+function parseDate(s) {
+  return new Date(__parseDate(s));
+}
+
+function formatDate(d, layout) {
+  return __formatDate(d instanceof Date ? d.getTime() : parseDate(d).getTime(), layout || 'yyyy-MM-dd');
+}
+`
+
+var _javaLayoutReplacer = strings.NewReplacer(
+	"yyyy", "2006",
+	"yy", "06",
+	"MM", "01",
+	"dd", "02",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+	"SSS", "000",
+)
+
+// installPrelude installs the helper functions of sharding script:
+//   - crc32(s): the IEEE crc32 checksum of string.
+//   - murmur3(s[, seed]): the 32-bit murmur3 hash of string.
+//   - md5(s): the hex md5 digest of string.
+//   - hashCode(s): the java-style hash code of string.
+//   - lpad(s, n, pad)/rpad(s, n, pad): pad the string to the length.
+//   - parseDate(s): parse the date string, eg: '2022-01-02 15:04:05'.
+//   - formatDate(d[, layout]): format the date with java-style layout, eg: 'yyyyMMdd'.
+func installPrelude(vm *goja.Runtime) error {
+	natives := map[string]interface{}{
+		"crc32": func(s string) uint32 {
+			return crc32.ChecksumIEEE([]byte(s))
+		},
+		"murmur3": func(s string, seed uint32) uint32 {
+			return murmur3Sum32([]byte(s), seed)
+		},
+		"md5": func(s string) string {
+			sum := md5.Sum([]byte(s))
+			return hex.EncodeToString(sum[:])
+		},
+		"hashCode": func(s string) int32 {
+			var h int32
+			for _, c := range s {
+				h = 31*h + c
+			}
+			return h
+		},
+		"lpad": func(s string, n int, pad string) string {
+			return padString(s, n, pad, true)
+		},
+		"rpad": func(s string, n int, pad string) string {
+			return padString(s, n, pad, false)
+		},
+		"__parseDate": func(s string) (int64, error) {
+			t, err := parseShardTime(s)
+			if err != nil {
+				return 0, err
+			}
+			return t.UnixMilli(), nil
+		},
+		"__formatDate": func(millis int64, layout string) string {
+			return time.UnixMilli(millis).In(time.Local).Format(_javaLayoutReplacer.Replace(layout))
+		},
+	}
+	for k, v := range natives {
+		if err := vm.Set(k, v); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if _, err := vm.RunString(_jsPrelude); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func padString(s string, n int, pad string, left bool) string {
+	if len(pad) < 1 {
+		pad = " "
+	}
+	var sb strings.Builder
+	for sb.Len()+len(s) < n {
+		sb.WriteString(pad)
+	}
+	padding := sb.String()
+	if len(padding)+len(s) > n {
+		padding = padding[:n-len(s)]
+	}
+	if left {
+		return padding + s
+	}
+	return s + padding
+}
+
+// murmur3Sum32 returns the 32-bit murmur3 hash of data.
+func murmur3Sum32(data []byte, seed uint32) uint32 {
+	const (
+		c1 uint32 = 0xcc9e2d51
+		c2 uint32 = 0x1b873593
+	)
+
+	h := seed
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := uint32(data[i*4]) | uint32(data[i*4+1])<<8 | uint32(data[i*4+2])<<16 | uint32(data[i*4+3])<<24
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	tail := data[n*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 )
 
 import (
@@ -120,6 +121,99 @@ return n%32;
 	}
 }
 
+func TestScriptNamedColumns(t *testing.T) {
+	c, err := NewJavascriptShardComputer("($tenant_id * 8 + $uid) % 16", WithScriptColumns("tenant_id", "uid"))
+	assert.NoError(t, err)
+
+	actual, err := c.Compute([]interface{}{1, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 11, actual)
+
+	c, err = NewJavascriptShardComputer("$columns['user-id'] % 4", WithScriptColumns("user-id"))
+	assert.NoError(t, err)
+	actual, err = c.Compute(7)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, actual)
+}
+
+func TestScriptPrelude(t *testing.T) {
+	type tt struct {
+		script string
+		input  interface{}
+		output int
+	}
+
+	for _, it := range []tt{
+		{"crc32($value) % 32", "foobar", int(2666930069 % 32)},
+		{"murmur3($value) % 32", "foobar", int(murmur3Sum32([]byte("foobar"), 0) % 32)},
+		{"Math.abs(hashCode($value)) % 32", "foobar", 19}, // "foobar".hashCode() in java: -1268878963,
+		{"parseInt(md5($value).substring(0, 2), 16)", "foobar", 0x38},
+		{"parseDate($value).getMonth()", "2022-03-04", 2},
+		{"parseInt(formatDate($value, 'yyyyMM'))", time.Date(2022, 3, 4, 0, 0, 0, 0, time.Local), 202203},
+		{"parseInt(lpad(String($value), 4, '9'))", 1, 9991},
+	} {
+		t.Run(it.script, func(t *testing.T) {
+			c, err := NewJavascriptShardComputer(it.script)
+			assert.NoError(t, err)
+			actual, err := c.Compute(it.input)
+			assert.NoError(t, err)
+			assert.Equal(t, it.output, actual)
+		})
+	}
+}
+
+func TestScriptRange(t *testing.T) {
+	c, err := NewJavascriptShardComputer(`
+if ($range === undefined) {
+  return Math.floor($value / 100);
+}
+if ($range.lower === null || $range.upper === null) {
+  return null;
+}
+let ret = [];
+for (let i = Math.floor($range.lower / 100); i <= Math.floor($range.upper / 100); i++) {
+  ret.push(i);
+}
+return ret;
+`)
+	assert.NoError(t, err)
+
+	actual, err := c.Compute(250)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, actual)
+
+	rc, ok := c.(RangeShardComputer)
+	assert.True(t, ok)
+
+	indexes, err := rc.ComputeRange(150, 320)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, indexes)
+
+	_, err = rc.ComputeRange(nil, 320)
+	assert.ErrorIs(t, err, errNoRangePruning)
+
+	// range is not referenced by script
+	c, _ = NewJavascriptShardComputer("[$value % 4]")
+	_, ok = c.(RangeShardComputer)
+	assert.False(t, ok)
+	actual, err = c.Compute(6)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, actual)
+
+	// multiple indexes for a single value
+	c, _ = NewJavascriptShardComputer("[0, 1]")
+	_, err = c.Compute(6)
+	assert.Error(t, err)
+}
+
+func TestScriptTimeout(t *testing.T) {
+	c, err := NewJavascriptShardComputer("while (true) {}; return 0", WithScriptTimeout(50*time.Millisecond))
+	assert.NoError(t, err)
+
+	_, err = c.Compute(1)
+	assert.ErrorIs(t, err, errScriptTimeout)
+}
+
 func BenchmarkJavascriptShardComputer(b *testing.B) {
 	computer, _ := NewJavascriptShardComputer("$value % 32")
 	_, _ = computer.Compute(42)
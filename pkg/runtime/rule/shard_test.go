@@ -20,12 +20,19 @@ package rule
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 import (
+	"github.com/pkg/errors"
+
 	"github.com/stretchr/testify/assert"
 )
 
+import (
+	"github.com/arana-db/arana/pkg/proto/rule"
+)
+
 func TestShardFactory(t *testing.T) {
 	shardTable := []struct {
 		in       ShardType
@@ -144,3 +151,181 @@ func TestBKDRShard(t *testing.T) {
 		assert.Equal(t, shardTable[i].want, out)
 	}
 }
+
+func TestTimeShard(t *testing.T) {
+	date := time.Date(2022, time.March, 15, 10, 30, 0, 0, time.Local)
+	type tt struct {
+		typ  ShardType
+		mod  int
+		in   interface{}
+		want int
+	}
+	for _, it := range []tt{
+		{DayShard, 0, date, 73},
+		{DayShard, 7, "1970-01-08", 0},
+		{DayShard, 7, "1969-12-31", 6},
+		{WeekShard, 0, date, 10},
+		{WeekShard, 4, "1970-01-05", 1},
+		{MonthShard, 0, "2022-03-31 23:59:59", 2},
+		{MonthShard, 12, date, 2},
+		{MonthShard, 5, "2022-01-01", (2022*12 + 0) % 5},
+		{YearShard, 0, date, 2022},
+		{YearShard, 4, []byte("2022-01-01"), 2},
+	} {
+		t.Run(string(it.typ), func(t *testing.T) {
+			f := shardMap[it.typ]
+			out, err := f(it.mod).Compute(it.in)
+			assert.NoError(t, err)
+			assert.Equal(t, it.want, out)
+		})
+	}
+
+	_, err := NewMonthShard(12).Compute("not a date")
+	assert.Error(t, err)
+	assert.Equal(t, rule.Umonth, NewMonthShard(12).(TimeShardComputer).Unit())
+}
+
+func TestConsistentHashShard(t *testing.T) {
+	shard, err := ShardFactory(ConsistentHashShard, 4)
+	assert.NoError(t, err)
+
+	const total = 10000
+
+	var (
+		counts [4]int
+		before = make([]int, total)
+	)
+	for i := 0; i < total; i++ {
+		idx, err := shard.Compute(i)
+		assert.NoError(t, err)
+		counts[idx]++
+		before[i] = idx
+	}
+	for _, cnt := range counts {
+		assert.Greater(t, cnt, total/8, "keys should be distributed evenly")
+	}
+
+	// add a shard, only the keys moved into the new shard will be changed
+	var moved int
+	next := NewConsistentHashShard(5)
+	for i := 0; i < total; i++ {
+		idx, _ := next.Compute(i)
+		if idx != before[i] {
+			assert.Equal(t, 4, idx)
+			moved++
+		}
+	}
+	assert.Less(t, moved, total/3)
+
+	rng, err := shard.(RangeShardComputer).ComputeRange(nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, rng)
+
+	rng, err = shard.(RangeShardComputer).ComputeRange(int64(1), int64(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{before[1]}, rng)
+
+	assert.Equal(t, []int{0, 1, 2, 3}, shard.(ShardIndexer).Indexes())
+
+	// the seeds cover every shard of ring
+	covered := make(map[int]struct{})
+	for _, seed := range shard.(ShardSeeder).Seeds() {
+		idx, err := shard.Compute(seed)
+		assert.NoError(t, err)
+		covered[idx] = struct{}{}
+	}
+	assert.Len(t, covered, 4)
+}
+
+func TestRangeShard(t *testing.T) {
+	shard, err := NewRangeShard("[0,1e6)->0, [1000000,5000000)->1, [5000000,)->2")
+	assert.NoError(t, err)
+
+	for _, it := range []struct {
+		in   interface{}
+		want int
+	}{
+		{0, 0},
+		{int64(999999), 0},
+		{"1000000", 1},
+		{4999999, 1},
+		{int64(5000000), 2},
+		{int64(1) << 40, 2},
+	} {
+		out, err := shard.Compute(it.in)
+		assert.NoError(t, err)
+		assert.Equal(t, it.want, out)
+	}
+
+	_, err = shard.Compute(-1)
+	assert.Error(t, err)
+
+	rc := shard.(RangeShardComputer)
+	for _, it := range []struct {
+		lower, upper interface{}
+		want         []int
+	}{
+		{int64(500000), int64(2000000), []int{0, 1}},
+		{int64(1000000), nil, []int{1, 2}},
+		{nil, int64(999999), []int{0}},
+		{nil, int64(-1), nil},
+	} {
+		out, err := rc.ComputeRange(it.lower, it.upper)
+		assert.NoError(t, err)
+		assert.Equal(t, it.want, out)
+	}
+
+	for _, expr := range []string{
+		"",
+		"[0,1000)->0,foobar",
+		"[0,1000)->0,[500,2000)->1",
+		"[1000,0)->0",
+		"[a,1000)->0",
+	} {
+		_, err := NewRangeShard(expr)
+		assert.Error(t, err, "expression '%s' should be invalid", expr)
+	}
+}
+
+func TestDirectoryShard(t *testing.T) {
+	var lookups int
+	lookup := func(key string) (int, bool, error) {
+		lookups++
+		switch key {
+		case "tenant_a":
+			return 8, true, nil
+		case "broken":
+			return 0, false, errors.New("lookup failed")
+		}
+		return 0, false, nil
+	}
+
+	shard, err := NewDirectoryShard(lookup, NewModShard(4), 16, false, 0, 1, 2, 3, 8, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 8}, shard.(ShardIndexer).Indexes())
+	assert.False(t, shard.(PartialShardIndexer).Partial())
+	assert.Equal(t, 0, lookups, "the directory should be looked up lazily")
+
+	for i := 0; i < 3; i++ {
+		idx, err := shard.Compute("tenant_a")
+		assert.NoError(t, err)
+		assert.Equal(t, 8, idx)
+	}
+	assert.Equal(t, 1, lookups, "the mapped key should be cached")
+
+	idx, err := shard.Compute(7)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, idx, "the unmapped key should fall back")
+
+	_, err = shard.Compute("broken")
+	assert.Error(t, err)
+
+	shard, err = NewDirectoryShard(MapDirectoryLookup(map[string]int{"1": 2}), nil, 0, true)
+	assert.NoError(t, err)
+	assert.True(t, shard.(PartialShardIndexer).Partial())
+	idx, err = shard.Compute(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, idx)
+	_, err = shard.Compute(2)
+	assert.Error(t, err, "the unmapped key should be rejected without fallback")
+}
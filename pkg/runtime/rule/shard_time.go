@@ -0,0 +1,162 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rule
+
+import (
+	"fmt"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto/rule"
+)
+
+const (
+	DayShard   ShardType = "dayShard"
+	WeekShard  ShardType = "weekShard"
+	MonthShard ShardType = "monthShard"
+	YearShard  ShardType = "yearShard"
+)
+
+var _timeShardLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.000",
+	time.RFC3339,
+}
+
+var _ TimeShardComputer = (*timeShard)(nil)
+
+// TimeShardComputer represents a ShardComputer which shards the values by time.
+type TimeShardComputer interface {
+	rule.ShardComputer
+	// Unit returns the time unit of sharding.
+	Unit() rule.StepUnit
+	// Modulo returns the modulo of sharding, zero means no modulo.
+	Modulo() int
+}
+
+// timeShard computes the shard index of a time value.
+// Without modulo, the index is the position inside the natural period:
+//   - day: the day of year, [0,365]
+//   - week: the ISO week of year, [0,52]
+//   - month: the month of year, [0,11]
+//   - year: the year, eg: 2022
+//
+// With modulo, the index is the amount of units since 1970-01-01 mod the modulo.
+type timeShard struct {
+	unit     rule.StepUnit
+	shardNum int
+}
+
+// NewDayShard creates a ShardComputer which shards by day, shardNum is the optional modulo.
+func NewDayShard(shardNum int) rule.ShardComputer {
+	return timeShard{unit: rule.Uday, shardNum: shardNum}
+}
+
+// NewWeekShard creates a ShardComputer which shards by week, shardNum is the optional modulo.
+func NewWeekShard(shardNum int) rule.ShardComputer {
+	return timeShard{unit: rule.Uweek, shardNum: shardNum}
+}
+
+// NewMonthShard creates a ShardComputer which shards by month, shardNum is the optional modulo.
+func NewMonthShard(shardNum int) rule.ShardComputer {
+	return timeShard{unit: rule.Umonth, shardNum: shardNum}
+}
+
+// NewYearShard creates a ShardComputer which shards by year, shardNum is the optional modulo.
+func NewYearShard(shardNum int) rule.ShardComputer {
+	return timeShard{unit: rule.Uyear, shardNum: shardNum}
+}
+
+func (ts timeShard) Unit() rule.StepUnit {
+	return ts.unit
+}
+
+func (ts timeShard) Modulo() int {
+	return ts.shardNum
+}
+
+func (ts timeShard) Compute(value interface{}) (int, error) {
+	t, err := parseShardTime(value)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	year, month, day := t.Date()
+	// days since 1970-01-01, calendar based so that the location won't affect it
+	days := int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+
+	var serial, natural int
+	switch ts.unit {
+	case rule.Uday:
+		serial, natural = days, t.YearDay()-1
+	case rule.Uweek:
+		_, week := t.ISOWeek()
+		// 1970-01-01 is Thursday, shift to the Monday before it
+		serial, natural = floorDiv(days+3, 7), week-1
+	case rule.Umonth:
+		serial, natural = year*12+int(month)-1, int(month)-1
+	case rule.Uyear:
+		serial, natural = year, year
+	default:
+		return 0, errors.Errorf("unsupported time shard unit %s", ts.unit)
+	}
+
+	if ts.shardNum <= 0 {
+		return natural, nil
+	}
+	return (serial%ts.shardNum + ts.shardNum) % ts.shardNum, nil
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func parseShardTime(value interface{}) (time.Time, error) {
+	var s string
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v != nil {
+			return *v, nil
+		}
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		s = fmt.Sprint(value)
+	}
+
+	for _, layout := range _timeShardLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.Errorf("invalid time value '%v'", value)
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"sync"
 	"time"
 )
@@ -99,10 +100,11 @@ func Unload(schema string) error {
 }
 
 var (
-	_ proto.DB             = (*AtomDB)(nil)
-	_ proto.Callable       = (*atomTx)(nil)
-	_ proto.Tx             = (*compositeTx)(nil)
-	_ proto.VersionSupport = (*compositeTx)(nil)
+	_ proto.DB              = (*AtomDB)(nil)
+	_ proto.DatabaseSupport = (*defaultRuntime)(nil)
+	_ proto.Callable        = (*atomTx)(nil)
+	_ proto.Tx              = (*compositeTx)(nil)
+	_ proto.VersionSupport  = (*compositeTx)(nil)
 )
 
 type compositeTx struct {
@@ -165,6 +167,16 @@ func (tx *compositeTx) begin(ctx context.Context, group string) (*atomTx, error)
 	return newborn, nil
 }
 
+// groups returns the sorted groups which the transaction has already began on.
+func (tx *compositeTx) groups() []string {
+	groups := make([]string, 0, len(tx.txs))
+	for group := range tx.txs {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
 func (tx *compositeTx) String() string {
 	return fmt.Sprintf("tx-%d", tx.id)
 }
@@ -205,6 +217,7 @@ func (tx *compositeTx) Execute(ctx *proto.Context) (res proto.Result, warn uint1
 	)
 
 	ctx.Context = rcontext.WithHints(ctx.Context, ctx.Stmt.Hints)
+	ctx.Context = rcontext.WithTxGroups(ctx.Context, tx.groups())
 
 	var opt proto.Optimizer
 	if opt, err = optimize.NewOptimizer(ru, ctx.Stmt.Hints, ctx.Stmt.StmtNode, args); err != nil {
@@ -371,10 +384,13 @@ func (tx *atomTx) dispose() {
 type AtomDB struct {
 	mu sync.Mutex
 
-	id string
+	id       string
+	database string
 
 	weight proto.Weight
 	pool   *pools.ResourcePool
+	// schemaless creates the connection without default schema.
+	schemaless *mysql.Connector
 
 	closed atomic.Bool
 
@@ -390,8 +406,9 @@ func NewAtomDB(node *config.Node) *AtomDB {
 		return nil
 	}
 	db := &AtomDB{
-		id:     node.Name,
-		weight: proto.Weight{R: int32(r), W: int32(w)},
+		id:       node.Name,
+		database: node.Database,
+		weight:   proto.Weight{R: int32(r), W: int32(w)},
 	}
 
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?%s", node.Username, node.Password, node.Host, node.Port, node.Database, node.Parameters.String())
@@ -399,6 +416,10 @@ func NewAtomDB(node *config.Node) *AtomDB {
 	if err != nil {
 		panic(err)
 	}
+	dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/?%s", node.Username, node.Password, node.Host, node.Port, node.Parameters.String())
+	if db.schemaless, err = mysql.NewConnector(dsn); err != nil {
+		panic(err)
+	}
 
 	var (
 		capacity    = config.GetConnPropCapacity(node.ConnProps, 8)
@@ -413,6 +434,31 @@ func NewAtomDB(node *config.Node) *AtomDB {
 	return db
 }
 
+// Database returns the physical database name of the node.
+func (db *AtomDB) Database() string {
+	return db.database
+}
+
+// CallWithoutSchema executes the statement in a new connection without default schema, so that the
+// pooled connections won't be affected, eg: DROP DATABASE. The connection will be closed after executed.
+func (db *AtomDB) CallWithoutSchema(ctx context.Context, sql string) (proto.Result, error) {
+	if db.closed.Load() {
+		return nil, perrors.Errorf("the db instance '%s' is closed already", db.id)
+	}
+
+	bc, err := db.schemaless.NewBackendConnection(ctx)
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	defer bc.Close()
+
+	res, err := bc.ExecuteWithWarningCount(sql, false)
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	return res, nil
+}
+
 func (db *AtomDB) Variable(ctx context.Context, name string) (interface{}, error) {
 	if db.closed.Load() {
 		return nil, perrors.Errorf("the db instance '%s' is closed already", db.id)
@@ -677,6 +723,32 @@ func (pi *defaultRuntime) Exec(ctx context.Context, db string, query string, arg
 	return res, nil
 }
 
+func (pi *defaultRuntime) PhysicalDatabase(ctx context.Context, group string) (string, error) {
+	db, err := pi.atomMaster(ctx, group)
+	if err != nil {
+		return "", err
+	}
+	return db.Database(), nil
+}
+
+func (pi *defaultRuntime) ExecWithoutSchema(ctx context.Context, group string, query string) (proto.Result, error) {
+	db, err := pi.atomMaster(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("call upstream without schema: db=%s, id=%s, sql=\"%s\"", group, db.ID(), query)
+	return db.CallWithoutSchema(ctx, query)
+}
+
+// atomMaster returns the master node of group.
+func (pi *defaultRuntime) atomMaster(ctx context.Context, group string) (*AtomDB, error) {
+	db, ok := pi.Namespace().DBMaster(ctx, group).(*AtomDB)
+	if !ok || db == nil {
+		return nil, perrors.Errorf("cannot get upstream database %s", group)
+	}
+	return db, nil
+}
+
 func (pi *defaultRuntime) Execute(ctx *proto.Context) (res proto.Result, warn uint16, err error) {
 	var span trace.Span
 	ctx.Context, span = Tracer.Start(ctx.Context, "defaultRuntime.Execute")
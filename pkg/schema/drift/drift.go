@@ -0,0 +1,279 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package drift detects the schema differences between the physical tables of a logical table.
+package drift
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+)
+
+const (
+	KindTable     = "table"     // the physical table is missing
+	KindColumn    = "column"    // the column is missing, redundant or different
+	KindIndex     = "index"     // the index is missing, redundant or different
+	KindCollation = "collation" // the default collation of table is different
+)
+
+// Difference represents a difference between a physical table and the baseline.
+type Difference struct {
+	DB       string `json:"db"`
+	Table    string `json:"table"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Expected string `json:"expected"` // empty if it's redundant
+	Actual   string `json:"actual"`   // empty if it's missing
+}
+
+// Repair represents the DDL which makes the physical table same as the baseline.
+type Repair struct {
+	DB    string `json:"db"`
+	Table string `json:"table"`
+	SQL   string `json:"sql"`
+}
+
+// Report represents the result of checking a logical table.
+type Report struct {
+	Table       string        `json:"table"`
+	Baseline    string        `json:"baseline"`
+	Total       int           `json:"total"`
+	Differences []*Difference `json:"differences"`
+	Repairs     []*Repair     `json:"repairs,omitempty"`
+}
+
+// Check compares the columns, indexes and charsets of all the physical tables with the baseline,
+// which is the smallest existing physical table, the repair DDLs will be generated if repair is true.
+func Check(ctx context.Context, conn proto.VConn, table string, shards rule.DatabaseTables, repair bool) (*Report, error) {
+	dbs := make([]string, 0, len(shards))
+	for db := range shards {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	definitions := make(map[string]map[string]*definition, len(dbs))
+	for _, db := range dbs {
+		loaded, err := loadDefinitions(ctx, conn, db, shards[db])
+		if err != nil {
+			return nil, err
+		}
+		definitions[db] = loaded
+	}
+
+	// choose the smallest existing physical table as the baseline
+	var (
+		baseDB, baseTable string
+		base              *definition
+	)
+	for _, db := range dbs {
+		tables := append([]string(nil), shards[db]...)
+		sort.Strings(tables)
+		for _, it := range tables {
+			if d, ok := definitions[db][it]; ok {
+				baseDB, baseTable, base = db, it, d
+				break
+			}
+		}
+		if base != nil {
+			break
+		}
+	}
+	if base == nil {
+		return nil, errors.Errorf("no physical table of '%s' found", table)
+	}
+
+	ret := &Report{
+		Table:    table,
+		Baseline: baseDB + "." + baseTable,
+		Total:    shards.Len(),
+	}
+
+	var createSQL string
+	for _, db := range dbs {
+		tables := append([]string(nil), shards[db]...)
+		sort.Strings(tables)
+		for _, it := range tables {
+			if db == baseDB && it == baseTable {
+				continue
+			}
+
+			actual, ok := definitions[db][it]
+			if !ok {
+				ret.Differences = append(ret.Differences, &Difference{
+					DB:       db,
+					Table:    it,
+					Kind:     KindTable,
+					Name:     it,
+					Expected: it,
+				})
+				if repair {
+					if len(createSQL) < 1 {
+						var err error
+						if createSQL, err = showCreateTable(ctx, conn, baseDB, baseTable); err != nil {
+							return nil, err
+						}
+					}
+					ret.Repairs = append(ret.Repairs, &Repair{
+						DB:    db,
+						Table: it,
+						SQL:   renameCreateTable(createSQL, baseTable, it),
+					})
+				}
+				continue
+			}
+
+			differences, clauses := compare(base, actual)
+			for _, d := range differences {
+				d.DB, d.Table = db, it
+			}
+			ret.Differences = append(ret.Differences, differences...)
+
+			if repair && len(clauses) > 0 {
+				var sb strings.Builder
+				sb.WriteString("ALTER TABLE ")
+				writeID(&sb, it)
+				sb.WriteByte(' ')
+				sb.WriteString(strings.Join(clauses, ", "))
+				ret.Repairs = append(ret.Repairs, &Repair{
+					DB:    db,
+					Table: it,
+					SQL:   sb.String(),
+				})
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// compare compares the physical table with the baseline, returns the differences and the ALTER TABLE clauses to repair it.
+func compare(base, actual *definition) ([]*Difference, []string) {
+	var (
+		differences           []*Difference
+		drops, adds, modifies []string
+		addIndexes            []string
+	)
+
+	// redundant columns
+	for _, c := range actual.columns {
+		if expected, _ := base.column(c.name); expected == nil {
+			differences = append(differences, &Difference{Kind: KindColumn, Name: c.name, Actual: c.String()})
+			drops = append(drops, "DROP COLUMN "+quoteID(c.name))
+		}
+	}
+
+	// missing or different columns
+	for i, c := range base.columns {
+		existing, _ := actual.column(c.name)
+		switch {
+		case existing == nil:
+			differences = append(differences, &Difference{Kind: KindColumn, Name: c.name, Expected: c.String()})
+			position := " FIRST"
+			if i > 0 {
+				position = " AFTER " + quoteID(base.columns[i-1].name)
+			}
+			adds = append(adds, "ADD COLUMN "+c.String()+position)
+		case existing.String() != c.String():
+			differences = append(differences, &Difference{Kind: KindColumn, Name: c.name, Expected: c.String(), Actual: existing.String()})
+			modifies = append(modifies, "MODIFY COLUMN "+c.String())
+		}
+	}
+
+	// redundant or different indexes
+	for _, idx := range actual.indexes {
+		expected := base.index(idx.name)
+		if expected != nil && expected.String() == idx.String() {
+			continue
+		}
+		d := &Difference{Kind: KindIndex, Name: idx.name, Actual: idx.String()}
+		if expected != nil {
+			d.Expected = expected.String()
+			addIndexes = append(addIndexes, "ADD "+expected.String())
+		}
+		differences = append(differences, d)
+		if idx.isPrimary() {
+			drops = append([]string{"DROP PRIMARY KEY"}, drops...)
+		} else {
+			drops = append([]string{"DROP INDEX " + quoteID(idx.name)}, drops...)
+		}
+	}
+
+	// missing indexes
+	for _, idx := range base.indexes {
+		if actual.index(idx.name) == nil {
+			differences = append(differences, &Difference{Kind: KindIndex, Name: idx.name, Expected: idx.String()})
+			addIndexes = append(addIndexes, "ADD "+idx.String())
+		}
+	}
+
+	clauses := append(append(append(drops, adds...), modifies...), addIndexes...)
+
+	if !strings.EqualFold(base.collation, actual.collation) {
+		differences = append(differences, &Difference{Kind: KindCollation, Name: KindCollation, Expected: base.collation, Actual: actual.collation})
+		clauses = append(clauses, "COLLATE = "+base.collation)
+	}
+
+	return differences, clauses
+}
+
+func showCreateTable(ctx context.Context, conn proto.VConn, db, table string) (string, error) {
+	res, err := conn.Query(ctx, db, "SHOW CREATE TABLE "+quoteID(table))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to show create table %s.%s", db, table)
+	}
+	ds, err := res.Dataset()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer func() {
+		_ = ds.Close()
+	}()
+
+	row, err := ds.Next()
+	if errors.Is(err, io.EOF) {
+		return "", errors.Errorf("no such table %s.%s", db, table)
+	}
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	values := make([]proto.Value, 2)
+	if err = row.Scan(values); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return str(values[1]), nil
+}
+
+func renameCreateTable(sql, from, to string) string {
+	return strings.Replace(sql, "CREATE TABLE "+quoteID(from), "CREATE TABLE "+quoteID(to), 1)
+}
+
+func quoteID(name string) string {
+	var sb strings.Builder
+	writeID(&sb, name)
+	return sb.String()
+}
@@ -0,0 +1,172 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package drift
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/dataset"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/mysql/rows"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/resultx"
+	"github.com/arana-db/arana/testdata"
+)
+
+type fakeTable struct {
+	db, name, collation string
+	columns             [][]proto.Value // COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA, CHARACTER_SET_NAME, COLLATION_NAME
+	indexes             [][]proto.Value // INDEX_NAME, NON_UNIQUE, COLUMN_NAME
+}
+
+func newFakeTable(db, name string) *fakeTable {
+	return &fakeTable{
+		db:        db,
+		name:      name,
+		collation: "utf8mb4_general_ci",
+		columns: [][]proto.Value{
+			{proto.NewValueString("id"), proto.NewValueString("bigint"), proto.NewValueString("NO"), nil, proto.NewValueString("auto_increment"), nil, nil},
+			{proto.NewValueString("uid"), proto.NewValueString("bigint"), proto.NewValueString("NO"), nil, proto.NewValueString(""), nil, nil},
+			{proto.NewValueString("name"), proto.NewValueString("varchar(64)"), proto.NewValueString("YES"), proto.NewValueString("n/a"), proto.NewValueString(""), proto.NewValueString("utf8mb4"), proto.NewValueString("utf8mb4_general_ci")},
+			{proto.NewValueString("age"), proto.NewValueString("int"), proto.NewValueString("NO"), proto.NewValueString("0"), proto.NewValueString(""), nil, nil},
+		},
+		indexes: [][]proto.Value{
+			{proto.NewValueString("PRIMARY"), proto.NewValueString("0"), proto.NewValueString("id")},
+			{proto.NewValueString("idx_uid"), proto.NewValueString("1"), proto.NewValueString("uid")},
+		},
+	}
+}
+
+func fakeResult(names []string, values [][]proto.Value) proto.Result {
+	fields := make([]proto.Field, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, mysql.NewField(name, consts.FieldTypeVarString))
+	}
+	ds := &dataset.VirtualDataset{Columns: fields}
+	for _, it := range values {
+		ds.Rows = append(ds.Rows, rows.NewTextVirtualRow(fields, it))
+	}
+	return resultx.New(resultx.WithDataset(ds))
+}
+
+func TestCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		t0 = newFakeTable("db_0000", "student_0000")
+		t1 = newFakeTable("db_0000", "student_0001")
+		t2 = newFakeTable("db_0001", "student_0002")
+	)
+	// student_0001: missing column 'age' and index 'idx_uid' is unique
+	t1.columns = t1.columns[:3]
+	t1.indexes[1][1] = proto.NewValueString("0")
+	// student_0002: type of 'uid' is different, redundant column 'tmp' and different collation
+	t2.columns[1][1] = proto.NewValueString("int")
+	t2.columns = append(t2.columns, []proto.Value{proto.NewValueString("tmp"), proto.NewValueString("int"), proto.NewValueString("YES"), nil, proto.NewValueString(""), nil, nil})
+	t2.collation = "utf8mb4_bin"
+	// student_0003: missing
+
+	fakes := []*fakeTable{t0, t1, t2}
+
+	conn := testdata.NewMockVConn(ctrl)
+	conn.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, db string, sql string, args ...interface{}) (proto.Result, error) {
+			var (
+				names  []string
+				values [][]proto.Value
+			)
+			switch {
+			case strings.HasPrefix(sql, "SHOW CREATE TABLE"):
+				return fakeResult([]string{"Table", "Create Table"}, [][]proto.Value{
+					{proto.NewValueString("student_0000"), proto.NewValueString("CREATE TABLE `student_0000` (`id` bigint NOT NULL AUTO_INCREMENT)")},
+				}), nil
+			case strings.Contains(sql, "information_schema.tables"):
+				names = []string{"TABLE_NAME", "TABLE_COLLATION"}
+				for _, it := range fakes {
+					if it.db == db {
+						values = append(values, []proto.Value{proto.NewValueString(it.name), proto.NewValueString(it.collation)})
+					}
+				}
+			case strings.Contains(sql, "information_schema.columns"):
+				names = []string{"TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "EXTRA", "CHARACTER_SET_NAME", "COLLATION_NAME"}
+				for _, it := range fakes {
+					if it.db == db {
+						for _, c := range it.columns {
+							values = append(values, append([]proto.Value{proto.NewValueString(it.name)}, c...))
+						}
+					}
+				}
+			case strings.Contains(sql, "information_schema.statistics"):
+				names = []string{"TABLE_NAME", "INDEX_NAME", "NON_UNIQUE", "COLUMN_NAME"}
+				for _, it := range fakes {
+					if it.db == db {
+						for _, idx := range it.indexes {
+							values = append(values, append([]proto.Value{proto.NewValueString(it.name)}, idx...))
+						}
+					}
+				}
+			}
+			return fakeResult(names, values), nil
+		}).AnyTimes()
+
+	shards := rule.DatabaseTables{
+		"db_0000": {"student_0000", "student_0001"},
+		"db_0001": {"student_0002", "student_0003"},
+	}
+
+	report, err := Check(context.Background(), conn, "student", shards, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "db_0000.student_0000", report.Baseline)
+	assert.Equal(t, 4, report.Total)
+
+	var actual []string
+	for _, d := range report.Differences {
+		actual = append(actual, d.DB+"."+d.Table+": "+d.Kind+" "+d.Name)
+	}
+	assert.Equal(t, []string{
+		"db_0000.student_0001: column age",
+		"db_0000.student_0001: index idx_uid",
+		"db_0001.student_0002: column tmp",
+		"db_0001.student_0002: column uid",
+		"db_0001.student_0002: collation collation",
+		"db_0001.student_0003: table student_0003",
+	}, actual)
+
+	assert.Len(t, report.Repairs, 3)
+	assert.Equal(t, "ALTER TABLE `student_0001` DROP INDEX `idx_uid`, ADD COLUMN `age` int NOT NULL DEFAULT 0 AFTER `name`, ADD INDEX `idx_uid` (`uid`)", report.Repairs[0].SQL)
+	assert.Equal(t, "ALTER TABLE `student_0002` DROP COLUMN `tmp`, MODIFY COLUMN `uid` bigint NOT NULL, COLLATE = utf8mb4_general_ci", report.Repairs[1].SQL)
+	assert.Equal(t, "CREATE TABLE `student_0003` (`id` bigint NOT NULL AUTO_INCREMENT)", report.Repairs[2].SQL)
+
+	report, err = Check(context.Background(), conn, "student", shards, false)
+	assert.NoError(t, err)
+	assert.Len(t, report.Differences, 6)
+	assert.Empty(t, report.Repairs)
+}
@@ -0,0 +1,284 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package drift
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+const (
+	tablesSQL     = "SELECT TABLE_NAME, TABLE_COLLATION FROM information_schema.tables WHERE TABLE_SCHEMA=database() AND TABLE_NAME IN (%s)"
+	columnsSQL    = "SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA, CHARACTER_SET_NAME, COLLATION_NAME FROM information_schema.columns WHERE TABLE_SCHEMA=database() AND TABLE_NAME IN (%s) ORDER BY TABLE_NAME, ORDINAL_POSITION"
+	statisticsSQL = "SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE, COLUMN_NAME FROM information_schema.statistics WHERE TABLE_SCHEMA=database() AND TABLE_NAME IN (%s) ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX"
+)
+
+// definition represents the definition of a physical table.
+type definition struct {
+	collation string
+	columns   []*column
+	indexes   []*index
+}
+
+func (d *definition) column(name string) (*column, int) {
+	for i, c := range d.columns {
+		if strings.EqualFold(c.name, name) {
+			return c, i
+		}
+	}
+	return nil, -1
+}
+
+func (d *definition) index(name string) *index {
+	for _, idx := range d.indexes {
+		if strings.EqualFold(idx.name, name) {
+			return idx
+		}
+	}
+	return nil
+}
+
+type column struct {
+	name      string
+	typ       string
+	nullable  bool
+	def       *string
+	extra     string
+	charset   string
+	collation string
+}
+
+// String returns the column definition which can be used in the ALTER TABLE statement.
+func (c *column) String() string {
+	var sb strings.Builder
+	writeID(&sb, c.name)
+	sb.WriteByte(' ')
+	sb.WriteString(c.typ)
+	if len(c.charset) > 0 {
+		sb.WriteString(" CHARACTER SET ")
+		sb.WriteString(c.charset)
+	}
+	if len(c.collation) > 0 {
+		sb.WriteString(" COLLATE ")
+		sb.WriteString(c.collation)
+	}
+	if c.nullable {
+		sb.WriteString(" NULL")
+	} else {
+		sb.WriteString(" NOT NULL")
+	}
+	if c.def != nil {
+		sb.WriteString(" DEFAULT ")
+		sb.WriteString(quoteDefault(*c.def))
+	}
+	if len(c.extra) > 0 {
+		sb.WriteByte(' ')
+		sb.WriteString(c.extra)
+	}
+	return sb.String()
+}
+
+type index struct {
+	name    string
+	unique  bool
+	columns []string
+}
+
+func (idx *index) isPrimary() bool {
+	return strings.EqualFold(idx.name, "PRIMARY")
+}
+
+// String returns the index definition which can be used in the ALTER TABLE statement.
+func (idx *index) String() string {
+	var sb strings.Builder
+	switch {
+	case idx.isPrimary():
+		sb.WriteString("PRIMARY KEY")
+	case idx.unique:
+		sb.WriteString("UNIQUE INDEX ")
+		writeID(&sb, idx.name)
+	default:
+		sb.WriteString("INDEX ")
+		writeID(&sb, idx.name)
+	}
+	sb.WriteString(" (")
+	for i, c := range idx.columns {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		writeID(&sb, c)
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+// loadDefinitions loads the definitions of the physical tables in the database, the missing tables are absent in the result.
+func loadDefinitions(ctx context.Context, conn proto.VConn, db string, tables []string) (map[string]*definition, error) {
+	in := make([]string, 0, len(tables))
+	for _, table := range tables {
+		in = append(in, "'"+strings.ReplaceAll(table, "'", "''")+"'")
+	}
+	inList := strings.Join(in, ",")
+
+	ret := make(map[string]*definition, len(tables))
+
+	if err := query(ctx, conn, db, fmt.Sprintf(tablesSQL, inList), func(values []proto.Value) {
+		ret[str(values[0])] = &definition{
+			collation: str(values[1]),
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := query(ctx, conn, db, fmt.Sprintf(columnsSQL, inList), func(values []proto.Value) {
+		d, ok := ret[str(values[0])]
+		if !ok {
+			return
+		}
+		c := &column{
+			name:      str(values[1]),
+			typ:       str(values[2]),
+			nullable:  strings.EqualFold(str(values[3]), "YES"),
+			extra:     normalizeExtra(str(values[5])),
+			charset:   str(values[6]),
+			collation: str(values[7]),
+		}
+		if values[4] != nil {
+			def := values[4].String()
+			c.def = &def
+		}
+		d.columns = append(d.columns, c)
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := query(ctx, conn, db, fmt.Sprintf(statisticsSQL, inList), func(values []proto.Value) {
+		d, ok := ret[str(values[0])]
+		if !ok {
+			return
+		}
+		name := str(values[1])
+		idx := d.index(name)
+		if idx == nil {
+			idx = &index{
+				name:   name,
+				unique: str(values[2]) == "0",
+			}
+			d.indexes = append(d.indexes, idx)
+		}
+		idx.columns = append(idx.columns, str(values[3]))
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func query(ctx context.Context, conn proto.VConn, db, sql string, handle func(values []proto.Value)) error {
+	res, err := conn.Query(ctx, db, sql)
+	if err != nil {
+		return errors.Wrapf(err, "failed to query metadata from %s", db)
+	}
+
+	ds, err := res.Dataset()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		_ = ds.Close()
+	}()
+
+	fields, err := ds.Fields()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	values := make([]proto.Value, len(fields))
+	for {
+		row, err := ds.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if err = row.Scan(values); err != nil {
+			return errors.WithStack(err)
+		}
+		handle(values)
+	}
+}
+
+func str(value proto.Value) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
+
+// normalizeExtra keeps the parts of EXTRA which can be used in the column definition.
+func normalizeExtra(extra string) string {
+	lower := strings.ToLower(extra)
+	switch {
+	case strings.Contains(lower, "auto_increment"):
+		return "AUTO_INCREMENT"
+	case strings.Contains(lower, "on update "):
+		return strings.TrimSpace(extra[strings.Index(lower, "on update "):])
+	default:
+		return ""
+	}
+}
+
+func quoteDefault(def string) string {
+	upper := strings.ToUpper(def)
+	if strings.HasPrefix(upper, "CURRENT_TIMESTAMP") || upper == "NULL" {
+		return def
+	}
+	if isNumber(def) {
+		return def
+	}
+	return "'" + strings.ReplaceAll(def, "'", "''") + "'"
+}
+
+func isNumber(s string) bool {
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9', r == '.':
+		case (r == '-' || r == '+') && i == 0:
+		default:
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+func writeID(sb *strings.Builder, name string) {
+	sb.WriteByte('`')
+	sb.WriteString(strings.ReplaceAll(name, "`", "``"))
+	sb.WriteByte('`')
+}
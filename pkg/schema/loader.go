@@ -138,25 +138,35 @@ func (l *SimpleSchemaLoader) LoadColumnMetadataMap(ctx context.Context, schema s
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	result := make(map[string][]*proto.ColumnMetadata, 0)
+	for group, groupTables := range locateTables(conn, tables) {
+		if err = l.loadColumnMetadata(ctx, conn, group, groupTables, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (l *SimpleSchemaLoader) loadColumnMetadata(ctx context.Context, conn runtime.Runtime, group string, tables []string, result map[string][]*proto.ColumnMetadata) error {
 	var (
 		resultSet proto.Result
 		ds        proto.Dataset
+		err       error
 	)
 
-	if resultSet, err = conn.Query(ctx, "", getColumnMetadataSQL(tables)); err != nil {
+	if resultSet, err = conn.Query(ctx, group, getColumnMetadataSQL(tables)); err != nil {
 		log.Errorf("Load ColumnMetadata error when call db: %v", err)
-		return nil, errors.WithStack(err)
+		return errors.WithStack(err)
 	}
 
 	if ds, err = resultSet.Dataset(); err != nil {
 		log.Errorf("Load ColumnMetadata error when call db: %v", err)
-		return nil, errors.WithStack(err)
+		return errors.WithStack(err)
 	}
 
-	result := make(map[string][]*proto.ColumnMetadata, 0)
 	if ds == nil {
 		log.Error("Load ColumnMetadata error because the result is nil")
-		return nil, nil
+		return nil
 	}
 
 	var (
@@ -171,11 +181,11 @@ func (l *SimpleSchemaLoader) LoadColumnMetadataMap(ctx context.Context, schema s
 			break
 		}
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return errors.WithStack(err)
 		}
 
 		if err = row.Scan(cells); err != nil {
-			return nil, errors.WithStack(err)
+			return errors.WithStack(err)
 		}
 
 		tableName := convertInterfaceToStrNullable(cells[0])
@@ -195,7 +205,7 @@ func (l *SimpleSchemaLoader) LoadColumnMetadataMap(ctx context.Context, schema s
 		})
 	}
 
-	return result, nil
+	return nil
 }
 
 func convertInterfaceToStrNullable(value proto.Value) string {
@@ -211,24 +221,35 @@ func (l *SimpleSchemaLoader) LoadIndexMetadata(ctx context.Context, schema strin
 		return nil, errors.WithStack(err)
 	}
 
+	result := make(map[string][]*proto.IndexMetadata, 0)
+	for group, groupTables := range locateTables(conn, tables) {
+		if err = l.loadIndexMetadata(ctx, conn, group, groupTables, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (l *SimpleSchemaLoader) loadIndexMetadata(ctx context.Context, conn runtime.Runtime, group string, tables []string, result map[string][]*proto.IndexMetadata) error {
 	var (
 		resultSet proto.Result
 		ds        proto.Dataset
+		err       error
 	)
 
-	if resultSet, err = conn.Query(ctx, "", getIndexMetadataSQL(tables)); err != nil {
-		return nil, errors.WithStack(err)
+	if resultSet, err = conn.Query(ctx, group, getIndexMetadataSQL(tables)); err != nil {
+		return errors.WithStack(err)
 	}
 
 	if ds, err = resultSet.Dataset(); err != nil {
-		return nil, errors.WithStack(err)
+		return errors.WithStack(err)
 	}
 
 	var (
 		fields, _ = ds.Fields()
 		row       proto.Row
 		values    = make([]proto.Value, len(fields))
-		result    = make(map[string][]*proto.IndexMetadata, 0)
 	)
 
 	for {
@@ -238,11 +259,11 @@ func (l *SimpleSchemaLoader) LoadIndexMetadata(ctx context.Context, schema strin
 		}
 
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return errors.WithStack(err)
 		}
 
 		if err = row.Scan(values); err != nil {
-			return nil, errors.WithStack(err)
+			return errors.WithStack(err)
 		}
 
 		tableName := convertInterfaceToStrNullable(values[0])
@@ -250,7 +271,23 @@ func (l *SimpleSchemaLoader) LoadIndexMetadata(ctx context.Context, schema strin
 		result[tableName] = append(result[tableName], &proto.IndexMetadata{Name: indexName})
 	}
 
-	return result, nil
+	return nil
+}
+
+// locateTables groups the tables by the locations, the single tables are loaded from their own groups,
+// and the others are loaded from the default group.
+func locateTables(conn runtime.Runtime, tables []string) map[string][]string {
+	ret := make(map[string][]string)
+	if len(tables) == 0 {
+		ret[""] = nil
+		return ret
+	}
+	ru := conn.Namespace().Rule()
+	for _, table := range tables {
+		group, _ := ru.SingleTable(table)
+		ret[group] = append(ret[group], table)
+	}
+	return ret
 }
 
 func getIndexMetadataSQL(tables []string) string {
@@ -14,20 +14,70 @@
  * See the License for the specific language governing permissions and
  * limitations under the License.
  */
-
 package server
 
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+
+	"go.uber.org/atomic"
+)
+
 import (
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/util/log"
 )
 
+// DefaultShutdownTimeout is the default max duration to wait for the pending transactions while shutting down.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ErrShuttingDown is returned if the server is already shutting down.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+var _default atomic.Value // *Server
+
+// Default returns the server of current process, nil if the server is not started.
+func Default() *Server {
+	srv, _ := _default.Load().(*Server)
+	return srv
+}
+
+// SetDefault sets the server of current process.
+func SetDefault(srv *Server) {
+	_default.Store(srv)
+}
+
+// Hook is called while shutting down.
+type Hook func(ctx context.Context) error
+
 type Server struct {
 	listeners []proto.Listener
+
+	// beforeDrain is called before draining the listeners, eg: deregister from the service registry.
+	beforeDrain []Hook
+	// afterDrain is called after the listeners are drained, eg: close the backend pools.
+	afterDrain []Hook
+
+	// shutdownTimeout is used if no timeout is specified when shutting down.
+	shutdownTimeout time.Duration
+	shuttingDown    atomic.Bool
+	done            chan struct{}
+
+	// holds delays the closing of done after shut down, eg: the admin api which is responding the result of draining.
+	holdsMu  sync.Mutex
+	holds    int
+	finished bool
 }
 
 func NewServer() *Server {
 	return &Server{
 		listeners: make([]proto.Listener, 0),
+		done:      make(chan struct{}),
 	}
 }
 
@@ -35,8 +85,129 @@ func (srv *Server) AddListener(listener proto.Listener) {
 	srv.listeners = append(srv.listeners, listener)
 }
 
+// SetShutdownTimeout sets the default timeout of shutting down.
+func (srv *Server) SetShutdownTimeout(timeout time.Duration) {
+	srv.shutdownTimeout = timeout
+}
+
+// BeforeDrain adds a hook which will be called before draining the listeners.
+func (srv *Server) BeforeDrain(hook Hook) {
+	srv.beforeDrain = append(srv.beforeDrain, hook)
+}
+
+// AfterDrain adds a hook which will be called after the listeners are drained.
+func (srv *Server) AfterDrain(hook Hook) {
+	srv.afterDrain = append(srv.afterDrain, hook)
+}
+
 func (srv *Server) Start() {
 	for _, l := range srv.listeners {
 		go l.Listen()
 	}
 }
+
+// Done returns a channel which will be closed after the server is shut down and all holds are released.
+func (srv *Server) Done() <-chan struct{} {
+	return srv.done
+}
+
+// Hold prevents the channel of Done from being closed until the returned function is called, it is used to
+// finish the work which depends on the result of shutting down before the process exits.
+func (srv *Server) Hold() (release func()) {
+	srv.holdsMu.Lock()
+	srv.holds++
+	srv.holdsMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			srv.holdsMu.Lock()
+			defer srv.holdsMu.Unlock()
+			srv.holds--
+			if srv.holds == 0 && srv.finished {
+				close(srv.done)
+			}
+		})
+	}
+}
+
+// finish marks the server as shut down, the channel of Done will be closed if nothing holds it.
+func (srv *Server) finish() {
+	srv.holdsMu.Lock()
+	defer srv.holdsMu.Unlock()
+	srv.finished = true
+	if srv.holds == 0 {
+		close(srv.done)
+	}
+}
+
+// Shutdown shuts down the server gracefully, the default timeout will be used if timeout <= 0:
+//  1. call the hooks before draining, eg: deregister from the service registry.
+//  2. drain all listeners, the sessions still alive after timeout will be terminated.
+//  3. call the hooks after draining, eg: close the backend pools.
+//
+// The sessions which are terminated while in a transaction will be returned.
+func (srv *Server) Shutdown(timeout time.Duration) ([]*proto.SessionState, error) {
+	if !srv.shuttingDown.CAS(false, true) {
+		return nil, ErrShuttingDown
+	}
+	defer srv.finish()
+
+	if timeout <= 0 {
+		timeout = srv.shutdownTimeout
+	}
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	log.Infof("shutting down server, timeout=%s", timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, hook := range srv.beforeDrain {
+		if err := hook(ctx); err != nil {
+			log.Errorf("failed to call hook before draining: %v", err)
+		}
+	}
+
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		terminated []*proto.SessionState
+	)
+	for _, it := range srv.listeners {
+		wg.Add(1)
+		go func(l proto.Listener) {
+			defer wg.Done()
+			states := l.Drain(ctx)
+			mu.Lock()
+			terminated = append(terminated, states...)
+			mu.Unlock()
+		}(it)
+	}
+	wg.Wait()
+
+	var inTx []*proto.SessionState
+	for _, it := range terminated {
+		if it.InTransaction {
+			log.Warnf("session terminated while in a transaction: connection_id=%d, tenant=%s, schema=%s, remote=%s",
+				it.ConnectionID, it.Tenant, it.Schema, it.RemoteAddr)
+			inTx = append(inTx, it)
+		}
+	}
+	if len(terminated) > 0 {
+		log.Warnf("%d sessions terminated after timeout, %d of them were in a transaction", len(terminated), len(inTx))
+	}
+
+	// use a fresh context, the timeout may be exceeded already
+	for _, hook := range srv.afterDrain {
+		if err := hook(context.Background()); err != nil {
+			log.Errorf("failed to call hook after draining: %v", err)
+		}
+	}
+
+	log.Infof("server is shut down")
+
+	return inTx, nil
+}
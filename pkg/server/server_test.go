@@ -0,0 +1,114 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+type fakeListener struct {
+	events *[]string
+	states []*proto.SessionState
+	block  bool
+}
+
+func (f *fakeListener) SetExecutor(proto.Executor) {}
+
+func (f *fakeListener) Listen() {}
+
+func (f *fakeListener) Close() {}
+
+func (f *fakeListener) Drain(ctx context.Context) []*proto.SessionState {
+	if f.block {
+		<-ctx.Done()
+	}
+	*f.events = append(*f.events, "drain")
+	return f.states
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	var events []string
+
+	srv := NewServer()
+	srv.AddListener(&fakeListener{
+		events: &events,
+		block:  true,
+		states: []*proto.SessionState{
+			{ConnectionID: 1, InTransaction: true},
+			{ConnectionID: 2},
+		},
+	})
+	srv.BeforeDrain(func(ctx context.Context) error {
+		events = append(events, "before")
+		return nil
+	})
+	srv.AfterDrain(func(ctx context.Context) error {
+		assert.NoError(t, ctx.Err())
+		events = append(events, "after")
+		return nil
+	})
+
+	start := time.Now()
+	inTx, err := srv.Shutdown(50 * time.Millisecond)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, []string{"before", "drain", "after"}, events)
+	assert.Len(t, inTx, 1)
+	assert.Equal(t, uint32(1), inTx[0].ConnectionID)
+
+	select {
+	case <-srv.Done():
+	default:
+		assert.Fail(t, "server should be done")
+	}
+
+	_, err = srv.Shutdown(0)
+	assert.ErrorIs(t, err, ErrShuttingDown)
+}
+
+func TestServer_Hold(t *testing.T) {
+	srv := NewServer()
+	release := srv.Hold()
+
+	_, err := srv.Shutdown(time.Millisecond)
+	assert.NoError(t, err)
+
+	select {
+	case <-srv.Done():
+		assert.Fail(t, "server should be held")
+	default:
+	}
+
+	release()
+	release() // no effect
+
+	select {
+	case <-srv.Done():
+	default:
+		assert.Fail(t, "server should be done")
+	}
+}
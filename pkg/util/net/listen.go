@@ -0,0 +1,109 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package net
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// UnixPrefix is the prefix of unix domain socket address, eg: 'unix:/var/run/arana.sock'.
+const UnixPrefix = "unix:"
+
+// IsUnixAddress returns true if the address is a unix domain socket address.
+func IsUnixAddress(address string) bool {
+	return strings.HasPrefix(address, UnixPrefix)
+}
+
+// Listen listens on the TCP address or unix domain socket address.
+//
+// For unix domain socket, the stale socket file will be removed before listening,
+// and the file permission will be changed if the mode(eg: '0660') is not empty.
+func Listen(address string, port int, mode string) (net.Listener, error) {
+	if !IsUnixAddress(address) {
+		l, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(port)))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return l, nil
+	}
+
+	path := strings.TrimPrefix(address, UnixPrefix)
+	if len(path) < 1 {
+		return nil, errors.Errorf("invalid unix socket address '%s'", address)
+	}
+
+	var (
+		perm os.FileMode
+		err  error
+	)
+	if len(mode) > 0 {
+		var m uint64
+		if m, err = strconv.ParseUint(mode, 8, 32); err != nil {
+			return nil, errors.Wrapf(err, "invalid unix socket mode '%s'", mode)
+		}
+		perm = os.FileMode(m).Perm()
+	}
+
+	if err = removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if perm != 0 {
+		if err = os.Chmod(path, perm); err != nil {
+			_ = l.Close()
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return l, nil
+}
+
+// removeStaleSocket removes the socket file which is left by a crashed process.
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if fi.Mode()&os.ModeSocket == 0 {
+		return errors.Errorf("cannot listen on '%s': file exists and is not a socket", path)
+	}
+
+	// the socket is still in use
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return errors.Errorf("cannot listen on '%s': address already in use", path)
+	}
+
+	return errors.WithStack(os.Remove(path))
+}
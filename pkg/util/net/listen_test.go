@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package net
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListen_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "arana.sock")
+	address := UnixPrefix + path
+
+	l, err := Listen(address, 0, "0660")
+	assert.NoError(t, err)
+
+	fi, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0660), fi.Mode().Perm())
+
+	// in use
+	_, err = Listen(address, 0, "")
+	assert.Error(t, err)
+
+	conn, err := net.Dial("unix", path)
+	assert.NoError(t, err)
+	_ = conn.Close()
+
+	// simulate the stale socket file left by a crashed process
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = l.Close()
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+
+	l, err = Listen(address, 0, "")
+	assert.NoError(t, err)
+	_ = l.Close()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// not a socket
+	assert.NoError(t, os.WriteFile(path, []byte("foo"), 0600))
+	_, err = Listen(address, 0, "")
+	assert.Error(t, err)
+
+	_, err = Listen(address, 0, "abc")
+	assert.Error(t, err)
+	_, err = Listen(UnixPrefix, 0, "")
+	assert.Error(t, err)
+}
+
+func TestListen_TCP(t *testing.T) {
+	l, err := Listen("127.0.0.1", 0, "")
+	assert.NoError(t, err)
+	defer l.Close()
+	assert.Equal(t, "tcp", l.Addr().Network())
+}